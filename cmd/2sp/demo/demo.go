@@ -39,17 +39,20 @@ type Demo struct {
 	program *tea.Program
 	logger  *zap.Logger
 
+	newTransport transport.Factory
+
 	players    []*game.Game
 	playerSubs []*game.Subscription
 }
 
-func New(ctx context.Context, dealer *game.Game, program *tea.Program) *Demo {
+func New(ctx context.Context, dealer *game.Game, program *tea.Program, newTransport transport.Factory) *Demo {
 	return &Demo{
-		ctx:     ctx,
-		dealer:  dealer,
-		events:  dealer.Subscribe(),
-		program: program,
-		logger:  config.Logger.Named("demo"),
+		ctx:          ctx,
+		dealer:       dealer,
+		events:       dealer.Subscribe(),
+		program:      program,
+		logger:       config.Logger.Named("demo"),
+		newTransport: newTransport,
 	}
 }
 
@@ -266,7 +269,7 @@ func (d *Demo) sendText(text string) {
 func (d *Demo) createPlayer(name string) (*game.Game, error) {
 	logger := config.Logger.Named(strings.ToLower(name))
 
-	tr := transport.NewNode(d.ctx, logger)
+	tr := d.newTransport(logger)
 	err := tr.Initialize()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize transport")
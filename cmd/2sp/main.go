@@ -4,17 +4,24 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jonboulle/clockwork"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/six78/2-story-points-cli/cmd/2sp/demo"
+	"github.com/six78/2-story-points-cli/internal/api"
 	"github.com/six78/2-story-points-cli/internal/config"
+	"github.com/six78/2-story-points-cli/internal/daemon"
 	"github.com/six78/2-story-points-cli/internal/transport"
 	"github.com/six78/2-story-points-cli/internal/version"
 	"github.com/six78/2-story-points-cli/internal/view"
 	"github.com/six78/2-story-points-cli/pkg/game"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
 	"github.com/six78/2-story-points-cli/pkg/storage"
 )
 
@@ -27,22 +34,48 @@ func main() {
 		return
 	}
 
+	encoding, err := protocol.ParseEncoding(config.Encoding())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	err = game.LoadDecks(config.DecksFile())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	ctx, quit := context.WithCancel(context.Background())
 	defer quit()
 
-	waku := transport.NewNode(ctx, config.Logger)
-	defer waku.Stop()
+	newTransport, err := transportFactory(ctx)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	messenger := newTransport(config.Logger)
+	defer messenger.Stop()
+
+	playerRole := protocol.VoterRole
+	if config.Observer() {
+		playerRole = protocol.ObserverRole
+	}
 
 	options := []game.Option{
 		game.WithContext(ctx),
-		game.WithTransport(waku),
+		game.WithTransport(messenger),
 		game.WithStorage(createStorage()),
 		game.WithLogger(config.Logger.Named("game")),
 		game.WithPlayerName(config.PlayerName()),
+		game.WithPlayerRole(playerRole),
 		game.WithOnlineMessagePeriod(config.OnlineMessagePeriod),
 		game.WithStateMessagePeriod(config.StateMessagePeriod),
 		game.WithEnableSymmetricEncryption(config.EnableSymmetricEncryption),
 		game.WithClock(clockwork.NewRealClock()),
+		game.WithEncoding(encoding),
+		game.WithAutoReveal(config.AutoReveal(), config.AutoRevealDelay()),
 	}
 
 	game := game.NewGame(options)
@@ -51,13 +84,32 @@ func main() {
 	}
 	defer game.Stop()
 
+	if config.APIAddress() != "" {
+		server, err := startAPI(game)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer server.Stop()
+	}
+
+	if config.Daemon() {
+		err = runDaemon(ctx, game, messenger)
+		if err != nil {
+			config.Logger.Error("error running daemon", zap.Error(err))
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create UI model and program
-	model := view.InitialModel(game, waku)
+	model := view.InitialModel(game, messenger)
 	program := tea.NewProgram(model)
 
 	// Run demo if enabled
 	if config.Demo() {
-		demonstration := demo.New(ctx, game, program)
+		demonstration := demo.New(ctx, game, program, newTransport)
 		go func() {
 			demonstration.Routine()
 			program.Quit()
@@ -73,6 +125,102 @@ func main() {
 	os.Exit(0)
 }
 
+func startAPI(game *game.Game) (*api.Server, error) {
+	token := config.APIToken()
+	if token == "" {
+		var err error
+		token, err = api.GenerateToken()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	server, err := api.NewServer(game, config.Logger.Named("api"), config.APIAddress(), token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("API listening on http://%s, token: %s\n", server.Address(), token)
+	return server, nil
+}
+
+func runDaemon(ctx context.Context, game *game.Game, messenger transport.Service) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := messenger.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize transport")
+	}
+
+	err = messenger.Start()
+	if err != nil {
+		return errors.Wrap(err, "failed to start transport")
+	}
+
+	err = game.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize game")
+	}
+
+	if game.Player().Name == "" {
+		err = game.RenamePlayer(config.GeneratePlayerName())
+		if err != nil {
+			return errors.Wrap(err, "failed to set player name")
+		}
+	}
+
+	d := daemon.New(game, config.Logger.Named("daemon"))
+
+	// Initial action is usually `new` or `join <room>`
+	if args := strings.Fields(config.InitialAction()); len(args) > 0 {
+		response := d.Handle(daemon.Request{Action: args[0], Args: args[1:]})
+		if response.Error != "" {
+			return errors.New(response.Error)
+		}
+		config.Logger.Info("initial action done", zap.String("roomID", response.RoomID))
+	}
+
+	if config.DaemonSocket() != "" {
+		return d.ListenUnix(ctx, config.DaemonSocket())
+	}
+
+	err = d.Serve(ctx, os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	// Keep the room alive when stdin is closed
+	<-ctx.Done()
+	return nil
+}
+
+// transportFactory returns a function to create transports of the configured kind.
+// All loopback transports created by the factory share the same in-memory network.
+func transportFactory(ctx context.Context) (transport.Factory, error) {
+	switch config.Transport() {
+	case config.TransportWaku:
+		return func(logger *zap.Logger) transport.Service {
+			return transport.NewNode(ctx, logger)
+		}, nil
+	case config.TransportLan:
+		return func(logger *zap.Logger) transport.Service {
+			return transport.NewLanNode(ctx, logger, config.LanAddress(), config.LanInterface())
+		}, nil
+	case config.TransportLoopback:
+		network := transport.NewLoopbackNetwork()
+		return func(logger *zap.Logger) transport.Service {
+			return network.NewNode(logger)
+		}, nil
+	}
+	return nil, errors.Errorf("unknown transport: %s", config.Transport())
+}
+
 func createStorage() storage.Service {
 	if config.Anonymous() {
 		return nil
@@ -0,0 +1,333 @@
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	"github.com/six78/2-story-points-cli/pkg/game"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+// Dealer actions are shared between the TUI and the headless mode.
+// Actions that change the UI state (e.g. switching rooms) belong to the view.
+
+type Action string
+
+const (
+	Deal       Action = "deal"
+	Add        Action = "add"
+	Reveal     Action = "reveal"
+	Finish     Action = "finish"
+	Deck       Action = "deck"
+	Select     Action = "select"
+	Hint       Action = "hint"
+	Remove     Action = "remove"
+	Edit       Action = "edit"
+	Move       Action = "move"
+	Revote     Action = "revote"
+	Timebox    Action = "timebox"
+	Countdown  Action = "countdown"
+	AutoReveal Action = "autoreveal"
+)
+
+type actionFunc func(g *game.Game, args []string) error
+
+var dealerActions = map[Action]actionFunc{
+	Deal:       RunDeal,
+	Add:        RunAdd,
+	Reveal:     RunReveal,
+	Finish:     RunFinish,
+	Deck:       RunDeck,
+	Select:     RunSelect,
+	Hint:       RunHint,
+	Remove:     RunRemove,
+	Edit:       RunEdit,
+	Move:       RunMove,
+	Revote:     RunRevote,
+	Timebox:    RunTimebox,
+	Countdown:  RunCountdown,
+	AutoReveal: RunAutoReveal,
+}
+
+var ErrUnknownAction = errors.New("unknown action")
+
+// Run runs the dealer action with given arguments.
+func Run(g *game.Game, action Action, args []string) error {
+	actionFn, ok := dealerActions[action]
+	if !ok {
+		return errors.Wrap(ErrUnknownAction, string(action))
+	}
+	return actionFn(g, args)
+}
+
+func RunDeal(g *game.Game, args []string) error {
+	if len(args) == 0 {
+		return errors.New("empty deal")
+	}
+	// TODO: Find a better way of restoring empty spaces between args
+	issue := strings.Join(args, " ")
+	_, err := g.Deal(issue)
+	return err
+}
+
+func RunAdd(g *game.Game, args []string) error {
+	if len(args) == 0 {
+		return errors.New("empty issue")
+	}
+	_, err := g.AddIssue(strings.Join(args, " "))
+	return err
+}
+
+func RunReveal(g *game.Game, args []string) error {
+	return g.Reveal()
+}
+
+func RunRevote(g *game.Game, args []string) error {
+	return g.Revote()
+}
+
+func RunFinish(g *game.Game, args []string) error {
+	if len(args) == 0 {
+		return errors.New("empty result")
+	}
+	return g.Finish(protocol.VoteValue(args[0]))
+}
+
+func RunDeck(g *game.Game, args []string) error {
+	deck, err := ParseDeck(args)
+	if err != nil {
+		return err
+	}
+	return g.SetDeck(deck)
+}
+
+func RunSelect(g *game.Game, args []string) error {
+	if len(args) == 0 {
+		return errors.New("no issue index provided")
+	}
+
+	index, err := parseIssueIndex(args[0])
+	if err != nil {
+		return err
+	}
+
+	return g.SelectIssue(index)
+}
+
+func RunRemove(g *game.Game, args []string) error {
+	if len(args) == 0 {
+		return errors.New("no issue index provided")
+	}
+
+	index, err := parseIssueIndex(args[0])
+	if err != nil {
+		return err
+	}
+
+	return g.RemoveIssue(index)
+}
+
+// RunEdit changes the issue title or URL: edit <index> <titleOrURL>.
+func RunEdit(g *game.Game, args []string) error {
+	if len(args) < 2 {
+		return errors.New("issue index and new title are required")
+	}
+
+	index, err := parseIssueIndex(args[0])
+	if err != nil {
+		return err
+	}
+
+	return g.EditIssue(index, strings.Join(args[1:], " "))
+}
+
+// RunMove moves the issue to a new position in the list: move <index> <newIndex>.
+func RunMove(g *game.Game, args []string) error {
+	if len(args) < 2 {
+		return errors.New("issue index and new index are required")
+	}
+
+	index, err := parseIssueIndex(args[0])
+	if err != nil {
+		return err
+	}
+
+	newIndex, err := parseIssueIndex(args[1])
+	if err != nil {
+		return err
+	}
+
+	return g.MoveIssue(index, newIndex)
+}
+
+func parseIssueIndex(arg string) (int, error) {
+	index, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("invalid issue index: %s (%w)", arg, err)
+	}
+	return index, nil
+}
+
+// RunTimebox sets the default voting duration of the room: timebox <duration>|off.
+func RunTimebox(g *game.Game, args []string) error {
+	timebox, err := parseTimebox(args)
+	if err != nil {
+		return err
+	}
+	return g.SetTimebox(timebox)
+}
+
+// RunCountdown sets the voting duration of the current vote: countdown <duration>|off.
+func RunCountdown(g *game.Game, args []string) error {
+	duration, err := parseTimebox(args)
+	if err != nil {
+		return err
+	}
+	return g.SetVotingDeadline(duration)
+}
+
+// parseTimebox parses a duration like "90s" or "2m". "off" disables the timebox.
+func parseTimebox(args []string) (time.Duration, error) {
+	if len(args) == 0 {
+		return 0, errors.New("no duration provided")
+	}
+	if strings.ToLower(args[0]) == "off" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s (%w)", args[0], err)
+	}
+	return duration, nil
+}
+
+// RunAutoReveal sets when votes are revealed automatically: autoreveal <policy> [quorum%] [delay].
+func RunAutoReveal(g *game.Game, args []string) error {
+	settings, err := ParseAutoRevealSettings(args)
+	if err != nil {
+		return err
+	}
+	return g.SetAutoReveal(settings)
+}
+
+// ParseAutoRevealSettings parses an auto reveal policy name, followed by the quorum percentage
+// for the quorum policy and an optional delay, e.g. "quorum 75% 3s" or "all-voted 1s".
+func ParseAutoRevealSettings(args []string) (protocol.AutoRevealSettings, error) {
+	settings := protocol.AutoRevealSettings{}
+	if len(args) == 0 {
+		return settings, fmt.Errorf("no auto reveal policy provided, available policies: %s",
+			joinAutoRevealPolicies(game.AvailableAutoRevealPolicies))
+	}
+
+	settings.Policy = protocol.AutoRevealPolicy(strings.ToLower(args[0]))
+	if !slices.Contains(game.AvailableAutoRevealPolicies, settings.Policy) {
+		return settings, fmt.Errorf("unknown auto reveal policy: '%s', available policies: %s",
+			args[0], joinAutoRevealPolicies(game.AvailableAutoRevealPolicies))
+	}
+	args = args[1:]
+
+	if settings.Policy == protocol.AutoRevealQuorum {
+		if len(args) == 0 {
+			return settings, errors.New("no quorum percentage provided")
+		}
+		quorum, err := strconv.Atoi(strings.TrimSuffix(args[0], "%"))
+		if err != nil {
+			return settings, fmt.Errorf("invalid quorum: %s (%w)", args[0], err)
+		}
+		settings.Quorum = quorum
+		args = args[1:]
+	}
+
+	if len(args) > 1 {
+		return settings, errors.New("too many arguments")
+	}
+	if len(args) == 1 {
+		delay, err := time.ParseDuration(args[0])
+		if err != nil {
+			return settings, fmt.Errorf("invalid delay: %s (%w)", args[0], err)
+		}
+		milliseconds := delay.Milliseconds()
+		settings.Delay = &milliseconds
+	}
+
+	return settings, game.ValidateAutoRevealSettings(&settings)
+}
+
+func joinAutoRevealPolicies(policies []protocol.AutoRevealPolicy) string {
+	names := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		names = append(names, string(policy))
+	}
+	return strings.Join(names, ", ")
+}
+
+// RunHint sets the hint strategy of the room: hint <strategy> [maxMeanDeviation] [maxDeviation].
+func RunHint(g *game.Game, args []string) error {
+	settings, err := ParseHintSettings(args)
+	if err != nil {
+		return err
+	}
+	return g.SetHintSettings(settings)
+}
+
+// ParseHintSettings parses a hint strategy name followed by optional thresholds.
+func ParseHintSettings(args []string) (protocol.HintSettings, error) {
+	settings := protocol.HintSettings{}
+	if len(args) == 0 {
+		return settings, fmt.Errorf("no hint strategy provided, available strategies: %s",
+			joinHintStrategies(game.AvailableHintStrategies))
+	}
+	if len(args) > 3 {
+		return settings, errors.New("too many arguments")
+	}
+
+	settings.Strategy = protocol.HintStrategy(strings.ToLower(args[0]))
+	if !slices.Contains(game.AvailableHintStrategies, settings.Strategy) {
+		return settings, fmt.Errorf("unknown hint strategy: '%s', available strategies: %s",
+			args[0], joinHintStrategies(game.AvailableHintStrategies))
+	}
+
+	thresholds := []*float64{&settings.MaxMeanDeviation, &settings.MaxDeviation}
+	for i, arg := range args[1:] {
+		value, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return settings, fmt.Errorf("invalid hint threshold: %s (%w)", arg, err)
+		}
+		*thresholds[i] = value
+	}
+
+	return settings, nil
+}
+
+func joinHintStrategies(strategies []protocol.HintStrategy) string {
+	names := make([]string, 0, len(strategies))
+	for _, strategy := range strategies {
+		names = append(names, string(strategy))
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParseDeck parses a deck either by name, built-in or from the decks file, or as a list of cards.
+func ParseDeck(args []string) (protocol.Deck, error) {
+	if len(args) == 0 {
+		return nil, errors.New("deck can't be empty")
+	}
+
+	if len(args) == 1 {
+		// attempt to parse deck by name
+		deckName := strings.ToLower(args[0])
+		deck, ok := game.GetDeck(deckName)
+		if !ok {
+			return nil, fmt.Errorf("unknown deck: '%s', available decks: %s",
+				args[0], strings.Join(game.AvailableDecks(), ", "))
+		}
+		return deck, nil
+	}
+
+	return game.NewDeck(args)
+}
@@ -0,0 +1,411 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/six78/2-story-points-cli/internal/actions"
+	"github.com/six78/2-story-points-cli/pkg/game"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+// Server exposes the running game to local tools, e.g. editor plugins.
+// Every request must be authenticated with the token, either with `Authorization: Bearer <token>` header
+// or with `token` query parameter (browsers can't set headers for EventSource).
+
+const tokenLength = 16
+
+var ErrNotLoopback = errors.New("API can only listen on a loopback address")
+
+type Server struct {
+	game     *game.Game
+	logger   *zap.Logger
+	token    string
+	address  string
+	listener net.Listener
+	server   *http.Server
+}
+
+type StateResponse struct {
+	RoomID   string              `json:"roomId,omitempty"`
+	IsDealer bool                `json:"isDealer"`
+	MyVote   protocol.VoteResult `json:"myVote"`
+	Delivery string              `json:"voteDelivery"`
+	State    *protocol.State     `json:"state"`
+}
+
+type IssueRequest struct {
+	Issue string `json:"issue"`
+}
+
+type IssueResponse struct {
+	IssueID protocol.IssueID `json:"issueId"`
+}
+
+type VoteRequest struct {
+	Value protocol.VoteValue `json:"value"`
+}
+
+type FinishRequest struct {
+	Result protocol.VoteValue `json:"result"`
+}
+
+type DeckRequest struct {
+	Deck []string `json:"deck"` // Either a deck name or a list of cards
+}
+
+// HintRequest sets the hint strategy, zero thresholds mean defaults.
+type HintRequest = protocol.HintSettings
+
+// TimeboxRequest sets a voting duration like "90s" or "2m", "off" disables it.
+type TimeboxRequest struct {
+	Duration string `json:"duration"`
+}
+
+// AutoRevealRequest sets the auto reveal policy, empty settings mean defaults of the client.
+type AutoRevealRequest = protocol.AutoRevealSettings
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func NewServer(game *game.Game, logger *zap.Logger, address string, token string) (*Server, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid API address")
+	}
+	if host != "localhost" && !net.ParseIP(host).IsLoopback() {
+		return nil, ErrNotLoopback
+	}
+	if token == "" {
+		return nil, errors.New("API token is required")
+	}
+
+	s := &Server{
+		game:    game,
+		logger:  logger,
+		token:   token,
+		address: address,
+	}
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return s, nil
+}
+
+// GenerateToken returns a random token to be used when no token was configured.
+func GenerateToken() (string, error) {
+	token := make([]byte, tokenLength)
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate API token")
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen")
+	}
+	s.listener = listener
+
+	go func() {
+		err := s.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("API server stopped", zap.Error(err))
+		}
+	}()
+
+	s.logger.Info("API server started", zap.String("address", s.Address()))
+	return nil
+}
+
+// Stop closes the server immediately. Graceful shutdown would wait for event streams forever.
+func (s *Server) Stop() {
+	err := s.server.Close()
+	if err != nil {
+		s.logger.Warn("failed to close API server", zap.Error(err))
+	}
+}
+
+// Address returns the actual listening address, which is different from the configured one for port 0.
+func (s *Server) Address() string {
+	if s.listener == nil {
+		return s.address
+	}
+	return s.listener.Addr().String()
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/state", s.get(s.handleState))
+	mux.HandleFunc("/events", s.get(s.handleEvents))
+	mux.HandleFunc("/deal", s.post(s.handleDeal))
+	mux.HandleFunc("/issues", s.post(s.handleAddIssue))
+	mux.HandleFunc("/vote", s.post(s.handleVote))
+	mux.HandleFunc("/reveal", s.post(s.handleReveal))
+	mux.HandleFunc("/revote", s.post(s.handleRevote))
+	mux.HandleFunc("/finish", s.post(s.handleFinish))
+	mux.HandleFunc("/deck", s.post(s.handleDeck))
+	mux.HandleFunc("/hint", s.post(s.handleHint))
+	mux.HandleFunc("/timebox", s.post(s.handleTimebox))
+	mux.HandleFunc("/countdown", s.post(s.handleCountdown))
+	mux.HandleFunc("/autoreveal", s.post(s.handleAutoReveal))
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) get(handler http.HandlerFunc) http.HandlerFunc {
+	return allowMethod(http.MethodGet, handler)
+}
+
+func (s *Server) post(handler http.HandlerFunc) http.HandlerFunc {
+	return allowMethod(http.MethodPost, handler)
+}
+
+func allowMethod(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.stateResponse())
+}
+
+func (s *Server) handleDeal(w http.ResponseWriter, r *http.Request) {
+	var request IssueRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	issueID, err := s.game.Deal(request.Issue)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, IssueResponse{IssueID: issueID})
+}
+
+func (s *Server) handleAddIssue(w http.ResponseWriter, r *http.Request) {
+	var request IssueRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	issueID, err := s.game.AddIssue(request.Issue)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, IssueResponse{IssueID: issueID})
+}
+
+func (s *Server) handleVote(w http.ResponseWriter, r *http.Request) {
+	var request VoteRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	s.respond(w, s.game.PublishVote(request.Value))
+}
+
+func (s *Server) handleReveal(w http.ResponseWriter, r *http.Request) {
+	s.respond(w, s.game.Reveal())
+}
+
+func (s *Server) handleRevote(w http.ResponseWriter, r *http.Request) {
+	s.respond(w, s.game.Revote())
+}
+
+func (s *Server) handleFinish(w http.ResponseWriter, r *http.Request) {
+	var request FinishRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	s.respond(w, s.game.Finish(request.Result))
+}
+
+func (s *Server) handleDeck(w http.ResponseWriter, r *http.Request) {
+	var request DeckRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	s.respond(w, actions.RunDeck(s.game, request.Deck))
+}
+
+func (s *Server) handleHint(w http.ResponseWriter, r *http.Request) {
+	var request HintRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	s.respond(w, s.game.SetHintSettings(request))
+}
+
+func (s *Server) handleTimebox(w http.ResponseWriter, r *http.Request) {
+	var request TimeboxRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	s.respond(w, actions.RunTimebox(s.game, []string{request.Duration}))
+}
+
+func (s *Server) handleCountdown(w http.ResponseWriter, r *http.Request) {
+	var request TimeboxRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	s.respond(w, actions.RunCountdown(s.game, []string{request.Duration}))
+}
+
+func (s *Server) handleAutoReveal(w http.ResponseWriter, r *http.Request) {
+	var request AutoRevealRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	s.respond(w, s.game.SetAutoReveal(request))
+}
+
+// respond writes the new state of the game, or the error if the action failed.
+func (s *Server) respond(w http.ResponseWriter, err error) {
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.stateResponse())
+}
+
+func (s *Server) stateResponse() StateResponse {
+	response := StateResponse{
+		IsDealer: s.game.IsDealer(),
+		MyVote:   s.game.MyVote(),
+		Delivery: s.game.VoteDelivery().String(),
+		State:    s.game.CurrentState(),
+	}
+	if roomID := s.game.RoomID(); !roomID.Empty() {
+		response.RoomID = roomID.String()
+	}
+	return response
+}
+
+// handleEvents streams game events as server-sent events.
+// The current state is sent first, so that clients don't need a separate request.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+		return
+	}
+
+	// Clients that don't keep up are disconnected, they get the current state when they reconnect
+	subscription := s.game.SubscribeNonBlocking()
+	defer s.game.Unsubscribe(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	err := writeEvent(w, game.Event{Tag: game.EventStateChanged, Data: s.game.CurrentState()})
+	if err != nil {
+		return
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, more := <-subscription.Events:
+			if !more {
+				return
+			}
+			err = writeEvent(w, event)
+			if err != nil {
+				s.logger.Debug("failed to write event", zap.Error(err))
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event game.Event) error {
+	name, data := eventPayload(event)
+	if name == "" {
+		return nil
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event")
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+	return err
+}
+
+func eventPayload(event game.Event) (string, interface{}) {
+	switch event.Tag {
+	case game.EventStateChanged:
+		return "state", event.Data
+	case game.EventAutoRevealScheduled:
+		if duration, ok := event.Data.(time.Duration); ok {
+			return "autoRevealScheduled", map[string]int64{"delayMs": duration.Milliseconds()}
+		}
+	case game.EventAutoRevealCancelled:
+		return "autoRevealCancelled", nil
+	case game.EventDealerChanged:
+		return "dealerChanged", map[string]interface{}{"isDealer": event.Data}
+	case game.EventVoteDelivery:
+		if delivery, ok := event.Data.(game.VoteDelivery); ok {
+			return "voteDelivery", map[string]string{"delivery": delivery.String()}
+		}
+	}
+	return "", nil
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, target interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(target)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "failed to parse request"))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/six78/2-story-points-cli/internal/testcommon"
+	"github.com/six78/2-story-points-cli/internal/transport"
+	mocktransport "github.com/six78/2-story-points-cli/internal/transport/mock"
+	"github.com/six78/2-story-points-cli/pkg/game"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+func TestServer(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+type Suite struct {
+	testcommon.Suite
+
+	ctx        context.Context
+	cancel     context.CancelFunc
+	game       *game.Game
+	token      string
+	httpServer *httptest.Server
+}
+
+func (s *Suite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	ctrl := gomock.NewController(s.T())
+	transportMock := mocktransport.NewMockService(ctrl)
+	transportMock.EXPECT().SubscribeToMessages(gomock.Any(), gomock.Any()).
+		Return(&transport.MessagesSubscription{
+			Ch:          make(chan []byte),
+			Unsubscribe: func() {},
+		}, nil).
+		AnyTimes()
+	transportMock.EXPECT().PublishPublicMessage(gomock.Any(), gomock.Any()).AnyTimes()
+	transportMock.EXPECT().SubscribeToConnectionStatus().
+		Return(make(transport.ConnectionStatusSubscription)).
+		AnyTimes()
+
+	s.game = game.NewGame([]game.Option{
+		game.WithContext(s.ctx),
+		game.WithTransport(transportMock),
+		game.WithClock(clockwork.NewFakeClock()),
+		game.WithLogger(s.Logger),
+		game.WithPlayerName(gofakeit.Username()),
+		game.WithPublishStateLoop(false),
+		game.WithAutoReveal(false, 0),
+	})
+	s.Require().NotNil(s.game)
+
+	err := s.game.Initialize()
+	s.Require().NoError(err)
+
+	room, initialState, err := s.game.CreateNewRoom()
+	s.Require().NoError(err)
+	err = s.game.JoinRoom(room.ToRoomID(), initialState)
+	s.Require().NoError(err)
+
+	s.token, err = GenerateToken()
+	s.Require().NoError(err)
+
+	server, err := NewServer(s.game, s.Logger, "127.0.0.1:0", s.token)
+	s.Require().NoError(err)
+
+	s.httpServer = httptest.NewServer(server.Handler())
+}
+
+func (s *Suite) TearDownTest() {
+	s.httpServer.Close()
+	s.cancel()
+}
+
+func (s *Suite) request(method string, path string, body interface{}, response interface{}) int {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		s.Require().NoError(err)
+	}
+
+	request, err := http.NewRequest(method, s.httpServer.URL+path, bytes.NewReader(payload))
+	s.Require().NoError(err)
+	request.Header.Set("Authorization", "Bearer "+s.token)
+
+	result, err := http.DefaultClient.Do(request)
+	s.Require().NoError(err)
+	defer result.Body.Close()
+
+	if response != nil {
+		err = json.NewDecoder(result.Body).Decode(response)
+		s.Require().NoError(err)
+	}
+
+	return result.StatusCode
+}
+
+func (s *Suite) TestNewServer() {
+	_, err := NewServer(s.game, s.Logger, "0.0.0.0:1234", s.token)
+	s.Require().ErrorIs(err, ErrNotLoopback)
+
+	_, err = NewServer(s.game, s.Logger, "localhost:1234", "")
+	s.Require().Error(err)
+
+	_, err = NewServer(s.game, s.Logger, "[::1]:1234", s.token)
+	s.Require().NoError(err)
+}
+
+func (s *Suite) TestAuthentication() {
+	result, err := http.Get(s.httpServer.URL + "/state")
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnauthorized, result.StatusCode)
+	_ = result.Body.Close()
+
+	result, err = http.Get(s.httpServer.URL + "/state?token=" + gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnauthorized, result.StatusCode)
+	_ = result.Body.Close()
+
+	result, err = http.Get(s.httpServer.URL + "/state?token=" + s.token)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, result.StatusCode)
+	_ = result.Body.Close()
+}
+
+func (s *Suite) TestActions() {
+	var state StateResponse
+	status := s.request(http.MethodGet, "/state", nil, &state)
+	s.Require().Equal(http.StatusOK, status)
+	s.Require().Equal(s.game.RoomID().String(), state.RoomID)
+	s.Require().True(state.IsDealer)
+
+	status = s.request(http.MethodGet, "/deal", nil, nil)
+	s.Require().Equal(http.StatusMethodNotAllowed, status)
+
+	var issue IssueResponse
+	status = s.request(http.MethodPost, "/deal", IssueRequest{Issue: gofakeit.URL()}, &issue)
+	s.Require().Equal(http.StatusOK, status)
+	s.Require().Equal(issue.IssueID, s.game.CurrentState().ActiveIssue)
+
+	var errorResponse ErrorResponse
+	status = s.request(http.MethodPost, "/deck", DeckRequest{Deck: []string{"1", "2"}}, &errorResponse)
+	s.Require().Equal(http.StatusBadRequest, status)
+	s.Require().NotEmpty(errorResponse.Error)
+
+	status = s.request(http.MethodPost, "/reveal", nil, &state)
+	s.Require().Equal(http.StatusOK, status)
+	s.Require().Equal(protocol.RevealedState, state.State.VoteState())
+
+	result := s.game.CurrentState().Deck[0].Value
+	status = s.request(http.MethodPost, "/finish", FinishRequest{Result: result}, &state)
+	s.Require().Equal(http.StatusOK, status)
+	s.Require().Equal(&result, state.State.Issues.Get(issue.IssueID).Result)
+
+	status = s.request(http.MethodPost, "/deck", DeckRequest{Deck: []string{"1", "2"}}, &state)
+	s.Require().Equal(http.StatusOK, status)
+	s.Require().Equal(protocol.DeckFromValues("1", "2"), state.State.Deck)
+
+	hint := HintRequest{Strategy: protocol.ModeHintStrategy}
+	status = s.request(http.MethodPost, "/hint", hint, &state)
+	s.Require().Equal(http.StatusOK, status)
+	s.Require().Equal(&hint, state.State.Hints)
+
+	status = s.request(http.MethodPost, "/timebox", TimeboxRequest{Duration: "2m"}, &state)
+	s.Require().Equal(http.StatusOK, status)
+	s.Require().Equal((2 * time.Minute).Milliseconds(), state.State.Timebox)
+
+	autoReveal := AutoRevealRequest{Policy: protocol.AutoRevealQuorum, Quorum: 75}
+	status = s.request(http.MethodPost, "/autoreveal", autoReveal, &state)
+	s.Require().Equal(http.StatusOK, status)
+	s.Require().Equal(&autoReveal, state.State.AutoReveal)
+
+	var addedIssue IssueResponse
+	status = s.request(http.MethodPost, "/issues", IssueRequest{Issue: gofakeit.URL()}, &addedIssue)
+	s.Require().Equal(http.StatusOK, status)
+	s.Require().NotNil(s.game.CurrentState().Issues.Get(addedIssue.IssueID))
+}
+
+func (s *Suite) TestEvents() {
+	result, err := http.Get(s.httpServer.URL + "/events?token=" + s.token)
+	s.Require().NoError(err)
+	defer result.Body.Close()
+	s.Require().Equal(http.StatusOK, result.StatusCode)
+	s.Require().Equal("text/event-stream", result.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(result.Body)
+	readEvent := func() (string, string) {
+		var name, data string
+		for {
+			line, err := reader.ReadString('\n')
+			s.Require().NoError(err)
+			line = strings.TrimSpace(line)
+			if line == "" {
+				return name, data
+			}
+			if value, ok := strings.CutPrefix(line, "event: "); ok {
+				name = value
+			}
+			if value, ok := strings.CutPrefix(line, "data: "); ok {
+				data = value
+			}
+		}
+	}
+
+	// Initial state
+	name, _ := readEvent()
+	s.Require().Equal("state", name)
+
+	issue := gofakeit.URL()
+	_, err = s.game.Deal(issue)
+	s.Require().NoError(err)
+
+	name, data := readEvent()
+	s.Require().Equal("state", name)
+
+	var state protocol.State
+	err = json.Unmarshal([]byte(data), &state)
+	s.Require().NoError(err)
+	s.Require().Len(state.Issues, 1)
+	s.Require().Equal(issue, state.Issues[0].TitleOrURL)
+}
@@ -16,18 +16,29 @@ import (
 const OnlineMessagePeriod = 5 * time.Second
 const StateMessagePeriod = 30 * time.Second
 const logsDirectory = "logs"
+const decksFileName = "decks.json"
 const SymmetricKeyLength = 32
 const EnableSymmetricEncryption = true
 
+const (
+	TransportWaku     = "waku"
+	TransportLoopback = "loopback"
+	TransportLan      = "lan"
+)
+
 const VendorName = "six78"
 const ApplicationName = "2sp"
 
 const UserColor = lipgloss.Color("#7D56F4")
 const ForegroundShadeColor = lipgloss.Color("#555555")
 
+var transport string
+var lanAddress string
+var lanInterface string
 var fleet string
 var nameserver string
 var playerName string
+var observer bool
 var initialAction string
 var debug bool
 var anonymous bool
@@ -35,7 +46,16 @@ var wakuStaticNodes StaticWakuNodes
 var wakuLightMode bool
 var wakuDiscV5 bool
 var wakuDnsDiscovery bool
+var wakuHistory bool
+var encoding string
 var demo bool
+var daemon bool
+var daemonSocket string
+var apiAddress string
+var apiToken string
+var decksFile string
+var autoReveal bool
+var autoRevealDelay time.Duration
 var version bool
 
 var Logger *zap.Logger
@@ -90,15 +110,28 @@ func createLogFile() string {
 
 func ParseArguments() {
 	flag.StringVar(&playerName, "name", "", "Player name")
+	flag.BoolVar(&observer, "observer", false, "Watch the game without voting, e.g. as a product owner")
 	flag.BoolVar(&debug, "debug", false, "Show debug info")
 	flag.BoolVar(&anonymous, "anonymous", false, "Anonymous mode")
+	flag.StringVar(&transport, "transport", TransportWaku, "Messages transport: waku, lan (local network multicast) or loopback (in-process, no peers needed)")
+	flag.StringVar(&lanAddress, "lan.address", "239.255.78.2:27878", "LAN transport multicast group address")
+	flag.StringVar(&lanInterface, "lan.interface", "", "LAN transport network interface, system default if not set")
 	flag.StringVar(&fleet, "waku.fleet", "shards.test", "Waku fleet name")
 	flag.StringVar(&nameserver, "waku.nameserver", "", "Waku nameserver")
 	flag.Var(&wakuStaticNodes, "waku.staticnode", "Waku static node multiaddress")
 	flag.BoolVar(&wakuLightMode, "waku.lightmode", false, "Waku lightpush/filter mode")
 	flag.BoolVar(&wakuDiscV5, "waku.discv5", true, "Enable DiscV5 discovery")
 	flag.BoolVar(&wakuDnsDiscovery, "waku.dnsdiscovery", true, "Enable DNS discovery")
+	flag.BoolVar(&wakuHistory, "waku.history", true, "Retrieve recent room messages from Waku store nodes on join")
+	flag.StringVar(&encoding, "encoding", "json", "Messages encoding: json or proto")
 	flag.BoolVar(&demo, "demo", false, "Run demo and quit")
+	flag.BoolVar(&daemon, "daemon", false, "Run without UI, accept JSON line commands from stdin")
+	flag.StringVar(&daemonSocket, "daemon.socket", "", "Accept daemon commands from a Unix socket instead of stdin")
+	flag.StringVar(&apiAddress, "api", "", "Enable local HTTP API on given loopback address, e.g. 127.0.0.1:7878")
+	flag.StringVar(&apiToken, "api.token", "", "HTTP API token, generated on each start if not set")
+	flag.StringVar(&decksFile, "decks", "", "User decks file, "+decksFileName+" in the config directory if not set")
+	flag.BoolVar(&autoReveal, "auto-reveal", true, "Reveal votes when all players voted, unless the room sets another auto reveal policy")
+	flag.DurationVar(&autoRevealDelay, "auto-reveal.delay", time.Second, "Delay before votes are revealed automatically")
 	flag.BoolVar(&version, "version", false, "Print version and quit")
 	flag.Parse()
 
@@ -109,6 +142,18 @@ func GeneratePlayerName() string {
 	return fmt.Sprintf("player-%d", time.Now().Unix())
 }
 
+func Transport() string {
+	return transport
+}
+
+func LanAddress() string {
+	return lanAddress
+}
+
+func LanInterface() string {
+	return lanInterface
+}
+
 func Fleet() string {
 	return fleet
 }
@@ -121,6 +166,10 @@ func PlayerName() string {
 	return playerName
 }
 
+func Observer() bool {
+	return observer
+}
+
 func InitialAction() string {
 	return initialAction
 }
@@ -149,8 +198,50 @@ func WakuDnsDiscovery() bool {
 	return wakuDnsDiscovery
 }
 
+func WakuHistory() bool {
+	return wakuHistory
+}
+
+func Encoding() string {
+	return encoding
+}
+
 func Demo() bool {
 	return demo
 }
 
 func Version() bool { return version }
+
+func Daemon() bool {
+	return daemon
+}
+
+func DaemonSocket() string {
+	return daemonSocket
+}
+
+func APIAddress() string {
+	return apiAddress
+}
+
+func APIToken() string {
+	return apiToken
+}
+
+func AutoReveal() bool {
+	return autoReveal
+}
+
+func AutoRevealDelay() time.Duration {
+	return autoRevealDelay
+}
+
+// DecksFile returns the path of the user decks file.
+func DecksFile() string {
+	if decksFile != "" {
+		return decksFile
+	}
+	configDirs := configdir.New(VendorName, ApplicationName)
+	folders := configDirs.QueryFolders(configdir.Global)
+	return filepath.Join(folders[0].Path, decksFileName)
+}
@@ -0,0 +1,202 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/six78/2-story-points-cli/internal/actions"
+	"github.com/six78/2-story-points-cli/pkg/game"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+// Daemon runs the game without the TUI, e.g. to keep a dealer alive on a server.
+// Commands are accepted as JSON lines, either from stdin or from a local Unix socket.
+
+const (
+	ActionNew   = "new"   // Create a new room and join it as a dealer
+	ActionJoin  = "join"  // Join an existing room
+	ActionLeave = "leave" // Leave the current room
+	ActionState = "state" // Return the current state without any changes
+)
+
+// Request is a single command. Any of the dealer actions (see actions.Action) is also accepted.
+type Request struct {
+	ID     string   `json:"id,omitempty"` // Optional, copied to the response
+	Action string   `json:"action"`
+	Args   []string `json:"args,omitempty"`
+}
+
+type Response struct {
+	ID       string          `json:"id,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	RoomID   string          `json:"roomId,omitempty"`
+	IsDealer bool            `json:"isDealer"`
+	State    *protocol.State `json:"state,omitempty"` // Copy of the state, the game goes on while it's encoded
+}
+
+type Daemon struct {
+	game   *game.Game
+	logger *zap.Logger
+	lock   sync.Mutex // Requests from different connections are processed one by one
+}
+
+func New(game *game.Game, logger *zap.Logger) *Daemon {
+	return &Daemon{
+		game:   game,
+		logger: logger,
+	}
+}
+
+// Handle processes a single request and returns the resulting state of the game.
+func (d *Daemon) Handle(request Request) Response {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.logger.Debug("handling request", zap.Any("request", request))
+
+	err := d.run(request)
+	if err != nil {
+		d.logger.Warn("request failed", zap.Any("request", request), zap.Error(err))
+	}
+
+	response := Response{
+		ID:       request.ID,
+		IsDealer: d.game.IsDealer(),
+		State:    d.game.CurrentState(),
+	}
+	if err != nil {
+		response.Error = err.Error()
+	}
+	if roomID := d.game.RoomID(); !roomID.Empty() {
+		response.RoomID = roomID.String()
+	}
+
+	return response
+}
+
+func (d *Daemon) run(request Request) error {
+	switch request.Action {
+	case ActionNew:
+		room, initialState, err := d.game.CreateNewRoom()
+		if err != nil {
+			return err
+		}
+		return d.game.JoinRoom(room.ToRoomID(), initialState)
+
+	case ActionJoin:
+		if len(request.Args) == 0 {
+			return errors.New("no room id argument provided")
+		}
+		return d.game.JoinRoom(protocol.NewRoomID(request.Args[0]), nil)
+
+	case ActionLeave:
+		d.game.LeaveRoom()
+		return nil
+
+	case ActionState:
+		return nil
+	}
+
+	return actions.Run(d.game, actions.Action(request.Action), request.Args)
+}
+
+// Serve reads JSON line requests from the reader and writes a JSON line response for each of them.
+// Returns when the reader is exhausted or the context is cancelled.
+func (d *Daemon) Serve(ctx context.Context, reader io.Reader, writer io.Writer) error {
+	scanner := bufio.NewScanner(reader)
+	encoder := json.NewEncoder(writer)
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var request Request
+		var response Response
+
+		err := json.Unmarshal(line, &request)
+		if err != nil {
+			response.Error = errors.Wrap(err, "failed to parse request").Error()
+		} else {
+			response = d.Handle(request)
+		}
+
+		err = encoder.Encode(response)
+		if err != nil {
+			return errors.Wrap(err, "failed to write response")
+		}
+	}
+
+	return scanner.Err()
+}
+
+// removeStaleSocket removes the socket left by a previous run.
+// Anything else found at the path is kept and reported as an error.
+func removeStaleSocket(socketPath string) error {
+	info, err := os.Lstat(socketPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to check socket path")
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return errors.Errorf("%s exists and is not a socket", socketPath)
+	}
+	err = os.Remove(socketPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove stale socket")
+	}
+	return nil
+}
+
+// ListenUnix accepts connections on a Unix socket and serves each of them until the context is cancelled.
+func (d *Daemon) ListenUnix(ctx context.Context, socketPath string) error {
+	err := removeStaleSocket(socketPath)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen on socket")
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	d.logger.Info("listening for commands", zap.String("socket", socketPath))
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, "failed to accept connection")
+		}
+
+		go func() {
+			defer conn.Close()
+			err := d.Serve(ctx, conn, conn)
+			if err != nil {
+				d.logger.Warn("connection closed with error", zap.Error(err))
+			}
+		}()
+	}
+}
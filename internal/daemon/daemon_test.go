@@ -0,0 +1,179 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/six78/2-story-points-cli/internal/testcommon"
+	"github.com/six78/2-story-points-cli/internal/transport"
+	mocktransport "github.com/six78/2-story-points-cli/internal/transport/mock"
+	"github.com/six78/2-story-points-cli/pkg/game"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+func TestDaemon(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+type Suite struct {
+	testcommon.Suite
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	daemon *Daemon
+}
+
+func (s *Suite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	ctrl := gomock.NewController(s.T())
+	transportMock := mocktransport.NewMockService(ctrl)
+	transportMock.EXPECT().SubscribeToMessages(gomock.Any(), gomock.Any()).
+		Return(&transport.MessagesSubscription{
+			Ch:          make(chan []byte),
+			Unsubscribe: func() {},
+		}, nil).
+		AnyTimes()
+	transportMock.EXPECT().PublishPublicMessage(gomock.Any(), gomock.Any()).AnyTimes()
+	transportMock.EXPECT().SubscribeToConnectionStatus().
+		Return(make(transport.ConnectionStatusSubscription)).
+		AnyTimes()
+
+	g := game.NewGame([]game.Option{
+		game.WithContext(s.ctx),
+		game.WithTransport(transportMock),
+		game.WithClock(clockwork.NewFakeClock()),
+		game.WithLogger(s.Logger),
+		game.WithPlayerName(gofakeit.Username()),
+		game.WithPublishStateLoop(false),
+	})
+	s.Require().NotNil(g)
+
+	err := g.Initialize()
+	s.Require().NoError(err)
+
+	s.daemon = New(g, s.Logger)
+}
+
+func (s *Suite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *Suite) TestHandle() {
+	response := s.daemon.Handle(Request{Action: ActionState})
+	s.Require().Empty(response.Error)
+	s.Require().Empty(response.RoomID)
+	s.Require().Nil(response.State)
+
+	response = s.daemon.Handle(Request{ID: "1", Action: ActionNew})
+	s.Require().Empty(response.Error)
+	s.Require().Equal("1", response.ID)
+	s.Require().NotEmpty(response.RoomID)
+	s.Require().True(response.IsDealer)
+	s.Require().NotNil(response.State)
+
+	issue := gofakeit.URL()
+	response = s.daemon.Handle(Request{Action: "deal", Args: []string{issue}})
+	s.Require().Empty(response.Error)
+	s.Require().Len(response.State.Issues, 1)
+	s.Require().Equal(issue, response.State.Issues[0].TitleOrURL)
+	s.Require().Equal(protocol.VotingState, response.State.VoteState())
+
+	response = s.daemon.Handle(Request{Action: "reveal"})
+	s.Require().Empty(response.Error)
+	s.Require().Equal(protocol.RevealedState, response.State.VoteState())
+
+	response = s.daemon.Handle(Request{Action: "finish", Args: []string{"not-in-deck"}})
+	s.Require().NotEmpty(response.Error)
+
+	result := response.State.Deck[0].Value
+	response = s.daemon.Handle(Request{Action: "finish", Args: []string{string(result)}})
+	s.Require().Empty(response.Error)
+	s.Require().Equal(&result, response.State.Issues[0].Result)
+
+	response = s.daemon.Handle(Request{Action: "unknown"})
+	s.Require().Contains(response.Error, "unknown action")
+
+	response = s.daemon.Handle(Request{Action: ActionLeave})
+	s.Require().Empty(response.Error)
+	s.Require().Empty(response.RoomID)
+}
+
+func (s *Suite) TestStateCopy() {
+	response := s.daemon.Handle(Request{Action: ActionNew})
+	s.Require().Empty(response.Error)
+	state := response.State
+
+	response = s.daemon.Handle(Request{Action: "deal", Args: []string{gofakeit.URL()}})
+	s.Require().Empty(response.Error)
+	s.Require().Len(response.State.Issues, 1)
+
+	// Responses are not changed by the game afterwards
+	s.Require().Empty(state.Issues)
+}
+
+func (s *Suite) TestServe() {
+	input := strings.Join([]string{
+		`{"id":"1","action":"new"}`,
+		``,
+		`{invalid json`,
+		`{"id":"2","action":"add","args":["first","issue"]}`,
+	}, "\n")
+	output := bytes.NewBuffer(nil)
+
+	err := s.daemon.Serve(s.ctx, strings.NewReader(input), output)
+	s.Require().NoError(err)
+
+	decoder := json.NewDecoder(output)
+	responses := make([]Response, 0, 3)
+	for decoder.More() {
+		var response Response
+		err = decoder.Decode(&response)
+		s.Require().NoError(err)
+		responses = append(responses, response)
+	}
+
+	s.Require().Len(responses, 3)
+	s.Require().Equal("1", responses[0].ID)
+	s.Require().Empty(responses[0].Error)
+	s.Require().NotEmpty(responses[1].Error)
+	s.Require().Equal("2", responses[2].ID)
+	s.Require().Empty(responses[2].Error)
+	s.Require().Len(responses[2].State.Issues, 1)
+	s.Require().Equal("first issue", responses[2].State.Issues[0].TitleOrURL)
+}
+
+func (s *Suite) TestRemoveStaleSocket() {
+	dir, err := os.MkdirTemp("", "2sp")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+
+	// Missing socket is fine
+	socketPath := filepath.Join(dir, "2sp.sock")
+	s.Require().NoError(removeStaleSocket(socketPath))
+
+	// Socket left by a previous run is removed
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	s.Require().NoError(err)
+	listener.SetUnlinkOnClose(false)
+	s.Require().NoError(listener.Close())
+	s.Require().NoError(removeStaleSocket(socketPath))
+	s.Require().NoFileExists(socketPath)
+
+	// Other files are kept
+	filePath := filepath.Join(dir, "file")
+	s.Require().NoError(os.WriteFile(filePath, []byte(gofakeit.Sentence(3)), 0600))
+	s.Require().Error(removeStaleSocket(filePath))
+	s.Require().FileExists(filePath)
+}
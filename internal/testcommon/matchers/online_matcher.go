@@ -1,7 +1,6 @@
 package matchers
 
 import (
-	"encoding/json"
 	"testing"
 
 	"github.com/six78/2-story-points-cli/pkg/protocol"
@@ -29,7 +28,7 @@ func (m *OnlineMatcher) Matches(x interface{}) bool {
 	}
 
 	var onlineMessage protocol.PlayerOnlineMessage
-	err := json.Unmarshal(m.payload, &onlineMessage)
+	err := protocol.Unmarshal(m.payload, &onlineMessage)
 	if err != nil {
 		return false
 	}
@@ -1,7 +1,6 @@
 package matchers
 
 import (
-	"encoding/json"
 	"testing"
 
 	"github.com/six78/2-story-points-cli/pkg/protocol"
@@ -12,8 +11,9 @@ type Callback func(state *protocol.State) bool
 type StateMatcher struct {
 	Matcher
 	MessageMatcher
-	cb    Callback
-	state protocol.State
+	cb      Callback
+	state   protocol.State
+	tracker *StateTracker
 }
 
 func NewStateMatcher(t *testing.T, cb Callback) *StateMatcher {
@@ -23,6 +23,13 @@ func NewStateMatcher(t *testing.T, cb Callback) *StateMatcher {
 	}
 }
 
+// WithTracker allows the matcher to match state delta messages.
+// The tracker should be shared between all state matchers of the game.
+func (m *StateMatcher) WithTracker(tracker *StateTracker) *StateMatcher {
+	m.tracker = tracker
+	return m
+}
+
 func (m *StateMatcher) Matches(x interface{}) bool {
 	var state protocol.State
 
@@ -31,15 +38,33 @@ func (m *StateMatcher) Matches(x interface{}) bool {
 		if !m.MessageMatcher.Matches(x) {
 			return false
 		}
-		if m.message.Type != protocol.MessageTypeState {
-			return false
-		}
-		var stateMessage protocol.GameStateMessage
-		err := json.Unmarshal(m.payload, &stateMessage)
-		if err != nil {
+		switch {
+		case m.message.Type == protocol.MessageTypeState:
+			var stateMessage protocol.GameStateMessage
+			err := protocol.Unmarshal(m.payload, &stateMessage)
+			if err != nil {
+				return false
+			}
+			state = stateMessage.State
+			if m.tracker != nil {
+				m.tracker.set(&stateMessage)
+			}
+
+		case m.message.Type == protocol.MessageTypeStateDelta && m.tracker != nil:
+			var deltaMessage protocol.StateDeltaMessage
+			err := protocol.Unmarshal(m.payload, &deltaMessage)
+			if err != nil {
+				return false
+			}
+			trackedState, ok := m.tracker.apply(&deltaMessage)
+			if !ok {
+				return false
+			}
+			state = *trackedState
+
+		default:
 			return false
 		}
-		state = stateMessage.State
 
 	case *protocol.State:
 		state = *x
@@ -0,0 +1,56 @@
+package matchers
+
+import (
+	"sync"
+
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+// StateTracker reconstructs the published state from full state and state delta messages.
+// Matchers might be called several times with the same message, so applying the same delta again
+// returns the already reconstructed state.
+type StateTracker struct {
+	lock     sync.Mutex
+	state    *protocol.State
+	sequence int64
+}
+
+func NewStateTracker() *StateTracker {
+	return &StateTracker{}
+}
+
+func (t *StateTracker) set(message *protocol.GameStateMessage) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.state = message.State.Clone()
+	t.sequence = message.Sequence
+}
+
+func (t *StateTracker) apply(message *protocol.StateDeltaMessage) (*protocol.State, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.state == nil {
+		return nil, false
+	}
+
+	if message.Sequence == t.sequence {
+		return t.state.Clone(), true
+	}
+
+	if message.BaseSequence != t.sequence {
+		return nil, false
+	}
+
+	t.state.ApplyDelta(&message.Delta)
+	t.sequence = message.Sequence
+
+	return t.state.Clone(), true
+}
+
+func (t *StateTracker) Sequence() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.sequence
+}
@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/waku-org/go-waku/waku/v2/protocol"
+	"github.com/waku-org/go-waku/waku/v2/protocol/pb"
+	"github.com/waku-org/go-waku/waku/v2/protocol/store"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// historyPeriod covers at least one state message, which the dealer publishes every 30 seconds
+	historyPeriod       = time.Minute
+	historyPageSize     = 50
+	historyMaxPages     = 5
+	historyAttempts     = 3
+	historyRetryDelay   = 5 * time.Second
+	historyQueryTimeout = 10 * time.Second
+)
+
+// retrieveHistory queries store nodes for the recent messages of the room and sends them to the channel.
+// Right after start there might be no store peers yet, so the query is retried a few times.
+// The channel is closed when finished.
+func (n *Node) retrieveHistory(contentTopics []string, history chan<- *pb.WakuMessage, leaveRoom <-chan struct{}) {
+	defer close(history)
+
+	for attempt := 1; attempt <= historyAttempts; attempt++ {
+		messages, err := n.queryHistory(contentTopics)
+		if err == nil {
+			n.hasHistory.Store(true)
+			n.logger.Info("history retrieved", zap.Int("messages", len(messages)))
+			for _, message := range messages {
+				select {
+				case history <- message:
+				case <-leaveRoom:
+					return
+				}
+			}
+			return
+		}
+
+		n.logger.Warn("failed to retrieve history", zap.Int("attempt", attempt), zap.Error(err))
+
+		select {
+		case <-n.clock.After(historyRetryDelay):
+		case <-leaveRoom:
+			return
+		case <-n.ctx.Done():
+			return
+		}
+	}
+}
+
+func (n *Node) queryHistory(contentTopics []string) ([]*pb.WakuMessage, error) {
+	ctx, cancel := context.WithTimeout(n.ctx, historyQueryTimeout)
+	defer cancel()
+
+	criteria := historyCriteria(n.pubsubTopic, contentTopics, n.clock.Now())
+	result, err := n.waku.Store().Query(ctx, criteria, store.WithPaging(true, historyPageSize))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query store")
+	}
+
+	var messages []*pb.WakuMessage
+	for page := 0; page < historyMaxPages && !result.IsComplete(); page++ {
+		for _, message := range result.Messages() {
+			messages = append(messages, message.GetMessage())
+		}
+		err = result.Next(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query next page")
+		}
+	}
+
+	return messages, nil
+}
+
+// historyCriteria selects messages of the room published during the last historyPeriod, oldest first.
+func historyCriteria(pubsubTopic string, contentTopics []string, now time.Time) store.FilterCriteria {
+	return store.FilterCriteria{
+		ContentFilter: protocol.NewContentFilter(pubsubTopic, contentTopics...),
+		TimeStart:     proto.Int64(now.Add(-historyPeriod).UnixNano()),
+		TimeEnd:       proto.Int64(now.UnixNano()),
+	}
+}
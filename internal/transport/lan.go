@@ -0,0 +1,604 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/waku-org/go-waku/waku/v2/protocol/pb"
+	"github.com/waku-org/go-waku/waku/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/ipv4"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/six78/2-story-points-cli/internal/config"
+	pp "github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+// LanNode exchanges messages directly with other nodes of the local network over UDP multicast.
+// It doesn't need any Waku peers, so planning works on an isolated network.
+// Every datagram is a protobuf-encoded Waku message, encrypted the same way as by the Waku node.
+// Meta field of the message carries the sender node ID. Messages without a content topic are
+// announcements, which nodes send periodically to discover each other.
+// Messages bigger than a datagram, e.g. the full state of a large room, are split into fragments,
+// which are reassembled by receivers.
+type LanNode struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	logger *zap.Logger
+
+	id            string
+	address       string
+	interfaceName string
+	group         *net.UDPAddr
+	multicastIf   *net.Interface
+	listener      *net.UDPConn
+	sender        *net.UDPConn
+	roomCache     ContentTopicCache
+
+	lock          sync.Mutex
+	subscriptions map[string][]*lanSubscription // By content topic
+	peers         map[string]time.Time          // Last time each node was heard
+
+	fragmentsSequence atomic.Uint64
+	fragments         map[string]*lanFragments // By sender and fragmented message ID, only used by the receive loop
+
+	statusLock        sync.Mutex
+	statusSubscribers []ConnectionStatusSubscription
+	connectionStatus  ConnectionStatus
+}
+
+type lanSubscription struct {
+	logger *zap.Logger
+	in     chan *pb.WakuMessage
+}
+
+// lanFragments collects fragments of a message until all of them are received.
+type lanFragments struct {
+	chunks   [][]byte
+	received int
+	started  time.Time
+}
+
+const (
+	lanAnnouncePeriod = 2 * time.Second
+	lanPeerTimeout    = 3 * lanAnnouncePeriod
+	lanMaxPacketSize  = 65507 // Maximum UDP payload over IPv4
+	lanBufferSize     = 100
+
+	// Fragments carry the message ID, fragment index and fragments count in the payload header.
+	// The fragment size leaves space for the header and other fields of the fragment message.
+	lanFragmentTopic      = "/2sp/lan/fragment"
+	lanFragmentHeaderSize = 12
+	lanFragmentSize       = 60000
+	lanMaxFragments       = 64 // Limits the message size to 3.8 MB
+	lanFragmentsTimeout   = 5 * time.Second
+)
+
+// NewLanNode creates a node for the multicast group address, e.g. "239.255.78.2:27878".
+// interfaceName selects the network interface to use, the system default is used when empty.
+func NewLanNode(ctx context.Context, logger *zap.Logger, address string, interfaceName string) *LanNode {
+	ctx, cancel := context.WithCancel(ctx)
+	return &LanNode{
+		ctx:           ctx,
+		cancel:        cancel,
+		logger:        logger.Named("lan"),
+		id:            uuid.New().String(),
+		address:       address,
+		interfaceName: interfaceName,
+		roomCache:     NewRoomCache(logger),
+		subscriptions: make(map[string][]*lanSubscription),
+		peers:         make(map[string]time.Time),
+		fragments:     make(map[string]*lanFragments),
+	}
+}
+
+func (n *LanNode) Initialize() error {
+	group, err := net.ResolveUDPAddr("udp4", n.address)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve multicast address")
+	}
+	if !group.IP.IsMulticast() {
+		return errors.Errorf("not a multicast address: %s", n.address)
+	}
+	n.group = group
+
+	if n.interfaceName != "" {
+		n.multicastIf, err = net.InterfaceByName(n.interfaceName)
+		if err != nil {
+			return errors.Wrap(err, "failed to find network interface")
+		}
+	}
+
+	return nil
+}
+
+func (n *LanNode) Start() error {
+	if n.group == nil {
+		return errors.New("not initialized")
+	}
+
+	listener, err := net.ListenMulticastUDP("udp4", n.multicastIf, n.group)
+	if err != nil {
+		return errors.Wrap(err, "failed to join multicast group")
+	}
+
+	sender, err := net.DialUDP("udp4", nil, n.group)
+	if err != nil {
+		_ = listener.Close()
+		return errors.Wrap(err, "failed to create multicast sender")
+	}
+
+	if n.multicastIf != nil {
+		err = ipv4.NewPacketConn(sender).SetMulticastInterface(n.multicastIf)
+		if err != nil {
+			_ = listener.Close()
+			_ = sender.Close()
+			return errors.Wrap(err, "failed to set multicast interface")
+		}
+	}
+
+	// Fragments of a big message arrive in a burst
+	err = listener.SetReadBuffer(lanMaxFragments * lanMaxPacketSize)
+	if err != nil {
+		n.logger.Warn("failed to set multicast read buffer size", zap.Error(err))
+	}
+
+	n.listener = listener
+	n.sender = sender
+
+	go n.receiveLoop()
+	go n.announceLoop()
+
+	n.logger.Info("lan node started",
+		zap.String("nodeID", n.id),
+		zap.String("group", n.group.String()))
+
+	n.notifyConnectionStatus()
+	return nil
+}
+
+func (n *LanNode) Stop() {
+	n.cancel()
+	if n.listener != nil {
+		_ = n.listener.Close()
+	}
+	if n.sender != nil {
+		_ = n.sender.Close()
+	}
+}
+
+func (n *LanNode) receiveLoop() {
+	buffer := make([]byte, lanMaxPacketSize)
+	for {
+		size, _, err := n.listener.ReadFromUDP(buffer)
+		if err != nil {
+			if n.ctx.Err() == nil {
+				n.logger.Error("failed to read multicast packet", zap.Error(err))
+			}
+			return
+		}
+
+		message := &pb.WakuMessage{}
+		err = proto.Unmarshal(buffer[:size], message)
+		if err != nil {
+			n.logger.Debug("failed to unmarshal multicast packet", zap.Error(err))
+			continue
+		}
+
+		n.handleMessage(message)
+	}
+}
+
+func (n *LanNode) handleMessage(message *pb.WakuMessage) {
+	sender := string(message.Meta)
+	if sender == "" || sender == n.id {
+		// Own messages are delivered locally when published
+		return
+	}
+
+	n.seePeer(sender)
+
+	if message.ContentTopic == lanFragmentTopic {
+		n.handleFragment(sender, message.Payload)
+		return
+	}
+
+	if message.ContentTopic == "" {
+		return
+	}
+
+	n.deliver(message)
+}
+
+func (n *LanNode) handleFragment(sender string, payload []byte) {
+	if len(payload) <= lanFragmentHeaderSize {
+		n.logger.Debug("invalid fragment received")
+		return
+	}
+
+	id := binary.BigEndian.Uint64(payload[0:])
+	index := int(binary.BigEndian.Uint16(payload[8:]))
+	count := int(binary.BigEndian.Uint16(payload[10:]))
+	if count > lanMaxFragments || index >= count {
+		n.logger.Debug("invalid fragment received", zap.Int("index", index), zap.Int("count", count))
+		return
+	}
+
+	n.expireFragments()
+
+	key := fmt.Sprintf("%s/%d", sender, id)
+	fragments, ok := n.fragments[key]
+	if !ok {
+		fragments = &lanFragments{
+			chunks:  make([][]byte, count),
+			started: time.Now(),
+		}
+		n.fragments[key] = fragments
+	}
+	if len(fragments.chunks) != count || fragments.chunks[index] != nil {
+		return
+	}
+
+	fragments.chunks[index] = slices.Clone(payload[lanFragmentHeaderSize:])
+	fragments.received++
+	if fragments.received < count {
+		return
+	}
+
+	delete(n.fragments, key)
+
+	message := &pb.WakuMessage{}
+	err := proto.Unmarshal(bytes.Join(fragments.chunks, nil), message)
+	if err != nil {
+		n.logger.Debug("failed to unmarshal fragmented message", zap.Error(err))
+		return
+	}
+	if string(message.Meta) != sender || message.ContentTopic == lanFragmentTopic {
+		n.logger.Debug("invalid fragmented message received")
+		return
+	}
+
+	n.handleMessage(message)
+}
+
+// expireFragments drops messages that were not completely received, e.g. because of lost packets.
+func (n *LanNode) expireFragments() {
+	for key, fragments := range n.fragments {
+		if time.Since(fragments.started) > lanFragmentsTimeout {
+			delete(n.fragments, key)
+		}
+	}
+}
+
+func (n *LanNode) announceLoop() {
+	ticker := time.NewTicker(lanAnnouncePeriod)
+	defer ticker.Stop()
+
+	for {
+		err := n.send(&pb.WakuMessage{})
+		if err != nil {
+			n.logger.Warn("failed to announce node", zap.Error(err))
+		}
+
+		n.expirePeers()
+
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *LanNode) seePeer(id string) {
+	n.lock.Lock()
+	_, known := n.peers[id]
+	n.peers[id] = time.Now()
+	n.lock.Unlock()
+
+	if !known {
+		n.logger.Debug("peer discovered", zap.String("nodeID", id))
+		n.notifyConnectionStatus()
+	}
+}
+
+func (n *LanNode) expirePeers() {
+	n.lock.Lock()
+	expired := 0
+	for id, seen := range n.peers {
+		if time.Since(seen) > lanPeerTimeout {
+			delete(n.peers, id)
+			expired++
+		}
+	}
+	n.lock.Unlock()
+
+	if expired > 0 {
+		n.notifyConnectionStatus()
+	}
+}
+
+func (n *LanNode) deliver(message *pb.WakuMessage) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for _, subscription := range n.subscriptions[message.ContentTopic] {
+		// Never block the receiver, otherwise all rooms would stall
+		select {
+		case subscription.in <- message:
+		default:
+			subscription.logger.Warn("subscription buffer is full, message dropped")
+		}
+	}
+}
+
+func (n *LanNode) SubscribeToMessages(room *pp.Room, privateKey *ecdsa.PrivateKey) (*MessagesSubscription, error) {
+	n.logger.Debug("subscribing to room", zap.String("roomID", room.ToRoomID().String()))
+
+	// Subscribe to all encodings, so that we can play with clients using a different one
+	contentTopics, err := n.roomCache.GetAll(room)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build content topic")
+	}
+
+	subscription := &lanSubscription{
+		logger: n.logger,
+		in:     make(chan *pb.WakuMessage, lanBufferSize),
+	}
+
+	n.lock.Lock()
+	for _, contentTopic := range contentTopics {
+		n.subscriptions[contentTopic] = append(n.subscriptions[contentTopic], subscription)
+	}
+	n.lock.Unlock()
+
+	leaveRoom := make(chan struct{})
+	sub := &MessagesSubscription{
+		Ch: make(chan []byte, 10),
+		Unsubscribe: func() {
+			close(leaveRoom)
+		},
+	}
+
+	go func() {
+		defer func() {
+			n.unsubscribe(contentTopics, subscription)
+			n.roomCache.Remove(room)
+			close(sub.Ch)
+			n.logger.Debug("subscription channel closed")
+		}()
+
+		for {
+			select {
+			case <-leaveRoom:
+				return
+			case <-n.ctx.Done():
+				return
+			case message := <-subscription.in:
+				payload, err := decryptMessage(room, privateKey, message)
+				if err != nil {
+					// Private messages addressed to other players can't be decrypted, this is expected
+					n.logger.Debug("failed to decrypt message payload", zap.Error(err))
+					continue
+				}
+				select {
+				case sub.Ch <- payload:
+				case <-leaveRoom:
+					return
+				}
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+func (n *LanNode) unsubscribe(contentTopics []string, subscription *lanSubscription) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for _, contentTopic := range contentTopics {
+		subscriptions := slices.DeleteFunc(n.subscriptions[contentTopic], func(other *lanSubscription) bool {
+			return other == subscription
+		})
+		if len(subscriptions) == 0 {
+			delete(n.subscriptions, contentTopic)
+		} else {
+			n.subscriptions[contentTopic] = subscriptions
+		}
+	}
+}
+
+func (n *LanNode) PublishUnencryptedMessage(room *pp.Room, payload []byte) error {
+	message, err := n.buildMessage(room, payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to build message")
+	}
+	return n.publish(message)
+}
+
+func (n *LanNode) PublishPublicMessage(room *pp.Room, payload []byte) error {
+	message, err := n.buildMessage(room, payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to build message")
+	}
+
+	err = encryptPublicPayload(room, message)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt message")
+	}
+
+	return n.publish(message)
+}
+
+func (n *LanNode) PublishPrivateMessage(room *pp.Room, payload []byte, publicKey *ecdsa.PublicKey) error {
+	if publicKey == nil {
+		return errors.New("public key is required to publish private message")
+	}
+
+	message, err := n.buildMessage(room, payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to build message")
+	}
+
+	// Private messages are always encrypted, regardless of the config
+	version := uint32(1)
+	message.Version = &version
+
+	err = encryptPrivatePayload(publicKey, message)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt message")
+	}
+
+	return n.publish(message)
+}
+
+func (n *LanNode) buildMessage(room *pp.Room, payload []byte) (*pb.WakuMessage, error) {
+	version := uint32(0)
+	if config.EnableSymmetricEncryption {
+		version = 1
+	}
+
+	contentTopic, err := n.roomCache.Get(room, pp.DetectEncoding(payload))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build content topic")
+	}
+
+	return &pb.WakuMessage{
+		Payload:      payload,
+		Version:      &version,
+		ContentTopic: contentTopic,
+		Timestamp:    utils.GetUnixEpoch(),
+	}, nil
+}
+
+// publish delivers the message to local subscriptions and sends it to the network.
+func (n *LanNode) publish(message *pb.WakuMessage) error {
+	if n.sender == nil {
+		return errors.New("lan node is not started")
+	}
+
+	err := n.send(message)
+	if err != nil {
+		n.logger.Error("failed to publish message", zap.Error(err))
+		return errors.Wrap(err, "failed to publish message")
+	}
+
+	n.deliver(message)
+	return nil
+}
+
+func (n *LanNode) send(message *pb.WakuMessage) error {
+	message.Meta = []byte(n.id)
+
+	packet, err := proto.Marshal(message)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal message")
+	}
+
+	if len(packet) <= lanMaxPacketSize {
+		_, err = n.sender.Write(packet)
+		return errors.Wrap(err, "failed to send multicast packet")
+	}
+
+	fragments, err := n.fragment(packet)
+	if err != nil {
+		return err
+	}
+
+	for _, fragment := range fragments {
+		_, err = n.sender.Write(fragment)
+		if err != nil {
+			return errors.Wrap(err, "failed to send multicast packet")
+		}
+	}
+
+	return nil
+}
+
+// fragment splits the packet into fragment packets, each fitting a datagram.
+func (n *LanNode) fragment(packet []byte) ([][]byte, error) {
+	count := (len(packet) + lanFragmentSize - 1) / lanFragmentSize
+	if count > lanMaxFragments {
+		return nil, errors.Errorf("message is too big: %d bytes", len(packet))
+	}
+
+	id := n.fragmentsSequence.Add(1)
+	fragments := make([][]byte, 0, count)
+
+	for index := 0; index < count; index++ {
+		chunk := packet[index*lanFragmentSize : min((index+1)*lanFragmentSize, len(packet))]
+
+		payload := make([]byte, lanFragmentHeaderSize, lanFragmentHeaderSize+len(chunk))
+		binary.BigEndian.PutUint64(payload[0:], id)
+		binary.BigEndian.PutUint16(payload[8:], uint16(index))
+		binary.BigEndian.PutUint16(payload[10:], uint16(count))
+		payload = append(payload, chunk...)
+
+		fragment, err := proto.Marshal(&pb.WakuMessage{
+			Payload:      payload,
+			ContentTopic: lanFragmentTopic,
+			Meta:         []byte(n.id),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal fragment")
+		}
+
+		fragments = append(fragments, fragment)
+	}
+
+	return fragments, nil
+}
+
+func (n *LanNode) ConnectionStatus() ConnectionStatus {
+	n.statusLock.Lock()
+	defer n.statusLock.Unlock()
+
+	return n.connectionStatus
+}
+
+func (n *LanNode) SubscribeToConnectionStatus() ConnectionStatusSubscription {
+	n.statusLock.Lock()
+	defer n.statusLock.Unlock()
+
+	channel := make(ConnectionStatusSubscription, 10)
+	n.statusSubscribers = append(n.statusSubscribers, channel)
+	return channel
+}
+
+// notifyConnectionStatus counts this node as a peer, so that the first player on the network can start a room.
+func (n *LanNode) notifyConnectionStatus() {
+	n.lock.Lock()
+	peersCount := len(n.peers) + 1
+	n.lock.Unlock()
+
+	n.statusLock.Lock()
+	defer n.statusLock.Unlock()
+
+	n.connectionStatus = ConnectionStatus{
+		IsOnline:   true,
+		HasHistory: false,
+		PeersCount: peersCount,
+	}
+
+	for _, subscriber := range n.statusSubscribers {
+		select {
+		case subscriber <- n.connectionStatus:
+		default:
+			n.logger.Warn("connection status subscriber is not reading")
+		}
+	}
+}
+
+// Ensure that LanNode implements the Service interface at compile time.
+var _ Service = (*LanNode)(nil)
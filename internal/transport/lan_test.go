@@ -0,0 +1,250 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/suite"
+	"github.com/waku-org/go-waku/waku/v2/protocol/pb"
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/six78/2-story-points-cli/internal/testcommon"
+	pp "github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+func TestLanSuite(t *testing.T) {
+	suite.Run(t, new(LanSuite))
+}
+
+type LanSuite struct {
+	testcommon.Suite
+	ctx     context.Context
+	cancel  context.CancelFunc
+	address string
+}
+
+func (s *LanSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	// Random port, so that tests don't receive each other's messages
+	s.address = fmt.Sprintf("239.255.78.2:%d", gofakeit.IntRange(30000, 60000))
+}
+
+func (s *LanSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *LanSuite) newNode() *LanNode {
+	return s.newNodeOnInterface("")
+}
+
+func (s *LanSuite) newNodeOnInterface(interfaceName string) *LanNode {
+	node := NewLanNode(s.ctx, s.Logger, s.address, interfaceName)
+	err := node.Initialize()
+	s.Require().NoError(err)
+	err = node.Start()
+	if err != nil {
+		s.T().Skipf("multicast is not available: %s", err)
+	}
+	s.T().Cleanup(node.Stop)
+	return node
+}
+
+func (s *LanSuite) receive(sub *MessagesSubscription) []byte {
+	select {
+	case payload := <-sub.Ch:
+		return payload
+	case <-time.After(time.Second):
+		s.T().Skip("multicast packets are not delivered")
+	}
+	return nil
+}
+
+func (s *LanSuite) TestInitialize() {
+	node := NewLanNode(s.ctx, s.Logger, "127.0.0.1:27878", "")
+	err := node.Initialize()
+	s.Require().Error(err)
+
+	node = NewLanNode(s.ctx, s.Logger, s.address, gofakeit.LetterN(10))
+	err = node.Initialize()
+	s.Require().Error(err)
+
+	node = NewLanNode(s.ctx, s.Logger, s.address, "")
+	err = node.Start()
+	s.Require().Error(err) // Not initialized
+}
+
+func (s *LanSuite) TestMessages() {
+	room, err := pp.NewRoom()
+	s.Require().NoError(err)
+
+	dealerKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	playerKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	dealer := s.newNode()
+	player := s.newNode()
+
+	dealerSub, err := dealer.SubscribeToMessages(room, dealerKey)
+	s.Require().NoError(err)
+	playerSub, err := player.SubscribeToMessages(room, playerKey)
+	s.Require().NoError(err)
+
+	// Public message is delivered to everyone, including the sender
+	payload := []byte(gofakeit.LetterN(20))
+	err = dealer.PublishPublicMessage(room, payload)
+	s.Require().NoError(err)
+	s.Require().Equal(payload, s.receive(dealerSub))
+	s.Require().Equal(payload, s.receive(playerSub))
+
+	// Private message can only be decrypted by the recipient
+	payload = []byte(gofakeit.LetterN(20))
+	err = player.PublishPrivateMessage(room, payload, &dealerKey.PublicKey)
+	s.Require().NoError(err)
+	s.Require().Equal(payload, s.receive(dealerSub))
+
+	select {
+	case payload := <-playerSub.Ch:
+		s.Require().Fail("unexpected message", string(payload))
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func (s *LanSuite) TestPeersDiscovery() {
+	first := s.newNode()
+	statusSub := first.SubscribeToConnectionStatus()
+	s.Require().Equal(1, first.ConnectionStatus().PeersCount)
+	s.Require().True(first.ConnectionStatus().IsOnline)
+
+	_ = s.newNode()
+
+	select {
+	case status := <-statusSub:
+		s.Require().Equal(2, status.PeersCount)
+	case <-time.After(lanAnnouncePeriod):
+		s.T().Skip("multicast packets are not delivered")
+	}
+}
+
+func (s *LanSuite) TestMulticastInterface() {
+	interfaces, err := net.Interfaces()
+	s.Require().NoError(err)
+
+	index := slices.IndexFunc(interfaces, func(i net.Interface) bool {
+		return i.Flags&net.FlagUp != 0 && i.Flags&net.FlagMulticast != 0
+	})
+	if index < 0 {
+		s.T().Skip("no multicast network interface")
+	}
+	interfaceName := interfaces[index].Name
+
+	room, err := pp.NewRoom()
+	s.Require().NoError(err)
+	key, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	// Messages are sent through the selected interface, not only received on it
+	sender := s.newNodeOnInterface(interfaceName)
+	receiver := s.newNodeOnInterface(interfaceName)
+	s.Require().NotNil(sender.multicastIf)
+
+	sub, err := receiver.SubscribeToMessages(room, key)
+	s.Require().NoError(err)
+
+	payload := []byte(gofakeit.LetterN(20))
+	err = sender.PublishPublicMessage(room, payload)
+	s.Require().NoError(err)
+	s.Require().Equal(payload, s.receive(sub))
+}
+
+func (s *LanSuite) TestLargeMessage() {
+	room, err := pp.NewRoom()
+	s.Require().NoError(err)
+	key, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	sender := s.newNode()
+	receiver := s.newNode()
+
+	sub, err := receiver.SubscribeToMessages(room, key)
+	s.Require().NoError(err)
+
+	// Doesn't fit a single datagram
+	payload := []byte(gofakeit.LetterN(3 * lanMaxPacketSize))
+	err = sender.PublishPublicMessage(room, payload)
+	s.Require().NoError(err)
+	s.Require().Equal(payload, s.receive(sub))
+
+	payload = make([]byte, lanMaxFragments*lanFragmentSize)
+	err = sender.PublishPublicMessage(room, payload)
+	s.Require().Error(err)
+}
+
+func (s *LanSuite) TestFragments() {
+	room, err := pp.NewRoom()
+	s.Require().NoError(err)
+	key, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	// Fragments are handled directly, so that the test doesn't depend on multicast
+	sender := NewLanNode(s.ctx, s.Logger, s.address, "")
+	receiver := NewLanNode(s.ctx, s.Logger, s.address, "")
+
+	sub, err := receiver.SubscribeToMessages(room, key)
+	s.Require().NoError(err)
+
+	payload := []byte(gofakeit.LetterN(3 * lanFragmentSize))
+	message, err := sender.buildMessage(room, payload)
+	s.Require().NoError(err)
+	err = encryptPublicPayload(room, message)
+	s.Require().NoError(err)
+	message.Meta = []byte(sender.id)
+	packet, err := proto.Marshal(message)
+	s.Require().NoError(err)
+
+	fragments, err := sender.fragment(packet)
+	s.Require().NoError(err)
+	s.Require().Len(fragments, 4)
+
+	handle := func(fragment []byte) {
+		s.Require().LessOrEqual(len(fragment), lanMaxPacketSize)
+		message := &pb.WakuMessage{}
+		err := proto.Unmarshal(fragment, message)
+		s.Require().NoError(err)
+		receiver.handleMessage(message)
+	}
+
+	// Fragments may be reordered and duplicated
+	handle(fragments[3])
+	handle(fragments[1])
+	handle(fragments[1])
+	handle(fragments[0])
+	s.Require().Len(receiver.fragments, 1)
+
+	select {
+	case <-sub.Ch:
+		s.Require().Fail("message delivered before all fragments are received")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	handle(fragments[2])
+	s.Require().Empty(receiver.fragments)
+	s.Require().Equal(payload, s.receive(sub))
+
+	// Incomplete messages expire
+	fragments, err = sender.fragment(packet)
+	s.Require().NoError(err)
+	handle(fragments[0])
+	s.Require().Len(receiver.fragments, 1)
+	for _, fragments := range receiver.fragments {
+		fragments.started = time.Now().Add(-lanFragmentsTimeout)
+	}
+	receiver.expireFragments()
+	s.Require().Empty(receiver.fragments)
+}
@@ -0,0 +1,279 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	pp "github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+// LoopbackNetwork is an in-memory network, which delivers messages between loopback nodes of the same process.
+// It allows to play without any Waku peers, e.g. for demos, end-to-end tests and fully offline sessions.
+// Messages are routed by content topic, same as in Waku. They're not encrypted, but private messages
+// are only delivered to the subscriptions with the matching private key.
+type LoopbackNetwork struct {
+	lock          sync.Mutex
+	nodes         []*LoopbackNode
+	subscriptions map[string][]*loopbackSubscription // By content topic
+}
+
+type LoopbackNode struct {
+	network *LoopbackNetwork
+	logger  *zap.Logger
+
+	roomCache         ContentTopicCache
+	started           bool
+	statusLock        sync.Mutex
+	statusSubscribers []ConnectionStatusSubscription
+	connectionStatus  ConnectionStatus
+}
+
+type loopbackMessage struct {
+	payload   []byte
+	recipient *ecdsa.PublicKey // Only set for private messages
+}
+
+type loopbackSubscription struct {
+	logger     *zap.Logger
+	in         chan loopbackMessage
+	privateKey *ecdsa.PrivateKey
+}
+
+const loopbackBufferSize = 100
+
+func NewLoopbackNetwork() *LoopbackNetwork {
+	return &LoopbackNetwork{
+		nodes:         make([]*LoopbackNode, 0, 4),
+		subscriptions: make(map[string][]*loopbackSubscription),
+	}
+}
+
+// NewNode creates a new node in the network. The node must be started to send and receive messages.
+func (l *LoopbackNetwork) NewNode(logger *zap.Logger) *LoopbackNode {
+	return &LoopbackNode{
+		network:   l,
+		logger:    logger.Named("loopback"),
+		roomCache: NewRoomCache(logger),
+	}
+}
+
+func (l *LoopbackNetwork) join(node *LoopbackNode) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.nodes = append(l.nodes, node)
+	l.notifyConnectionStatus()
+}
+
+func (l *LoopbackNetwork) leave(node *LoopbackNode) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.nodes = slices.DeleteFunc(l.nodes, func(other *LoopbackNode) bool {
+		return other == node
+	})
+	l.notifyConnectionStatus()
+}
+
+// notifyConnectionStatus must be called with the lock held.
+// Every node counts itself as a peer, so that a single player can play offline.
+func (l *LoopbackNetwork) notifyConnectionStatus() {
+	status := ConnectionStatus{
+		IsOnline:   true,
+		HasHistory: false,
+		PeersCount: len(l.nodes),
+	}
+	for _, node := range l.nodes {
+		node.notifyConnectionStatus(status)
+	}
+}
+
+func (l *LoopbackNetwork) subscribe(contentTopics []string, subscription *loopbackSubscription) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, contentTopic := range contentTopics {
+		l.subscriptions[contentTopic] = append(l.subscriptions[contentTopic], subscription)
+	}
+}
+
+func (l *LoopbackNetwork) unsubscribe(contentTopics []string, subscription *loopbackSubscription) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, contentTopic := range contentTopics {
+		subscriptions := slices.DeleteFunc(l.subscriptions[contentTopic], func(other *loopbackSubscription) bool {
+			return other == subscription
+		})
+		if len(subscriptions) == 0 {
+			delete(l.subscriptions, contentTopic)
+		} else {
+			l.subscriptions[contentTopic] = subscriptions
+		}
+	}
+}
+
+func (l *LoopbackNetwork) publish(contentTopic string, message loopbackMessage) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, subscription := range l.subscriptions[contentTopic] {
+		// Never block the publisher, it might be the one who reads the subscription
+		select {
+		case subscription.in <- message:
+		default:
+			subscription.logger.Warn("subscription buffer is full, message dropped")
+		}
+	}
+}
+
+func (n *LoopbackNode) Initialize() error {
+	return nil
+}
+
+func (n *LoopbackNode) Start() error {
+	if n.started {
+		return errors.New("loopback node already started")
+	}
+	n.started = true
+	n.network.join(n)
+	return nil
+}
+
+func (n *LoopbackNode) Stop() {
+	if !n.started {
+		return
+	}
+	n.started = false
+	n.network.leave(n)
+}
+
+func (n *LoopbackNode) SubscribeToMessages(room *pp.Room, privateKey *ecdsa.PrivateKey) (*MessagesSubscription, error) {
+	n.logger.Debug("subscribing to room", zap.String("roomID", room.ToRoomID().String()))
+
+	// Subscribe to all encodings, so that we can play with clients using a different one
+	contentTopics, err := n.roomCache.GetAll(room)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build content topic")
+	}
+
+	subscription := &loopbackSubscription{
+		logger:     n.logger,
+		in:         make(chan loopbackMessage, loopbackBufferSize),
+		privateKey: privateKey,
+	}
+	n.network.subscribe(contentTopics, subscription)
+
+	leaveRoom := make(chan struct{})
+	sub := &MessagesSubscription{
+		Ch: make(chan []byte, 10),
+		Unsubscribe: func() {
+			close(leaveRoom)
+		},
+	}
+
+	go func() {
+		defer func() {
+			n.network.unsubscribe(contentTopics, subscription)
+			n.roomCache.Remove(room)
+			close(sub.Ch)
+			n.logger.Debug("subscription channel closed")
+		}()
+
+		for {
+			select {
+			case <-leaveRoom:
+				return
+			case message := <-subscription.in:
+				if !subscription.accepts(message) {
+					continue
+				}
+				select {
+				case sub.Ch <- message.payload:
+				case <-leaveRoom:
+					return
+				}
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+// accepts returns false for private messages addressed to other players.
+func (s *loopbackSubscription) accepts(message loopbackMessage) bool {
+	if message.recipient == nil {
+		return true
+	}
+	return s.privateKey != nil && s.privateKey.PublicKey.Equal(message.recipient)
+}
+
+func (n *LoopbackNode) PublishUnencryptedMessage(room *pp.Room, payload []byte) error {
+	return n.publish(room, loopbackMessage{payload: payload})
+}
+
+func (n *LoopbackNode) PublishPublicMessage(room *pp.Room, payload []byte) error {
+	return n.publish(room, loopbackMessage{payload: payload})
+}
+
+func (n *LoopbackNode) PublishPrivateMessage(room *pp.Room, payload []byte, publicKey *ecdsa.PublicKey) error {
+	if publicKey == nil {
+		return errors.New("public key is required to publish private message")
+	}
+	return n.publish(room, loopbackMessage{payload: payload, recipient: publicKey})
+}
+
+func (n *LoopbackNode) publish(room *pp.Room, message loopbackMessage) error {
+	if !n.started {
+		return errors.New("loopback node is not started")
+	}
+
+	contentTopic, err := n.roomCache.Get(room, pp.DetectEncoding(message.payload))
+	if err != nil {
+		return errors.Wrap(err, "failed to build content topic")
+	}
+
+	// Payload could be reused by the caller
+	message.payload = slices.Clone(message.payload)
+	n.network.publish(contentTopic, message)
+
+	return nil
+}
+
+func (n *LoopbackNode) ConnectionStatus() ConnectionStatus {
+	n.statusLock.Lock()
+	defer n.statusLock.Unlock()
+
+	return n.connectionStatus
+}
+
+func (n *LoopbackNode) SubscribeToConnectionStatus() ConnectionStatusSubscription {
+	n.statusLock.Lock()
+	defer n.statusLock.Unlock()
+
+	channel := make(ConnectionStatusSubscription, 10)
+	n.statusSubscribers = append(n.statusSubscribers, channel)
+	return channel
+}
+
+func (n *LoopbackNode) notifyConnectionStatus(status ConnectionStatus) {
+	n.statusLock.Lock()
+	defer n.statusLock.Unlock()
+
+	n.connectionStatus = status
+
+	for _, subscriber := range n.statusSubscribers {
+		select {
+		case subscriber <- status:
+		default:
+			n.logger.Warn("connection status subscriber is not reading")
+		}
+	}
+}
+
+// Ensure that LoopbackNode implements the Service interface at compile time.
+var _ Service = (*LoopbackNode)(nil)
@@ -0,0 +1,143 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/six78/2-story-points-cli/internal/testcommon"
+	pp "github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+const loopbackTimeout = time.Second
+
+func TestLoopbackSuite(t *testing.T) {
+	suite.Run(t, new(LoopbackSuite))
+}
+
+type LoopbackSuite struct {
+	testcommon.Suite
+	network *LoopbackNetwork
+}
+
+func (s *LoopbackSuite) SetupTest() {
+	s.network = NewLoopbackNetwork()
+}
+
+func (s *LoopbackSuite) newNode() *LoopbackNode {
+	node := s.network.NewNode(s.Logger)
+	err := node.Initialize()
+	s.Require().NoError(err)
+	err = node.Start()
+	s.Require().NoError(err)
+	return node
+}
+
+func (s *LoopbackSuite) receive(sub *MessagesSubscription) []byte {
+	select {
+	case payload := <-sub.Ch:
+		return payload
+	case <-time.After(loopbackTimeout):
+		s.Require().Fail("timeout waiting for message")
+	}
+	return nil
+}
+
+func (s *LoopbackSuite) requireNoMessage(sub *MessagesSubscription) {
+	select {
+	case payload := <-sub.Ch:
+		s.Require().Fail("unexpected message", string(payload))
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func (s *LoopbackSuite) TestPublicMessages() {
+	room, err := pp.NewRoom()
+	s.Require().NoError(err)
+	otherRoom, err := pp.NewRoom()
+	s.Require().NoError(err)
+
+	alice := s.newNode()
+	bob := s.newNode()
+	charlie := s.newNode()
+
+	aliceSub, err := alice.SubscribeToMessages(room, nil)
+	s.Require().NoError(err)
+	bobSub, err := bob.SubscribeToMessages(room, nil)
+	s.Require().NoError(err)
+	charlieSub, err := charlie.SubscribeToMessages(otherRoom, nil)
+	s.Require().NoError(err)
+
+	payload := []byte(gofakeit.LetterN(20))
+	err = alice.PublishPublicMessage(room, payload)
+	s.Require().NoError(err)
+
+	// Delivered to everyone in the room, including the sender
+	s.Require().Equal(payload, s.receive(aliceSub))
+	s.Require().Equal(payload, s.receive(bobSub))
+	s.requireNoMessage(charlieSub)
+
+	// No messages after unsubscribe
+	bobSub.Unsubscribe()
+	s.Require().Eventually(func() bool {
+		_, more := <-bobSub.Ch
+		return !more
+	}, loopbackTimeout, 10*time.Millisecond)
+
+	err = alice.PublishUnencryptedMessage(room, payload)
+	s.Require().NoError(err)
+	s.Require().Equal(payload, s.receive(aliceSub))
+}
+
+func (s *LoopbackSuite) TestPrivateMessages() {
+	room, err := pp.NewRoom()
+	s.Require().NoError(err)
+
+	dealerKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	playerKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	dealer := s.newNode()
+	player := s.newNode()
+
+	dealerSub, err := dealer.SubscribeToMessages(room, dealerKey)
+	s.Require().NoError(err)
+	playerSub, err := player.SubscribeToMessages(room, playerKey)
+	s.Require().NoError(err)
+
+	err = player.PublishPrivateMessage(room, []byte("vote"), nil)
+	s.Require().Error(err)
+
+	payload := []byte(gofakeit.LetterN(20))
+	err = player.PublishPrivateMessage(room, payload, &dealerKey.PublicKey)
+	s.Require().NoError(err)
+
+	s.Require().Equal(payload, s.receive(dealerSub))
+	s.requireNoMessage(playerSub)
+}
+
+func (s *LoopbackSuite) TestConnectionStatus() {
+	first := s.network.NewNode(s.Logger)
+	statusSub := first.SubscribeToConnectionStatus()
+
+	room, err := pp.NewRoom()
+	s.Require().NoError(err)
+	err = first.PublishPublicMessage(room, []byte{})
+	s.Require().Error(err) // Not started
+
+	err = first.Start()
+	s.Require().NoError(err)
+	s.Require().Equal(1, (<-statusSub).PeersCount)
+	s.Require().True(first.ConnectionStatus().IsOnline)
+
+	second := s.newNode()
+	s.Require().Equal(2, (<-statusSub).PeersCount)
+
+	second.Stop()
+	s.Require().Equal(1, (<-statusSub).PeersCount)
+	s.Require().Equal(1, first.ConnectionStatus().PeersCount)
+}
@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"context"
+	"time"
+
+	"github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/six78/2-story-points-cli/internal/config"
+)
+
+const (
+	reconnectMinDelay = 5 * time.Second
+	reconnectMaxDelay = time.Minute
+)
+
+// reconnectDelay returns the delay before the next reconnect attempt.
+// The delay doubles after each failed attempt, up to reconnectMaxDelay.
+func reconnectDelay(attempt int) time.Duration {
+	delay := reconnectMinDelay
+	for i := 0; i < attempt && delay < reconnectMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, reconnectMaxDelay)
+}
+
+// reconnectPeers re-runs DNS discovery and dials the discovered nodes together with one of the static nodes.
+// Static nodes are rotated on each attempt, so that a single unreachable node doesn't block the others.
+func (n *Node) reconnectPeers(attempt int) error {
+	dialed := 0
+
+	if config.WakuDnsDiscovery() {
+		discoveredNodes, err := discoverNodes(n.ctx, n.logger.Named("dnsdiscovery"))
+		if err != nil {
+			n.logger.Warn("failed to discover nodes", zap.Error(err))
+		}
+		for _, discovered := range discoveredNodes {
+			ctx, cancel := context.WithTimeout(n.ctx, dialTimeout)
+			err = n.waku.DialPeerWithInfo(ctx, discovered.PeerInfo)
+			cancel()
+			if err != nil {
+				n.logger.Debug("failed to dial discovered node",
+					zap.String("peerID", discovered.PeerID.String()),
+					zap.Error(err))
+				continue
+			}
+			dialed++
+		}
+	}
+
+	if staticNodes := config.WakuStaticNodes(); len(staticNodes) != 0 {
+		staticNode := staticNodes[(attempt-1)%len(staticNodes)]
+		n.logger.Info("connecting to a static store node",
+			zap.String("address", staticNode),
+		)
+		addr, err := multiaddr.NewMultiaddr(staticNode)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse multiaddr")
+		}
+		err = n.DialPeer(addr)
+		if err != nil {
+			n.logger.Warn("failed to dial static node", zap.String("address", staticNode), zap.Error(err))
+		} else {
+			dialed++
+		}
+	}
+
+	if dialed == 0 {
+		return errors.New("no peers dialed")
+	}
+
+	return nil
+}
@@ -1,22 +1,35 @@
 package transport
 
-import "github.com/six78/2-story-points-cli/pkg/protocol"
+import (
+	"crypto/ecdsa"
+
+	"go.uber.org/zap"
+
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
 
 //go:generate mockgen -source=service.go -destination=mock/service.go
 
 type Service interface {
 	Initialize() error
 	Start() error
+	Stop()
 
-	SubscribeToMessages(room *protocol.Room) (*MessagesSubscription, error)
+	// SubscribeToMessages subscribes to all messages in the room.
+	// privateKey is used to decrypt private messages addressed to this client, can be nil.
+	SubscribeToMessages(room *protocol.Room, privateKey *ecdsa.PrivateKey) (*MessagesSubscription, error)
 	PublishUnencryptedMessage(room *protocol.Room, payload []byte) error
 	PublishPublicMessage(room *protocol.Room, payload []byte) error
-	PublishPrivateMessage(room *protocol.Room, payload []byte) error
+	// PublishPrivateMessage publishes a message that can only be decrypted by the owner of publicKey.
+	PublishPrivateMessage(room *protocol.Room, payload []byte, publicKey *ecdsa.PublicKey) error
 
 	ConnectionStatus() ConnectionStatus
 	SubscribeToConnectionStatus() ConnectionStatusSubscription
 }
 
+// Factory creates a new transport, e.g. for each player of the demo.
+type Factory func(logger *zap.Logger) Service
+
 type MessagesSubscription struct {
 	Ch          chan []byte
 	Unsubscribe func()
@@ -26,6 +39,8 @@ type ConnectionStatus struct {
 	IsOnline   bool
 	HasHistory bool
 	PeersCount int
+	// ReconnectAttempt is the number of the current reconnect attempt, zero when not reconnecting
+	ReconnectAttempt int
 }
 
 type ConnectionStatusSubscription chan ConnectionStatus
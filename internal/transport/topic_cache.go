@@ -2,6 +2,7 @@ package transport
 
 import (
 	"strconv"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -12,51 +13,96 @@ import (
 	"go.uber.org/zap"
 )
 
+// ContentTopicCache caches content topics of all joined rooms.
 type ContentTopicCache struct {
-	logger       *zap.Logger
-	roomID       *protocol.RoomID
-	contentTopic string
-	err          error
-	hits         int
+	logger *zap.Logger
+	lock   sync.Mutex
+	rooms  map[protocol.RoomID]map[protocol.Encoding]string
+	hits   int
 }
 
 func NewRoomCache(logger *zap.Logger) ContentTopicCache {
 	return ContentTopicCache{
-		logger:       logger.Named("TopicCache"),
-		roomID:       nil,
-		contentTopic: "",
-		hits:         0,
+		logger: logger.Named("TopicCache"),
+		rooms:  make(map[protocol.RoomID]map[protocol.Encoding]string),
+		hits:   0,
 	}
 }
 
-func (r *ContentTopicCache) Get(room *protocol.Room) (string, error) {
+// Get returns the room content topic for messages with given encoding.
+func (r *ContentTopicCache) Get(room *protocol.Room, encoding protocol.Encoding) (string, error) {
+	contentTopics, err := r.getAll(room)
+	if err != nil {
+		return "", err
+	}
+
+	contentTopic, ok := contentTopics[encoding]
+	if !ok {
+		return "", errors.Errorf("unsupported encoding: %s", encoding)
+	}
+
+	return contentTopic, nil
+}
+
+// GetAll returns the room content topics for all supported encodings.
+func (r *ContentTopicCache) GetAll(room *protocol.Room) ([]string, error) {
+	contentTopics, err := r.getAll(room)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(protocol.Encodings))
+	for _, encoding := range protocol.Encodings {
+		result = append(result, contentTopics[encoding])
+	}
+
+	return result, nil
+}
+
+// Remove forgets content topics of the room, e.g. when the room was left.
+func (r *ContentTopicCache) Remove(room *protocol.Room) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.rooms, room.ToRoomID())
+}
+
+func (r *ContentTopicCache) getAll(room *protocol.Room) (map[protocol.Encoding]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
 	roomID := room.ToRoomID()
-	if r.roomID != nil && *r.roomID == roomID {
+	if contentTopics, ok := r.rooms[roomID]; ok {
 		r.hits++
-		return r.contentTopic, r.err
+		return contentTopics, nil
 	}
 
-	r.roomID = &roomID
-	r.contentTopic, r.err = r.roomContentTopic(room)
-	r.hits = 0
-
-	if r.err != nil {
-		r.logger.Error("failed to calculate content topic", zap.Error(r.err))
-	} else {
-		r.logger.Debug("new content topic", zap.String("contentTopic", r.contentTopic))
+	contentTopics := make(map[protocol.Encoding]string, len(protocol.Encodings))
+	for _, encoding := range protocol.Encodings {
+		contentTopic, err := r.roomContentTopic(room, encoding)
+		if err != nil {
+			r.logger.Error("failed to calculate content topic", zap.Error(err))
+			return nil, err
+		}
+		contentTopics[encoding] = contentTopic
 	}
 
-	return r.contentTopic, r.err
+	r.rooms[roomID] = contentTopics
+	r.logger.Debug("new content topics", zap.Any("contentTopics", contentTopics))
+
+	return contentTopics, nil
 }
 
-func (r *ContentTopicCache) roomContentTopic(room *protocol.Room) (string, error) {
+// roomContentTopic returns the content topic for given room and encoding.
+// The encoding is part of the content topic, so that clients can tell the message format
+// without looking into the payload.
+func (r *ContentTopicCache) roomContentTopic(room *protocol.Room, encoding protocol.Encoding) (string, error) {
 	version := strconv.Itoa(int(protocol.Version))
 	hash := crypto.Keccak256(room.Bytes())
 	contentTopicName := hexutil.Encode(hash[:4])[2:]
 
 	// FIXME: Change vendor name to application name here?
-	// TODO: Switch to protobuf
-	contentTopic, err := waku.NewContentTopic(config.VendorName, version, contentTopicName, "json") // WARNING: "six78" is not the name of the app
+	contentTopic, err := waku.NewContentTopic(config.VendorName, version, contentTopicName, string(encoding)) // WARNING: "six78" is not the name of the app
 
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create content topic")
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/six78/2-story-points-cli/pkg/protocol"
@@ -17,18 +18,18 @@ func TestContentTopicCache(t *testing.T) {
 	room1, err := protocol.NewRoom()
 	require.NoError(t, err)
 
-	room1ContentTopic, err := cache.roomContentTopic(room1)
+	room1ContentTopic, err := cache.roomContentTopic(room1, protocol.EncodingJSON)
 	require.NoError(t, err)
 
 	// First call to Get
-	contentTopic1, err := cache.Get(room1)
+	contentTopic1, err := cache.Get(room1, protocol.EncodingJSON)
 	require.NoError(t, err)
 	require.Equal(t, room1ContentTopic, contentTopic1)
 	require.Equal(t, 0, cache.hits)
 
 	// Second call to Get, hit cache
 	for i := range [3]int{} {
-		contentTopic2, err2 := cache.Get(room1)
+		contentTopic2, err2 := cache.Get(room1, protocol.EncodingJSON)
 		require.NoError(t, err2)
 		require.Equal(t, room1ContentTopic, contentTopic2)
 		require.Equal(t, i+1, cache.hits)
@@ -37,11 +38,49 @@ func TestContentTopicCache(t *testing.T) {
 	room2, err := protocol.NewRoom()
 	require.NoError(t, err)
 
-	room2ContentTopic, err := cache.roomContentTopic(room2)
+	room2ContentTopic, err := cache.roomContentTopic(room2, protocol.EncodingJSON)
 	require.NoError(t, err)
 
-	contentTopic2, err := cache.Get(room2)
+	contentTopic2, err := cache.Get(room2, protocol.EncodingJSON)
 	require.NoError(t, err)
 	require.Equal(t, room2ContentTopic, contentTopic2)
-	require.Equal(t, 0, cache.hits)
+	require.Equal(t, 3, cache.hits)
+
+	// Both rooms are cached
+	contentTopic1, err = cache.Get(room1, protocol.EncodingJSON)
+	require.NoError(t, err)
+	require.Equal(t, room1ContentTopic, contentTopic1)
+	require.Equal(t, 4, cache.hits)
+
+	// Removed room is calculated again
+	cache.Remove(room1)
+	contentTopic1, err = cache.Get(room1, protocol.EncodingJSON)
+	require.NoError(t, err)
+	require.Equal(t, room1ContentTopic, contentTopic1)
+	require.Equal(t, 4, cache.hits)
+}
+
+func TestContentTopicEncoding(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	cache := NewRoomCache(logger)
+
+	room, err := protocol.NewRoom()
+	require.NoError(t, err)
+
+	jsonContentTopic, err := cache.Get(room, protocol.EncodingJSON)
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(jsonContentTopic, "/json"))
+
+	protoContentTopic, err := cache.Get(room, protocol.EncodingProtobuf)
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(protoContentTopic, "/proto"))
+
+	allContentTopics, err := cache.GetAll(room)
+	require.NoError(t, err)
+	require.Equal(t, []string{jsonContentTopic, protoContentTopic}, allContentTopics)
+
+	_, err = cache.Get(room, protocol.Encoding("xml"))
+	require.Error(t, err)
 }
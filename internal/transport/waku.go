@@ -2,13 +2,16 @@ package transport
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/hex"
 	"net"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/jonboulle/clockwork"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/pkg/errors"
@@ -28,30 +31,39 @@ import (
 	pp "github.com/six78/2-story-points-cli/pkg/protocol"
 )
 
+const dialTimeout = 10 * time.Second
+
 type Node struct {
 	waku   *node.WakuNode
 	ctx    context.Context
 	logger *zap.Logger
+	clock  clockwork.Clock
 
 	pubsubTopic       string
 	peerConnection    chan node.PeerConnection
 	roomCache         ContentTopicCache
+	roomSubscriptions atomic.Int32 // Number of rooms subscribed at the same time
 	lightMode         bool
 	statusSubscribers []ConnectionStatusSubscription
 	connectionStatus  ConnectionStatus
 	connectedPeers    map[peer.ID]struct{}
+	hasHistory        atomic.Bool             // Set when the history was retrieved from a store node
+	reconnect         func(attempt int) error // Replaced in tests
 }
 
 func NewNode(ctx context.Context, logger *zap.Logger) *Node {
-	return &Node{
+	n := &Node{
 		waku:           nil,
 		ctx:            ctx,
 		logger:         logger.Named("waku"),
+		clock:          clockwork.NewRealClock(),
 		pubsubTopic:    FleetName(config.Fleet()).DefaultPubsubTopic(),
 		peerConnection: nil,
 		roomCache:      NewRoomCache(logger),
 		lightMode:      config.WakuLightMode(),
 	}
+	n.reconnect = n.reconnectPeers
+	return n
 }
 
 func (n *Node) Initialize() error {
@@ -64,7 +76,8 @@ func (n *Node) Initialize() error {
 	if config.WakuDnsDiscovery() {
 		discoveredNodes, err = discoverNodes(n.ctx, n.logger.Named("dnsdiscovery"))
 		if err != nil {
-			return errors.Wrap(err, "failed to discover nodes")
+			// Not fatal, discovery is retried when reconnecting
+			n.logger.Warn("failed to discover nodes", zap.Error(err))
 		}
 	}
 
@@ -140,9 +153,11 @@ func (n *Node) Start() error {
 		n.logger.Debug("starting discoveryV5")
 		err = n.waku.DiscV5().Start(context.Background())
 		if err != nil {
-			return errors.Wrap(err, "failed to start discoverV5")
+			// Not fatal, peers can still be found with DNS discovery and static nodes
+			n.logger.Warn("failed to start discoveryV5", zap.Error(err))
+		} else {
+			n.logger.Debug("started discoveryV5")
 		}
-		n.logger.Debug("started discoveryV5")
 	}
 
 	if staticNodes := config.WakuStaticNodes(); len(staticNodes) != 0 {
@@ -236,7 +251,8 @@ func (n *Node) addStaticNodes(staticNodes []string) error {
 
 		err = n.DialPeer(addr)
 		if err != nil {
-			return errors.Wrap(err, "failed to dial static peer")
+			// Not fatal, static nodes are dialed again when reconnecting
+			n.logger.Warn("failed to dial static node", zap.String("address", staticNode), zap.Error(err))
 		}
 	}
 
@@ -244,8 +260,6 @@ func (n *Node) addStaticNodes(staticNodes []string) error {
 }
 
 func (n *Node) DialPeer(address multiaddr.Multiaddr) error {
-	const dialTimeout = 10 * time.Second
-
 	ctx, cancel := context.WithTimeout(n.ctx, dialTimeout)
 	defer cancel()
 
@@ -269,7 +283,7 @@ func (n *Node) PublishUnencryptedMessage(room *pp.Room, payload []byte) error {
 		   I'm not sure if this is a good architecture decision.
 */
 
-func (n *Node) encryptPublicPayload(room *pp.Room, message *pb.WakuMessage) error {
+func encryptPublicPayload(room *pp.Room, message *pb.WakuMessage) error {
 	keyInfo := &wp.KeyInfo{
 		Kind:   wp.Symmetric,
 		SymKey: room.SymmetricKey,
@@ -286,7 +300,7 @@ func (n *Node) PublishPublicMessage(room *pp.Room, payload []byte) error {
 		return errors.Wrap(err, "failed to build waku message")
 	}
 
-	err = n.encryptPublicPayload(room, message)
+	err = encryptPublicPayload(room, message)
 	if err != nil {
 		return errors.Wrap(err, "failed to encrypt message")
 	}
@@ -294,9 +308,36 @@ func (n *Node) PublishPublicMessage(room *pp.Room, payload []byte) error {
 	return n.publishWakuMessage(message)
 }
 
-func (n *Node) PublishPrivateMessage(room *pp.Room, payload []byte) error {
-	n.logger.Error("PublishPrivateMessage not implemented")
-	return errors.New("PublishPrivateMessage not implemented")
+func encryptPrivatePayload(publicKey *ecdsa.PublicKey, message *pb.WakuMessage) error {
+	keyInfo := &wp.KeyInfo{
+		Kind:   wp.Asymmetric,
+		PubKey: *publicKey,
+	}
+
+	err := wp.EncodeWakuMessage(message, keyInfo)
+	return errors.Wrap(err, "failed to encode waku message")
+}
+
+func (n *Node) PublishPrivateMessage(room *pp.Room, payload []byte, publicKey *ecdsa.PublicKey) error {
+	if publicKey == nil {
+		return errors.New("public key is required to publish private message")
+	}
+
+	message, err := n.buildWakuMessage(room, payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to build waku message")
+	}
+
+	// Private messages are always encrypted, regardless of the config
+	version := uint32(1)
+	message.Version = &version
+
+	err = encryptPrivatePayload(publicKey, message)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt message")
+	}
+
+	return n.publishWakuMessage(message)
 }
 
 func (n *Node) buildWakuMessage(room *pp.Room, payload []byte) (*pb.WakuMessage, error) {
@@ -305,7 +346,7 @@ func (n *Node) buildWakuMessage(room *pp.Room, payload []byte) (*pb.WakuMessage,
 		version = 1
 	}
 
-	contentTopic, err := n.roomCache.Get(room)
+	contentTopic, err := n.roomCache.Get(room, pp.DetectEncoding(payload))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build content topic")
 	}
@@ -345,16 +386,50 @@ func (n *Node) publishWakuMessage(message *pb.WakuMessage) error {
 	return nil
 }
 
+// watchConnectionStatus tracks connected peers and supervises the connectivity.
+// When there are no peers, it tries to reconnect with a growing delay between attempts.
 func (n *Node) watchConnectionStatus() {
+	attempt := 0
+	reconnectTimer := n.clock.NewTimer(reconnectDelay(attempt))
+	defer reconnectTimer.Stop()
+
+	var reconnectDone chan struct{} // Not nil while a reconnect attempt is running
+
 	for {
 		select {
 		case <-n.ctx.Done():
 			return
+		case <-reconnectTimer.Chan():
+			if len(n.connectedPeers) > 0 || reconnectDone != nil {
+				continue
+			}
+			attempt++
+			n.logger.Info("reconnecting", zap.Int("attempt", attempt))
+			n.notifyConnectionStatus(ConnectionStatus{
+				IsOnline:         false,
+				HasHistory:       n.hasHistory.Load(),
+				PeersCount:       0,
+				ReconnectAttempt: attempt,
+			})
+			reconnectDone = make(chan struct{})
+			go func(done chan struct{}, attempt int) {
+				defer close(done)
+				err := n.reconnect(attempt)
+				if err != nil {
+					n.logger.Warn("reconnect attempt failed", zap.Int("attempt", attempt), zap.Error(err))
+				}
+			}(reconnectDone, attempt)
+		case <-reconnectDone:
+			reconnectDone = nil
+			if len(n.connectedPeers) == 0 {
+				reconnectTimer.Reset(reconnectDelay(attempt))
+			}
 		case status, more := <-n.peerConnection:
 			if !more {
 				return
 			}
 			n.logger.Debug("peer connection", zap.Any("status", status))
+			wasConnected := len(n.connectedPeers) > 0
 			if status.Connected {
 				n.connectedPeers[status.PeerID] = struct{}{}
 			} else {
@@ -362,10 +437,16 @@ func (n *Node) watchConnectionStatus() {
 			}
 			// using manual calculation instead of n.waku.PeerCount() for simpler testing
 			count := len(n.connectedPeers)
+			if count > 0 {
+				attempt = 0
+			} else if wasConnected && reconnectDone == nil {
+				reconnectTimer.Reset(reconnectDelay(attempt))
+			}
 			n.notifyConnectionStatus(ConnectionStatus{
-				IsOnline:   count > 0,
-				HasHistory: false,
-				PeersCount: count,
+				IsOnline:         count > 0,
+				HasHistory:       n.hasHistory.Load(),
+				PeersCount:       count,
+				ReconnectAttempt: attempt,
 			})
 		}
 	}
@@ -390,15 +471,16 @@ func (n *Node) subscribeToPubsubTopic() error {
 	return nil
 }
 
-func (n *Node) SubscribeToMessages(room *pp.Room) (*MessagesSubscription, error) {
-	n.logger.Debug("subscribing to room")
+func (n *Node) SubscribeToMessages(room *pp.Room, privateKey *ecdsa.PrivateKey) (*MessagesSubscription, error) {
+	n.logger.Debug("subscribing to room", zap.String("roomID", room.ToRoomID().String()))
 
-	contentTopic, err := n.roomCache.Get(room)
+	// Subscribe to all encodings, so that we can play with clients using a different one
+	contentTopics, err := n.roomCache.GetAll(room)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build content topic")
 	}
 
-	contentFilter := protocol.NewContentFilter(n.pubsubTopic, contentTopic)
+	contentFilter := protocol.NewContentFilter(n.pubsubTopic, contentTopics...)
 
 	var in chan *protocol.Envelope
 	var unsubscribe func()
@@ -442,6 +524,10 @@ func (n *Node) SubscribeToMessages(room *pp.Room) (*MessagesSubscription, error)
 			if err != nil {
 				n.logger.Warn("failed to unsubscribe from relay", zap.Error(err))
 			}
+			if n.roomSubscriptions.Load() > 1 {
+				// Pubsub topic is shared by all rooms, keep the validator for other rooms
+				return
+			}
 			// WARNING: Why 0 peers after this?
 			// FIXME: Open a go-waku PR. This unregister should be called in WakuRelay.RemoveTopicValidator
 			err = n.waku.Relay().PubSub().UnregisterTopicValidator(contentFilter.PubsubTopic)
@@ -467,6 +553,8 @@ func (n *Node) SubscribeToMessages(room *pp.Room) (*MessagesSubscription, error)
 		in = subs[0].Ch
 	}
 
+	n.roomSubscriptions.Add(1)
+
 	leaveRoom := make(chan struct{})
 	sub := &MessagesSubscription{
 		Ch: make(chan []byte, 10),
@@ -475,32 +563,52 @@ func (n *Node) SubscribeToMessages(room *pp.Room) (*MessagesSubscription, error)
 		},
 	}
 
+	var history chan *pb.WakuMessage
+	if config.WakuHistory() {
+		history = make(chan *pb.WakuMessage, 10)
+		go n.retrieveHistory(contentTopics, history, leaveRoom)
+	}
+
 	go func() {
 		defer func() {
 			unsubscribe()
+			n.roomSubscriptions.Add(-1)
+			n.roomCache.Remove(room)
 			close(sub.Ch)
 			n.logger.Debug("subscription channel closed")
 		}()
 
 		for {
+			var message *pb.WakuMessage
+
 			select {
 			case <-leaveRoom:
 				return
 			case value := <-in:
-				payload, err := decryptMessage(room, value.Message())
-				if err != nil {
-					n.logger.Warn("failed to decrypt message payload")
+				message = value.Message()
+			case value, more := <-history:
+				if !more {
+					history = nil
+					continue
 				}
+				message = value
+			}
 
-				sub.Ch <- payload
+			payload, err := decryptMessage(room, privateKey, message)
+			if err != nil {
+				// Private messages addressed to other players can't be decrypted, this is expected
+				n.logger.Debug("failed to decrypt message payload", zap.Error(err))
+				continue
 			}
+
+			sub.Ch <- payload
 		}
 	}()
 
 	return sub, nil
 }
 
-func decryptMessage(room *pp.Room, message *pb.WakuMessage) ([]byte, error) {
+func decryptMessage(room *pp.Room, privateKey *ecdsa.PrivateKey, message *pb.WakuMessage) ([]byte, error) {
 	// NOTE: waku automatically decide to decrypt or not based on message.Version (0/1)
 	//if !config.EnableSymmetricEncryption {
 	//	return payload, nil
@@ -511,12 +619,27 @@ func decryptMessage(room *pp.Room, message *pb.WakuMessage) ([]byte, error) {
 		SymKey: room.SymmetricKey,
 	}
 
-	err := wp.DecodeWakuMessage(message, keyInfo)
+	decoded, err := wp.DecodePayload(message, keyInfo)
+	if err == nil {
+		return decoded.Data, nil
+	}
+
+	if privateKey == nil {
+		return nil, errors.Wrap(err, "failed to decode waku message")
+	}
+
+	// The message is not encrypted with the room key, try to decrypt it as a private message
+	keyInfo = &wp.KeyInfo{
+		Kind:    wp.Asymmetric,
+		PrivKey: privateKey,
+	}
+
+	decoded, err = wp.DecodePayload(message, keyInfo)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to decode waku message")
 	}
 
-	return message.Payload, nil
+	return decoded.Data, nil
 }
 
 func (n *Node) ConnectionStatus() ConnectionStatus {
@@ -7,7 +7,10 @@ import (
 	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jonboulle/clockwork"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/suite"
 	"github.com/waku-org/go-waku/waku/v2/node"
 	wakuenr "github.com/waku-org/go-waku/waku/v2/protocol/enr"
@@ -52,15 +55,46 @@ func (s *WakuSuite) TestPublicEncryption() {
 	message, err := s.node.buildWakuMessage(room, payload)
 	s.Require().NoError(err)
 
-	err = s.node.encryptPublicPayload(room, message)
+	err = encryptPublicPayload(room, message)
 	s.Require().NoError(err)
 
-	decryptedPayload, err := decryptMessage(room, message)
+	decryptedPayload, err := decryptMessage(room, nil, message)
 	s.Require().NoError(err)
 
 	s.Require().Equal(payload, decryptedPayload)
 }
 
+func (s *WakuSuite) TestPrivateEncryption() {
+	room, err := pp.NewRoom()
+	s.Require().NoError(err)
+
+	recipientKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	otherKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	payload := make([]byte, 100)
+	gofakeit.Slice(payload)
+
+	message, err := s.node.buildWakuMessage(room, payload)
+	s.Require().NoError(err)
+
+	err = encryptPrivatePayload(&recipientKey.PublicKey, message)
+	s.Require().NoError(err)
+
+	// Room key is not enough to decrypt a private message
+	_, err = decryptMessage(room, nil, message)
+	s.Require().Error(err)
+
+	_, err = decryptMessage(room, otherKey, message)
+	s.Require().Error(err)
+
+	decryptedPayload, err := decryptMessage(room, recipientKey, message)
+	s.Require().NoError(err)
+	s.Require().Equal(payload, decryptedPayload)
+}
+
 func (s *WakuSuite) TestWakuInitialize() {
 	err := s.node.Initialize()
 	s.Require().NoError(err)
@@ -137,3 +171,79 @@ func (s *WakuSuite) TestWatchConnectionStatus() {
 		s.Require().Fail("timeout waiting for connection status watch finish")
 	}
 }
+
+func (s *WakuSuite) TestReconnectDelay() {
+	s.Require().Equal(reconnectMinDelay, reconnectDelay(0))
+	s.Require().Equal(2*reconnectMinDelay, reconnectDelay(1))
+	s.Require().Equal(4*reconnectMinDelay, reconnectDelay(2))
+	s.Require().Equal(reconnectMaxDelay, reconnectDelay(100))
+}
+
+func (s *WakuSuite) TestReconnect() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := clockwork.NewFakeClock()
+	attempts := make(chan int, 10)
+
+	n := NewNode(ctx, s.node.logger)
+	n.clock = clock
+	n.peerConnection = make(chan node.PeerConnection)
+	n.connectedPeers = make(map[peer.ID]struct{})
+	n.reconnect = func(attempt int) error {
+		attempts <- attempt
+		return errors.New("no peers dialed")
+	}
+
+	sub := n.SubscribeToConnectionStatus()
+	go n.watchConnectionStatus()
+
+	expectAttempt := func(expected int, delay time.Duration) {
+		clock.BlockUntil(1)
+		clock.Advance(delay)
+
+		select {
+		case status := <-sub:
+			s.Require().False(status.IsOnline)
+			s.Require().Equal(expected, status.ReconnectAttempt)
+		case <-time.After(500 * time.Millisecond):
+			s.Require().Fail("timeout waiting for connection status")
+		}
+
+		select {
+		case attempt := <-attempts:
+			s.Require().Equal(expected, attempt)
+		case <-time.After(500 * time.Millisecond):
+			s.Require().Fail("timeout waiting for reconnect attempt")
+		}
+	}
+
+	// Attempts are repeated with a growing delay
+	expectAttempt(1, reconnectDelay(0))
+	expectAttempt(2, reconnectDelay(1))
+
+	// Attempts counter is reset when connected
+	peerID := peer.ID(gofakeit.UUID())
+	n.peerConnection <- node.PeerConnection{PeerID: peerID, Connected: true}
+	status := <-sub
+	s.Require().True(status.IsOnline)
+	s.Require().Zero(status.ReconnectAttempt)
+
+	n.peerConnection <- node.PeerConnection{PeerID: peerID, Connected: false}
+	status = <-sub
+	s.Require().False(status.IsOnline)
+	s.Require().Zero(status.ReconnectAttempt)
+
+	expectAttempt(1, reconnectDelay(0))
+}
+
+func (s *WakuSuite) TestHistoryCriteria() {
+	now := time.Now()
+	contentTopics := []string{gofakeit.LetterN(10), gofakeit.LetterN(10)}
+
+	criteria := historyCriteria(s.node.pubsubTopic, contentTopics, now)
+	s.Require().Equal(s.node.pubsubTopic, criteria.PubsubTopic)
+	s.Require().ElementsMatch(contentTopics, criteria.ContentTopicsList())
+	s.Require().Equal(now.UnixNano(), *criteria.TimeEnd)
+	s.Require().Equal(historyPeriod, time.Duration(*criteria.TimeEnd-*criteria.TimeStart))
+}
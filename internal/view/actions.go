@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/exp/slices"
 
+	dealeractions "github.com/six78/2-story-points-cli/internal/actions"
 	"github.com/six78/2-story-points-cli/internal/view/commands"
 	"github.com/six78/2-story-points-cli/internal/view/messages"
 	"github.com/six78/2-story-points-cli/internal/view/states"
@@ -19,35 +20,57 @@ import (
 type Action string
 
 const (
-	Rename  Action = "rename"
-	New     Action = "new"
-	Join    Action = "join"
-	Exit    Action = "exit"
-	Vote    Action = "vote"
-	Retract Action = "retract"
-	Deal    Action = "deal"
-	Add     Action = "add"
-	Reveal  Action = "reveal"
-	Finish  Action = "finish"
-	Deck    Action = "deck"
-	Select  Action = "select"
+	Rename     Action = "rename"
+	New        Action = "new"
+	Join       Action = "join"
+	Exit       Action = "exit"
+	Vote       Action = "vote"
+	Retract    Action = "retract"
+	Role       Action = "role"
+	Deal       Action = Action(dealeractions.Deal)
+	Add        Action = Action(dealeractions.Add)
+	Reveal     Action = Action(dealeractions.Reveal)
+	Finish     Action = Action(dealeractions.Finish)
+	Deck       Action = Action(dealeractions.Deck)
+	Select     Action = Action(dealeractions.Select)
+	Hint       Action = Action(dealeractions.Hint)
+	Remove     Action = Action(dealeractions.Remove)
+	Edit       Action = Action(dealeractions.Edit)
+	Move       Action = Action(dealeractions.Move)
+	Revote     Action = Action(dealeractions.Revote)
+	Timebox    Action = Action(dealeractions.Timebox)
+	Countdown  Action = Action(dealeractions.Countdown)
+	AutoReveal Action = Action(dealeractions.AutoReveal)
+	Dealer     Action = "dealer"
+	Switch     Action = "switch"
 )
 
 type actionFunc func(m *model, args []string) tea.Cmd
 
 var actions = map[Action]actionFunc{
-	Rename:  runRenameAction,
-	Vote:    runVoteAction,
-	Retract: runRetractAction,
-	Deal:    runDealAction,
-	Add:     runAddAction,
-	New:     runNewAction,
-	Join:    runJoinAction,
-	Exit:    runExitAction,
-	Reveal:  runRevealAction,
-	Finish:  runFinishAction,
-	Deck:    runDeckAction,
-	Select:  runSelectAction,
+	Rename:     runRenameAction,
+	Vote:       runVoteAction,
+	Retract:    runRetractAction,
+	Role:       runRoleAction,
+	Deal:       runDealAction,
+	Add:        runAddAction,
+	New:        runNewAction,
+	Join:       runJoinAction,
+	Exit:       runExitAction,
+	Reveal:     runRevealAction,
+	Finish:     runFinishAction,
+	Deck:       runDeckAction,
+	Select:     runSelectAction,
+	Hint:       runHintAction,
+	Remove:     runRemoveAction,
+	Edit:       runEditAction,
+	Move:       runMoveAction,
+	Revote:     runRevoteAction,
+	Timebox:    runTimeboxAction,
+	Countdown:  runCountdownAction,
+	AutoReveal: runAutoRevealAction,
+	Dealer:     runDealerAction,
+	Switch:     runSwitchAction,
 }
 
 func processPlayerNameInput(m *model, playerName string) tea.Cmd {
@@ -62,6 +85,14 @@ func processPlayerNameInput(m *model, playerName string) tea.Cmd {
 	}
 }
 
+func processRoomNameInput(m *model, name string) tea.Cmd {
+	room, ok := m.roomsView.Selected()
+	if !ok {
+		return nil
+	}
+	return commands.RenameSavedRoom(m.game, room.RoomID, strings.TrimSpace(name))
+}
+
 func runRenameAction(m *model, args []string) tea.Cmd {
 	return func() tea.Msg {
 		if len(args) == 0 {
@@ -100,27 +131,25 @@ func runRetractAction(m *model, args []string) tea.Cmd {
 	}
 }
 
-func runDealAction(m *model, args []string) tea.Cmd {
+// runRoleAction switches between voting and observing: role voter|observer.
+func runRoleAction(m *model, args []string) tea.Cmd {
 	return func() tea.Msg {
 		if len(args) == 0 {
-			err := errors.New("empty deal")
+			err := errors.Errorf("no role provided, available roles: %s, %s", protocol.VoterRole, protocol.ObserverRole)
 			return messages.NewErrorMessage(err)
 		}
-		// TODO: Find a better way of restoring empty spaces between args
-		issue := strings.Join(args, " ")
-		_, err := m.game.Deal(issue)
+		role := protocol.PlayerRole(strings.ToLower(args[0]))
+		err := m.game.SetPlayerRole(role)
 		return messages.NewErrorMessage(err)
 	}
 }
 
+func runDealAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunDeal, args)
+}
+
 func runAddAction(m *model, args []string) tea.Cmd {
-	return func() tea.Msg {
-		if len(args) == 0 {
-			err := errors.New("empty issue")
-			return messages.NewErrorMessage(err)
-		}
-		return commands.AddIssue(m.game, strings.Join(args, " "))()
-	}
+	return dealerAction(m, dealeractions.RunAdd, args)
 }
 
 func runNewAction(m *model, args []string) tea.Cmd {
@@ -139,96 +168,116 @@ func runJoinAction(m *model, args []string) tea.Cmd {
 }
 
 func runExitAction(m *model, args []string) tea.Cmd {
-	return func() tea.Msg {
-		m.game.LeaveRoom()
-		return messages.RoomJoin{
-			RoomID:   m.game.RoomID(),
-			IsDealer: m.game.IsDealer(),
-		}
-	}
+	return commands.LeaveRoom(m.game)
 }
 
 func runRevealAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunReveal, args)
+}
+
+func runRevoteAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunRevote, args)
+}
+
+func runTimeboxAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunTimebox, args)
+}
+
+func runCountdownAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunCountdown, args)
+}
+
+func runAutoRevealAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunAutoReveal, args)
+}
+
+func runFinishAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunFinish, args)
+}
+
+func runDeckAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunDeck, args)
+}
+
+func runSelectAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunSelect, args)
+}
+
+func runHintAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunHint, args)
+}
+
+func runRemoveAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunRemove, args)
+}
+
+func runEditAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunEdit, args)
+}
+
+func runMoveAction(m *model, args []string) tea.Cmd {
+	return dealerAction(m, dealeractions.RunMove, args)
+}
+
+func dealerAction(m *model, action func(*game.Game, []string) error, args []string) tea.Cmd {
 	return func() tea.Msg {
-		err := m.game.Reveal()
+		err := action(m.game, args)
 		return messages.NewErrorMessage(err)
 	}
 }
 
-func runFinishAction(m *model, args []string) tea.Cmd {
+func runDealerAction(m *model, args []string) tea.Cmd {
 	return func() tea.Msg {
 		if len(args) == 0 {
-			err := errors.New("empty result")
-			return messages.NewErrorMessage(err)
-		}
-		result, err := parseVote(args[0])
-		if err != nil {
+			err := errors.New("no player provided")
 			return messages.NewErrorMessage(err)
 		}
-		if !slices.Contains(m.gameState.Deck, result) {
-			err = errors.New("result not in deck")
+		if m.gameState == nil {
+			err := errors.New("no room joined")
 			return messages.NewErrorMessage(err)
 		}
-		err = m.game.Finish(result)
-		return messages.NewErrorMessage(err)
-	}
-}
 
-func parseDeck(args []string) (protocol.Deck, error) {
-	if len(args) == 0 {
-		return nil, errors.New("deck can't be empty")
-	}
-
-	if len(args) == 1 {
-		// attempt to parse deck by name
-		deckName := strings.ToLower(args[0])
-		deck, ok := game.GetDeck(deckName)
-		if !ok {
-			return nil, fmt.Errorf("unknown deck: '%s', available decks: %s",
-				args[0], strings.Join(game.AvailableDecks(), ", "))
+		// Player can be given either by name or by ID
+		index := slices.IndexFunc(m.gameState.Players, func(player protocol.Player) bool {
+			return player.Name == args[0] || string(player.ID) == args[0]
+		})
+		if index < 0 {
+			err := fmt.Errorf("player not found: '%s'", args[0])
+			return messages.NewErrorMessage(err)
 		}
-		return deck, nil
-	}
-
-	deck := protocol.Deck{}
-	cards := map[string]struct{}{}
 
-	for _, card := range args {
-		if _, ok := cards[card]; ok {
-			return nil, fmt.Errorf("duplicate card: '%s'", card)
-		}
-		cards[card] = struct{}{}
-		deck = append(deck, protocol.VoteValue(card))
+		err := m.game.TransferDealer(m.gameState.Players[index].ID)
+		return messages.NewErrorMessage(err)
 	}
-
-	return deck, nil
 }
 
-func runDeckAction(m *model, args []string) tea.Cmd {
+// runSwitchAction switches to the room given by its number (starting from 1) or ID.
+// Without arguments switches to the next joined room.
+func runSwitchAction(m *model, args []string) tea.Cmd {
 	return func() tea.Msg {
-		deck, err := parseDeck(args)
-		if err != nil {
+		if len(m.rooms) == 0 {
+			err := errors.New("no room joined")
 			return messages.NewErrorMessage(err)
 		}
 
-		err = m.game.SetDeck(deck)
-		return messages.NewErrorMessage(err)
-	}
-}
-
-func runSelectAction(m *model, args []string) tea.Cmd {
-	return func() tea.Msg {
 		if len(args) == 0 {
-			err := errors.New("no issue index provided")
-			return messages.NewErrorMessage(err)
+			return commands.SwitchRoom(m.game, nextRoom(m.rooms, m.roomID))()
 		}
 
-		index, err := strconv.Atoi(args[0])
-		if err != nil {
-			err = fmt.Errorf("invalid issue index: %s (%w)", args[0], err)
-			return messages.NewErrorMessage(err)
+		roomID := protocol.NewRoomID(args[0])
+		if number, err := strconv.Atoi(args[0]); err == nil {
+			if number < 1 || number > len(m.rooms) {
+				err = fmt.Errorf("invalid room number: %d", number)
+				return messages.NewErrorMessage(err)
+			}
+			roomID = m.rooms[number-1]
 		}
 
-		return commands.SelectIssue(m.game, index)()
+		return commands.SwitchRoom(m.game, roomID)()
 	}
 }
+
+func nextRoom(rooms []protocol.RoomID, current protocol.RoomID) protocol.RoomID {
+	index := slices.Index(rooms, current)
+	return rooms[(index+1)%len(rooms)]
+}
@@ -28,6 +28,11 @@ func ProcessInput(m *model) tea.Cmd {
 		return processPlayerNameInput(m, m.input.Value())
 	}
 
+	if m.state == states.SelectingRoom {
+		defer m.input.Reset()
+		return processRoomNameInput(m, m.input.Value())
+	}
+
 	if m.state == states.Playing {
 		defer m.input.Reset()
 		return ProcessAction(m, m.input.Value())
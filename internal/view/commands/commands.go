@@ -54,10 +54,7 @@ func CreateNewRoom(game *game.Game) tea.Cmd {
 			return messages.NewErrorMessage(err)
 		}
 
-		return messages.RoomJoin{
-			RoomID:   game.RoomID(),
-			IsDealer: game.IsDealer(),
-		}
+		return roomJoinMessage(game)
 	}
 }
 
@@ -67,10 +64,76 @@ func JoinRoom(game *game.Game, roomID protocol.RoomID) tea.Cmd {
 		if err != nil {
 			return messages.NewErrorMessage(err)
 		}
-		return messages.RoomJoin{
-			RoomID:   game.RoomID(),
-			IsDealer: game.IsDealer(),
+		return roomJoinMessage(game)
+	}
+}
+
+func OpenArchivedRoom(game *game.Game, roomID protocol.RoomID) tea.Cmd {
+	return func() tea.Msg {
+		err := game.OpenArchivedRoom(roomID)
+		if err != nil {
+			return messages.NewErrorMessage(err)
+		}
+		return roomJoinMessage(game)
+	}
+}
+
+func SwitchRoom(game *game.Game, roomID protocol.RoomID) tea.Cmd {
+	return func() tea.Msg {
+		err := game.SwitchRoom(roomID)
+		if err != nil {
+			return messages.NewErrorMessage(err)
 		}
+		return roomJoinMessage(game)
+	}
+}
+
+func LeaveRoom(game *game.Game) tea.Cmd {
+	return func() tea.Msg {
+		game.LeaveRoom()
+		return roomJoinMessage(game)
+	}
+}
+
+func LoadSavedRooms(game *game.Game) tea.Cmd {
+	return func() tea.Msg {
+		return savedRoomsMessage(game)
+	}
+}
+
+func RenameSavedRoom(game *game.Game, roomID protocol.RoomID, name string) tea.Cmd {
+	return func() tea.Msg {
+		err := game.RenameSavedRoom(roomID, name)
+		if err != nil {
+			return messages.NewErrorMessage(err)
+		}
+		return savedRoomsMessage(game)
+	}
+}
+
+func DeleteSavedRoom(game *game.Game, roomID protocol.RoomID) tea.Cmd {
+	return func() tea.Msg {
+		err := game.DeleteSavedRoom(roomID)
+		if err != nil {
+			return messages.NewErrorMessage(err)
+		}
+		return savedRoomsMessage(game)
+	}
+}
+
+func savedRoomsMessage(game *game.Game) tea.Msg {
+	rooms, err := game.SavedRooms()
+	if err != nil {
+		return messages.NewErrorMessage(err)
+	}
+	return messages.SavedRooms{Rooms: rooms}
+}
+
+func roomJoinMessage(game *game.Game) messages.RoomJoin {
+	return messages.RoomJoin{
+		RoomID:   game.RoomID(),
+		IsDealer: game.IsDealer(),
+		Rooms:    game.Rooms(),
 	}
 }
 
@@ -95,7 +158,8 @@ func PublishVote(game *game.Game, vote protocol.VoteValue) tea.Cmd {
 		}
 		// TODO: Send err=nil ErrorMessage here
 		return messages.MyVote{
-			Result: game.MyVote(),
+			Result:  game.MyVote(),
+			Pending: game.VotePending(),
 		}
 	}
 }
@@ -108,7 +172,8 @@ func RetractVote(game *game.Game) tea.Cmd {
 		}
 		// TODO: Send err=nil ErrorMessage here
 		return messages.MyVote{
-			Result: game.MyVote(),
+			Result:  game.MyVote(),
+			Pending: game.VotePending(),
 		}
 	}
 }
@@ -134,10 +199,24 @@ func SelectIssue(game *game.Game, index int) tea.Cmd {
 	}
 }
 
+func RemoveIssue(game *game.Game, index int) tea.Cmd {
+	return func() tea.Msg {
+		err := game.RemoveIssue(index)
+		return messages.NewErrorMessage(err)
+	}
+}
+
+func MoveIssue(game *game.Game, index int, newIndex int) tea.Cmd {
+	return func() tea.Msg {
+		err := game.MoveIssue(index, newIndex)
+		return messages.NewErrorMessage(err)
+	}
+}
+
 func QuitApp(game *game.Game) tea.Cmd {
 	return func() tea.Msg {
 		if game != nil {
-			game.LeaveRoom()
+			game.LeaveAllRooms()
 		}
 		return tea.Quit()
 	}
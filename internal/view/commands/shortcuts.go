@@ -12,6 +12,10 @@ type KeyMap struct {
 	NextIssue     key.Binding
 	PreviousIssue key.Binding
 	SelectIssue   key.Binding
+	RemoveIssue   key.Binding
+	EditIssue     key.Binding
+	MoveIssueUp   key.Binding
+	MoveIssueDown key.Binding
 	// Deck view
 	NextCard     key.Binding
 	PreviousCard key.Binding
@@ -20,11 +24,20 @@ type KeyMap struct {
 	// Dealer controls
 	RevealVotes key.Binding
 	FinishVote  key.Binding
+	Revote      key.Binding
 	AddIssue    key.Binding
 	// Room controls
-	NewRoom  key.Binding
-	JoinRoom key.Binding
-	ExitRoom key.Binding
+	NewRoom    key.Binding
+	JoinRoom   key.Binding
+	ExitRoom   key.Binding
+	SwitchRoom key.Binding
+	// Saved rooms list
+	NextRoom     key.Binding
+	PreviousRoom key.Binding
+	ResumeRoom   key.Binding
+	RenameRoom   key.Binding
+	DeleteRoom   key.Binding
+	SkipRooms    key.Binding
 }
 
 var DefaultKeyMap = KeyMap{
@@ -50,6 +63,22 @@ var DefaultKeyMap = KeyMap{
 		key.WithKeys("enter"),
 		key.WithHelp("Enter", "Select issue"),
 	),
+	RemoveIssue: key.NewBinding(
+		key.WithKeys("delete"),
+		key.WithHelp("Del", "Remove issue"),
+	),
+	EditIssue: key.NewBinding(
+		key.WithKeys("ctrl+e"),
+		key.WithHelp("Ctrl+E", "Edit issue"),
+	),
+	MoveIssueUp: key.NewBinding(
+		key.WithKeys("shift+up"),
+		key.WithHelp("Shift+↑", "Move issue up"),
+	),
+	MoveIssueDown: key.NewBinding(
+		key.WithKeys("shift+down"),
+		key.WithHelp("Shift+↓", "Move issue down"),
+	),
 	// Deck view
 	NextCard: key.NewBinding(
 		key.WithKeys("right"),
@@ -74,6 +103,9 @@ var DefaultKeyMap = KeyMap{
 	FinishVote: key.NewBinding(
 		key.WithKeys("f"),
 		key.WithHelp("F", "Finish vote and deal next issue")),
+	Revote: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("V", "Vote again")),
 	AddIssue: key.NewBinding(
 		key.WithKeys("a"),
 		key.WithHelp("A", "Add issue")),
@@ -88,4 +120,33 @@ var DefaultKeyMap = KeyMap{
 		key.WithKeys("e"),
 		key.WithHelp("E", "Exit room"),
 	),
+	SwitchRoom: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("S", "Switch room"),
+	),
+	// Saved rooms list
+	NextRoom: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "Next room"),
+	),
+	PreviousRoom: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "Previous room"),
+	),
+	ResumeRoom: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("Enter", "Resume room"),
+	),
+	RenameRoom: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("R", "Rename room"),
+	),
+	DeleteRoom: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("D", "Delete room"),
+	),
+	SkipRooms: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("Esc", "Skip"),
+	),
 }
@@ -0,0 +1,73 @@
+package countdownview
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/six78/2-story-points-cli/internal/config"
+	"github.com/six78/2-story-points-cli/internal/view/messages"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+// urgentThreshold is the remaining time when the countdown is highlighted.
+const urgentThreshold = 10 * time.Second
+
+var (
+	style       = lipgloss.NewStyle().Foreground(config.ForegroundShadeColor)
+	urgentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+)
+
+// Model shows the time left until votes are revealed by the voting deadline.
+// The view is re-rendered by other components (e.g. spinner), so there's no separate tick.
+type Model struct {
+	deadline time.Time
+	now      func() time.Time
+}
+
+func New() Model {
+	return Model{
+		now: time.Now,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case messages.GameStateMessage:
+		m.deadline = time.Time{}
+		if msg.State == nil || msg.State.VotingDeadline == 0 {
+			break
+		}
+		if msg.State.VoteState() != protocol.VotingState {
+			break
+		}
+		m.deadline = time.UnixMilli(msg.State.VotingDeadline)
+	}
+
+	return m, nil
+}
+
+func (m Model) View() string {
+	if m.deadline.IsZero() {
+		return ""
+	}
+
+	left := m.deadline.Sub(m.now())
+	if left <= 0 {
+		return urgentStyle.Render("Time is up, revealing votes...")
+	}
+
+	// Round up, so that 0:00 is never shown before the deadline
+	seconds := int((left + time.Second - 1) / time.Second)
+	text := fmt.Sprintf("Revealing in %d:%02d", seconds/60, seconds%60)
+	if left <= urgentThreshold {
+		return urgentStyle.Render(text)
+	}
+	return style.Render(text)
+}
@@ -0,0 +1,43 @@
+package countdownview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/six78/2-story-points-cli/internal/view/messages"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+func TestCountdown(t *testing.T) {
+	now := time.Now()
+	model := New()
+	model.now = func() time.Time { return now }
+	require.Nil(t, model.Init())
+
+	model, cmd := model.Update(messages.GameStateMessage{State: nil})
+	require.Nil(t, cmd)
+	require.Empty(t, model.View())
+
+	issue := protocol.Issue{ID: "1"}
+	state := &protocol.State{
+		Issues:         protocol.IssuesList{&issue},
+		ActiveIssue:    issue.ID,
+		VotingDeadline: now.Add(90*time.Second + 500*time.Millisecond).UnixMilli(),
+	}
+
+	model, _ = model.Update(messages.GameStateMessage{State: state})
+	require.Equal(t, "Revealing in 1:31", model.View())
+
+	now = now.Add(90 * time.Second)
+	require.Equal(t, "Revealing in 0:01", model.View())
+
+	now = now.Add(time.Second)
+	require.Equal(t, "Time is up, revealing votes...", model.View())
+
+	// Countdown is hidden when votes are revealed
+	state.VotesRevealed = true
+	model, _ = model.Update(messages.GameStateMessage{State: state})
+	require.Empty(t, model.View())
+}
@@ -9,19 +9,25 @@ import (
 	"github.com/six78/2-story-points-cli/internal/view/components/cursor"
 	"github.com/six78/2-story-points-cli/internal/view/components/voteview"
 	"github.com/six78/2-story-points-cli/internal/view/messages"
+	"github.com/six78/2-story-points-cli/pkg/game"
 	"github.com/six78/2-story-points-cli/pkg/protocol"
 )
 
 var (
 	defaultBorderStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#555555"))
 	votedBorderStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#aaaaaa"))
+	pendingBorderStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFEA00"))
 	highlightBorderStyle = lipgloss.NewStyle().Foreground(config.UserColor)
+	receivedMarkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#00E676"))
+	lostMarkStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5722"))
 )
 
 type Model struct {
 	deck          protocol.Deck
 	voteState     protocol.VoteState
 	myVote        protocol.VoteValue
+	votePending   bool
+	voteDelivery  game.VoteDelivery
 	votesRevealed bool
 	focused       bool
 	isDealer      bool
@@ -67,12 +73,20 @@ func (m Model) Update(msg tea.Msg) Model {
 		m.isDealer = msg.IsDealer
 		m.updateCursorsState()
 
+	case messages.DealerChanged:
+		m.isDealer = msg.IsDealer
+		m.updateCursorsState()
+
 	case messages.CommandModeChange:
 		m.commandMode = msg.CommandMode
 		m.updateCursorsState()
 
 	case messages.MyVote:
 		m.myVote = msg.Result.Value
+		m.votePending = msg.Pending
+
+	case messages.VoteDelivery:
+		m.voteDelivery = msg.Delivery
 	}
 
 	m.voteCursor = m.voteCursor.Update(msg)
@@ -84,7 +98,7 @@ func (m Model) Update(msg tea.Msg) Model {
 func (m Model) View() string {
 	cards := make([]string, 0, len(m.deck)*2)
 
-	for i, value := range m.deck {
+	for i, value := range m.deck.Values() {
 		card := renderCard(
 			value,
 			m.deck,
@@ -92,6 +106,8 @@ func (m Model) View() string {
 				voteCursor:   m.voteCursor.Match(i),
 				finishCursor: m.finishCursor.Match(i),
 				voted:        value == m.myVote,
+				pending:      value == m.myVote && m.votePending,
+				delivery:     m.voteDelivery,
 			},
 		)
 		cards = append(cards, card, " ") // Add a space between cards
@@ -127,12 +143,14 @@ type renderCardFlags struct {
 	voteCursor   bool
 	finishCursor bool
 	voted        bool
+	pending      bool              // Vote is not yet confirmed by the dealer
+	delivery     game.VoteDelivery // Only shown for the voted card
 }
 
 func renderCard(value protocol.VoteValue, deck protocol.Deck, flags renderCardFlags) string {
 	card := table.New().
 		Border(lipgloss.RoundedBorder()).
-		BorderStyle(*cardBorderStyle(flags.voted, flags.finishCursor)).
+		BorderStyle(*cardBorderStyle(flags.voted, flags.pending, flags.finishCursor)).
 		StyleFunc(func(row, col int) lipgloss.Style {
 			return *voteview.VoteStyle(value, deck)
 		}).
@@ -148,24 +166,39 @@ func renderCard(value protocol.VoteValue, deck protocol.Deck, flags renderCardFl
 
 	column = append(column, card)
 
+	if flags.voted {
+		column = append(column, deliveryMark(flags.delivery))
+	}
+
 	if flags.voteCursor {
-		if flags.voted {
-			column = append(column, "")
-		}
 		column = append(column, "  ^")
-	} else {
+	} else if !flags.voted {
 		column = append(column, "")
 	}
 
 	return lipgloss.JoinVertical(lipgloss.Top, column...)
 }
 
-func cardBorderStyle(voted bool, highlight bool) *lipgloss.Style {
+func cardBorderStyle(voted bool, pending bool, highlight bool) *lipgloss.Style {
 	if highlight {
 		return &highlightBorderStyle
 	}
+	if pending {
+		return &pendingBorderStyle
+	}
 	if voted {
 		return &votedBorderStyle
 	}
 	return &defaultBorderStyle
 }
+
+func deliveryMark(delivery game.VoteDelivery) string {
+	switch delivery {
+	case game.VoteReceived:
+		return receivedMarkStyle.Render("  ✓")
+	case game.VoteLost:
+		return lostMarkStyle.Render("  ✗")
+	default:
+		return ""
+	}
+}
@@ -22,7 +22,7 @@ type Suite struct {
 }
 
 func (s *Suite) TestRenderCard() {
-	deck := protocol.Deck{"1", "2", "3", "4"}
+	deck := protocol.DeckFromValues("1", "2", "3", "4")
 
 	testCases := []struct {
 		value    protocol.VoteValue
@@ -46,6 +46,30 @@ func (s *Suite) TestRenderCard() {
 	}
 }
 
+func (s *Suite) TestRenderDeliveryMark() {
+	deck := protocol.DeckFromValues("1")
+
+	testCases := []struct {
+		delivery game.VoteDelivery
+		cursor   bool
+		expected string
+	}{
+		{game.VoteSent, false, "╭───╮\n│ 1 │\n╰───╯\n     "},
+		{game.VoteReceived, false, "╭───╮\n│ 1 │\n╰───╯\n  ✓  "},
+		{game.VoteReceived, true, "╭───╮\n│ 1 │\n╰───╯\n  ✓  \n  ^  "},
+		{game.VoteLost, false, "╭───╮\n│ 1 │\n╰───╯\n  ✗  "},
+	}
+
+	for _, tc := range testCases {
+		result := renderCard(deck[0].Value, deck, renderCardFlags{
+			voteCursor: tc.cursor,
+			voted:      true,
+			delivery:   tc.delivery,
+		})
+		s.Require().Equal(tc.expected, result)
+	}
+}
+
 func (s *Suite) TestRenderDeck() {
 	model := Model{
 		deck:         game.CreateDeck([]string{"1", "2", "3"}),
@@ -266,15 +290,21 @@ func (s *Suite) TestCursor() {
 }
 
 func (s *Suite) TestBorderStyle() {
-	style := cardBorderStyle(false, false)
+	style := cardBorderStyle(false, false, false)
 	s.Require().Equal(&defaultBorderStyle, style)
 
-	style = cardBorderStyle(true, false)
+	style = cardBorderStyle(true, false, false)
 	s.Require().Equal(&votedBorderStyle, style)
 
-	style = cardBorderStyle(false, true)
+	style = cardBorderStyle(true, true, false)
+	s.Require().Equal(&pendingBorderStyle, style)
+
+	style = cardBorderStyle(false, false, true)
+	s.Require().Equal(&highlightBorderStyle, style)
+
+	style = cardBorderStyle(true, false, true)
 	s.Require().Equal(&highlightBorderStyle, style)
 
-	style = cardBorderStyle(true, true)
+	style = cardBorderStyle(true, true, true)
 	s.Require().Equal(&highlightBorderStyle, style)
 }
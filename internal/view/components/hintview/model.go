@@ -1,6 +1,10 @@
 package hintview
 
 import (
+	"math"
+	"strconv"
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
@@ -17,8 +21,9 @@ var (
 )
 
 type Model struct {
-	hint *protocol.Hint
-	deck protocol.Deck
+	hint    *protocol.Hint
+	deck    protocol.Deck
+	players protocol.PlayersList
 }
 
 func New() Model {
@@ -45,6 +50,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 
 		m.deck = msg.State.Deck
+		m.players = msg.State.Players
 	}
 
 	return m, nil
@@ -55,11 +61,40 @@ func (m Model) View() string {
 		return ""
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Top,
-		headerStyle.Render("Recommended:")+""+voteview.Render(m.hint.Value, m.deck),
-		headerStyle.Render("Acceptable:")+"  "+renderAcceptanceIcon(m.hint.Acceptable),
-		headerStyle.Render(">")+" "+textStyle.Render(m.hint.Description),
-	)
+	lines := []string{
+		headerStyle.Render("Recommended:") + "" + voteview.Render(m.hint.Value, m.deck),
+		headerStyle.Render("Acceptable:") + "  " + renderAcceptanceIcon(m.hint.Acceptable),
+	}
+	if m.hint.Average != nil {
+		lines = append(lines, headerStyle.Render("Average:")+"     "+renderAverage(*m.hint.Average))
+	}
+	if len(m.hint.LowestVoters) > 0 {
+		lines = append(lines, headerStyle.Render("Lowest:")+"      "+m.renderPlayers(m.hint.LowestVoters))
+	}
+	if len(m.hint.HighestVoters) > 0 {
+		lines = append(lines, headerStyle.Render("Highest:")+"     "+m.renderPlayers(m.hint.HighestVoters))
+	}
+	lines = append(lines, headerStyle.Render(">")+" "+textStyle.Render(m.hint.Description))
+
+	return lipgloss.JoinVertical(lipgloss.Top, lines...)
+}
+
+func renderAverage(average float64) string {
+	return strconv.FormatFloat(math.Round(average*100)/100, 'f', -1, 64)
+}
+
+// renderPlayers renders names of given players, player ID is shown when the player is unknown.
+func (m Model) renderPlayers(playerIDs []protocol.PlayerID) string {
+	names := make([]string, 0, len(playerIDs))
+	for _, playerID := range playerIDs {
+		player, ok := m.players.Get(playerID)
+		if ok && player.Name != "" {
+			names = append(names, player.Name)
+		} else {
+			names = append(names, string(playerID))
+		}
+	}
+	return strings.Join(names, ", ")
 }
 
 func renderAcceptanceIcon(acceptable bool) string {
@@ -106,3 +106,60 @@ func TestUpdateAcceptableVote(t *testing.T) {
 		})
 	}
 }
+
+func TestAverage(t *testing.T) {
+	average := 10 / 3.0
+	issue := protocol.Issue{
+		ID: protocol.IssueID(gofakeit.UUID()),
+		Hint: &protocol.Hint{
+			Acceptable:  true,
+			Value:       "3",
+			Description: gofakeit.LetterN(10),
+			Average:     &average,
+		},
+	}
+
+	model := New()
+	model, _ = model.Update(messages.GameStateMessage{
+		State: &protocol.State{
+			Issues:        protocol.IssuesList{&issue},
+			ActiveIssue:   issue.ID,
+			VotesRevealed: true,
+		},
+	})
+
+	lines := strings.Split(model.View(), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, "Average:     3.33", strings.Trim(lines[2], " "))
+}
+
+func TestOutliers(t *testing.T) {
+	issue := protocol.Issue{
+		ID: protocol.IssueID(gofakeit.UUID()),
+		Hint: &protocol.Hint{
+			Acceptable:    false,
+			Value:         "3",
+			Description:   gofakeit.LetterN(10),
+			LowestVoters:  []protocol.PlayerID{"1", "2"},
+			HighestVoters: []protocol.PlayerID{"3"},
+		},
+	}
+
+	model := New()
+	model, _ = model.Update(messages.GameStateMessage{
+		State: &protocol.State{
+			Players: protocol.PlayersList{
+				{ID: "1", Name: "Alice"},
+				{ID: "2", Name: "Bob"},
+			},
+			Issues:        protocol.IssuesList{&issue},
+			ActiveIssue:   issue.ID,
+			VotesRevealed: true,
+		},
+	})
+
+	lines := strings.Split(model.View(), "\n")
+	require.Len(t, lines, 5)
+	require.Equal(t, "Lowest:      Alice, Bob", strings.Trim(lines[2], " "))
+	require.Equal(t, "Highest:     3", strings.Trim(lines[3], " "))
+}
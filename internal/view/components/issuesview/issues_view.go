@@ -68,6 +68,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case messages.RoomJoin:
 		m.isDealer = msg.IsDealer
 		m.updateCursorFocus()
+
+	case messages.DealerChanged:
+		m.isDealer = msg.IsDealer
+		m.updateCursorFocus()
 	}
 
 	var spinnerCommand tea.Cmd
@@ -131,3 +135,7 @@ func (m *Model) Blur() {
 func (m *Model) CursorPosition() int {
 	return m.cursor.Position()
 }
+
+func (m *Model) SetCursorPosition(position int) {
+	m.cursor.SetPosition(position)
+}
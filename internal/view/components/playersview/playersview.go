@@ -1,6 +1,8 @@
 package playersview
 
 import (
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
@@ -12,6 +14,12 @@ import (
 const textColor = lipgloss.Color("#FAFAFA")
 const borderColor = lipgloss.Color("#555555")
 
+// verifiedSymbol is shown next to players whose messages signature was verified by the dealer
+const verifiedSymbol = "✔"
+
+// dealerSymbol is shown next to the player that currently owns the room
+const dealerSymbol = "♛"
+
 var (
 	onlinePlayerStyle = lipgloss.NewStyle().
 				Foreground(textColor).
@@ -36,6 +44,7 @@ type Model struct {
 	playersOnline []bool
 	playerID      protocol.PlayerID
 	playerColumn  int
+	observers     []string // Names of players who don't vote, they get no card
 }
 
 func New() Model {
@@ -97,7 +106,12 @@ func (m Model) View() string {
 		Headers(m.playerNames...).
 		Rows([][]string{row}...)
 
-	return t.String()
+	if len(m.observers) == 0 {
+		return t.String()
+	}
+
+	observers := offlinePlayerStyle.Render("Observers: " + strings.Join(m.observers, ", "))
+	return lipgloss.JoinVertical(lipgloss.Left, t.String(), observers)
 }
 
 func handleNewState(m *Model, state *protocol.State) {
@@ -111,13 +125,27 @@ func handleNewState(m *Model, state *protocol.State) {
 	m.playerNames = make([]string, 0, len(state.Players))
 	m.playersOnline = make([]bool, 0, len(state.Players))
 	m.votes = make([]playervoteview.Model, 0, len(state.Players))
+	m.observers = nil
+	m.playerColumn = -1
 
-	for i, player := range state.Players {
+	for _, player := range state.Players {
 		playerName := player.Name
+		if player.Verified() {
+			playerName += " " + verifiedSymbol
+		}
+		if player.ID == state.Dealer {
+			playerName += " " + dealerSymbol
+		}
 		if player.ID == m.playerID {
-			m.playerColumn = i
 			playerName += " (You)"
 		}
+		if player.Observer() {
+			m.observers = append(m.observers, playerName)
+			continue
+		}
+		if player.ID == m.playerID {
+			m.playerColumn = len(m.playerNames)
+		}
 		m.playerNames = append(m.playerNames, playerName)
 		m.playersOnline = append(m.playersOnline, player.Online)
 		voteView := playervoteview.New(player.ID)
@@ -0,0 +1,144 @@
+package roomsview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/six78/2-story-points-cli/internal/config"
+	"github.com/six78/2-story-points-cli/internal/view/commands"
+	"github.com/six78/2-story-points-cli/internal/view/components/cursor"
+	"github.com/six78/2-story-points-cli/internal/view/messages"
+	"github.com/six78/2-story-points-cli/pkg/storage"
+)
+
+const (
+	cursorSymbol   = ">"
+	lastUsedLayout = "2006-01-02 15:04"
+)
+
+var (
+	highlightStyle = lipgloss.NewStyle().Foreground(config.UserColor)
+	shadeStyle     = lipgloss.NewStyle().Foreground(config.ForegroundShadeColor)
+)
+
+type Model struct {
+	rooms         []storage.RoomInfo
+	loaded        bool
+	confirmDelete bool // Delete key was pressed once, waiting for confirmation
+
+	cursor cursor.Model
+}
+
+func New() Model {
+	return Model{
+		rooms:         nil,
+		loaded:        false,
+		confirmDelete: false,
+		cursor:        cursor.New(true, true),
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) Model {
+	switch msg := msg.(type) {
+	case messages.SavedRooms:
+		m.rooms = msg.Rooms
+		m.loaded = true
+		m.confirmDelete = false
+		m.cursor.SetRange(0, len(m.rooms)-1)
+	case tea.KeyMsg:
+		if !key.Matches(msg, commands.DefaultKeyMap.DeleteRoom) {
+			m.confirmDelete = false
+		}
+	}
+
+	m.cursor = m.cursor.Update(msg)
+	return m
+}
+
+func (m Model) View() string {
+	if !m.loaded {
+		return "Loading saved rooms ..."
+	}
+
+	items := make([]string, 0, len(m.rooms))
+
+	for i, room := range m.rooms {
+		var item string
+		var style lipgloss.Style
+
+		if m.cursor.Match(i) {
+			item += cursorSymbol
+			style = highlightStyle
+		} else {
+			item += " "
+		}
+
+		item += " " + roomTitle(room)
+		if room.Archived {
+			item += shadeStyle.Render(" (archived)")
+		}
+		item += shadeStyle.Render(fmt.Sprintf("  %d issue(s)  deck: %s  last used: %s",
+			room.IssuesCount,
+			deckString(room),
+			room.LastUsed.Format(lastUsedLayout),
+		))
+
+		if m.confirmDelete && m.cursor.Match(i) {
+			item += highlightStyle.Render("  press [D] again to delete")
+		}
+
+		items = append(items, style.Render(item))
+	}
+
+	if len(items) == 0 {
+		items = append(items, "- No saved rooms")
+	}
+
+	fullBlock := lipgloss.JoinVertical(lipgloss.Top, items...)
+	return fmt.Sprintf("Saved rooms:\n%s\n", fullBlock)
+}
+
+// Selected returns the room under the cursor.
+func (m *Model) Selected() (storage.RoomInfo, bool) {
+	position := m.cursor.Position()
+	if position < 0 || position >= len(m.rooms) {
+		return storage.RoomInfo{}, false
+	}
+	return m.rooms[position], true
+}
+
+// Delete returns true when deletion of the selected room was confirmed by pressing the key twice.
+func (m *Model) Delete() bool {
+	if m.confirmDelete {
+		m.confirmDelete = false
+		return true
+	}
+	m.confirmDelete = true
+	return false
+}
+
+func roomTitle(room storage.RoomInfo) string {
+	if room.Name == "" {
+		return room.RoomID.String()
+	}
+	return room.Name + shadeStyle.Render(" "+room.RoomID.String())
+}
+
+func deckString(room storage.RoomInfo) string {
+	if len(room.Deck) == 0 {
+		return "-"
+	}
+	values := make([]string, 0, len(room.Deck))
+	for _, card := range room.Deck {
+		values = append(values, string(card.Value))
+	}
+	return strings.Join(values, " ")
+}
@@ -0,0 +1,90 @@
+package roomsview
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/six78/2-story-points-cli/internal/testcommon"
+	"github.com/six78/2-story-points-cli/internal/view/messages"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+	"github.com/six78/2-story-points-cli/pkg/storage"
+)
+
+func TestRoomsView(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+type Suite struct {
+	testcommon.Suite
+}
+
+func (s *Suite) randomRooms(count int) []storage.RoomInfo {
+	rooms := make([]storage.RoomInfo, 0, count)
+	for i := 0; i < count; i++ {
+		rooms = append(rooms, storage.RoomInfo{
+			RoomID:      protocol.NewRoomID(gofakeit.LetterN(10)),
+			Name:        gofakeit.LetterN(6),
+			LastUsed:    time.Now(),
+			IssuesCount: gofakeit.Number(0, 10),
+			Deck:        protocol.DeckFromValues("1", "2", "3"),
+		})
+	}
+	return rooms
+}
+
+func (s *Suite) TestInit() {
+	model := New()
+	s.Require().False(model.loaded)
+	s.Require().Empty(model.rooms)
+	s.Require().Nil(model.Init())
+
+	_, ok := model.Selected()
+	s.Require().False(ok)
+}
+
+func (s *Suite) TestSelect() {
+	rooms := s.randomRooms(3)
+
+	model := New()
+	model = model.Update(messages.SavedRooms{Rooms: rooms})
+	s.Require().True(model.loaded)
+
+	room, ok := model.Selected()
+	s.Require().True(ok)
+	s.Require().Equal(rooms[0], room)
+
+	model = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	room, ok = model.Selected()
+	s.Require().True(ok)
+	s.Require().Equal(rooms[1], room)
+
+	view := model.View()
+	for _, room := range rooms {
+		s.Require().Contains(view, room.Name)
+		s.Require().Contains(view, room.RoomID.String())
+	}
+	s.Require().NotContains(view, "archived")
+
+	rooms[2].Archived = true
+	model = model.Update(messages.SavedRooms{Rooms: rooms})
+	s.Require().Contains(model.View(), "archived")
+}
+
+func (s *Suite) TestDeleteConfirmation() {
+	model := New()
+	model = model.Update(messages.SavedRooms{Rooms: s.randomRooms(2)})
+
+	s.Require().False(model.Delete())
+	s.Require().True(strings.Contains(model.View(), "again to delete"))
+	s.Require().True(model.Delete())
+
+	// Any other key cancels the deletion
+	s.Require().False(model.Delete())
+	model = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	s.Require().False(model.Delete())
+}
@@ -0,0 +1,131 @@
+package roundsview
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/six78/2-story-points-cli/internal/view/messages"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+var (
+	headerStyle       = lipgloss.NewStyle()
+	acceptableStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	unacceptableStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	textStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+)
+
+// round is a voting round of the active issue, as shown in the view.
+type round struct {
+	votes  protocol.IssueVotes
+	hint   *protocol.Hint
+	result *protocol.VoteValue
+}
+
+// Model shows the voting rounds of the active issue with the spread of votes in each round,
+// so that the team can see if the discussion brought the estimations closer.
+// Nothing is shown until the issue is voted again.
+type Model struct {
+	rounds []round
+	deck   protocol.Deck
+}
+
+func New() Model {
+	return Model{}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case messages.GameStateMessage:
+		m.rounds = nil
+		if msg.State == nil {
+			break
+		}
+
+		issue := msg.State.GetActiveIssue()
+		if issue == nil || len(issue.Rounds) == 0 {
+			break
+		}
+
+		for _, r := range issue.Rounds {
+			m.rounds = append(m.rounds, round{votes: r.Votes, hint: r.Hint, result: r.Result})
+		}
+		if msg.State.VotesRevealed {
+			m.rounds = append(m.rounds, round{votes: issue.Votes, hint: issue.Hint, result: issue.Result})
+		}
+
+		m.deck = msg.State.Deck
+	}
+
+	return m, nil
+}
+
+func (m Model) View() string {
+	if len(m.rounds) == 0 {
+		return ""
+	}
+
+	lines := []string{headerStyle.Render("Rounds:")}
+	for i, r := range m.rounds {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, m.renderRound(r)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Top, lines...)
+}
+
+func (m Model) renderRound(r round) string {
+	low, high, ok := votesRange(r.votes, m.deck)
+	if !ok {
+		return textStyle.Render("no votes")
+	}
+
+	votes := fmt.Sprintf("%-9s", string(m.deck[low].Value)+"…"+string(m.deck[high].Value))
+	if low == high {
+		votes = fmt.Sprintf("%-9s", string(m.deck[low].Value))
+	}
+
+	view := votes + " " + textStyle.Render(fmt.Sprintf("spread %d", high-low)) + "  " + renderAcceptanceIcon(r.hint)
+	if r.result != nil {
+		view += textStyle.Render("  result: " + string(*r.result))
+	}
+	return view
+}
+
+// votesRange returns deck indexes of the lowest and the highest votes.
+// Votes for special cards like "?" are skipped, same as in the hint.
+func votesRange(votes protocol.IssueVotes, deck protocol.Deck) (int, int, bool) {
+	low, high := -1, -1
+	for _, vote := range votes {
+		index := deck.Index(vote.Value)
+		if index < 0 {
+			continue
+		}
+		if kind := deck[index].CardKind(); kind == protocol.UncertaintyCardKind || kind == protocol.BreakCardKind {
+			continue
+		}
+		if low < 0 || index < low {
+			low = index
+		}
+		if high < 0 || index > high {
+			high = index
+		}
+	}
+	return low, high, low >= 0
+}
+
+func renderAcceptanceIcon(hint *protocol.Hint) string {
+	switch {
+	case hint == nil:
+		return ""
+	case hint.Acceptable:
+		return acceptableStyle.Render("✓")
+	default:
+		return unacceptableStyle.Render("x")
+	}
+}
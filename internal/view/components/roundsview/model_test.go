@@ -0,0 +1,76 @@
+package roundsview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/six78/2-story-points-cli/internal/view/messages"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+func TestNoRounds(t *testing.T) {
+	model := New()
+	require.Nil(t, model.Init())
+
+	model, cmd := model.Update(messages.GameStateMessage{State: nil})
+	require.Nil(t, cmd)
+	require.Empty(t, model.View())
+
+	issue := protocol.Issue{ID: "1", Votes: protocol.IssueVotes{"a": {Value: "3"}}}
+	model, _ = model.Update(messages.GameStateMessage{
+		State: &protocol.State{
+			Issues:        protocol.IssuesList{&issue},
+			ActiveIssue:   issue.ID,
+			VotesRevealed: true,
+		},
+	})
+	require.Empty(t, model.View())
+}
+
+func TestRounds(t *testing.T) {
+	deck := protocol.DeckFromValues("1", "2", "3", "5", "8", "13", "?")
+	issue := protocol.Issue{
+		ID: "1",
+		Rounds: []protocol.IssueRound{
+			{
+				Votes: protocol.IssueVotes{"a": {Value: "1"}, "b": {Value: "5"}, "c": {Value: "13"}},
+				Hint:  &protocol.Hint{Acceptable: false},
+			},
+			{
+				Votes: protocol.IssueVotes{"a": {Value: "?"}},
+			},
+		},
+		Votes: protocol.IssueVotes{"a": {Value: "3"}, "b": {Value: "3"}, "c": {Value: "5"}},
+		Hint:  &protocol.Hint{Acceptable: true},
+	}
+	state := &protocol.State{
+		Deck:          deck,
+		Issues:        protocol.IssuesList{&issue},
+		ActiveIssue:   issue.ID,
+		VotesRevealed: false,
+	}
+
+	// Current round is only shown when revealed
+	model := New()
+	model, _ = model.Update(messages.GameStateMessage{State: state})
+	lines := strings.Split(model.View(), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, "Rounds:", strings.TrimSpace(lines[0]))
+	require.Equal(t, "1. 1…13      spread 5  x", strings.TrimSpace(lines[1]))
+	require.Equal(t, "2. no votes", strings.TrimSpace(lines[2]))
+
+	state.VotesRevealed = true
+	model, _ = model.Update(messages.GameStateMessage{State: state})
+	lines = strings.Split(model.View(), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, "3. 3…5       spread 1  ✓", strings.TrimSpace(lines[3]))
+
+	// Result of a finished round is shown next to it
+	result := protocol.VoteValue("8")
+	issue.Rounds[0].Result = &result
+	model, _ = model.Update(messages.GameStateMessage{State: state})
+	lines = strings.Split(model.View(), "\n")
+	require.Equal(t, "1. 1…13      spread 5  x  result: 8", strings.TrimSpace(lines[1]))
+}
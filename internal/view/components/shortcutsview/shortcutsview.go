@@ -2,6 +2,7 @@ package shortcutsview
 
 import (
 	"fmt"
+	"strings"
 
 	bubblekey "github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -23,11 +24,15 @@ var (
 )
 
 type Model struct {
+	appState    states.AppState
 	roomView    states.RoomView
 	commandMode bool
 	isDealer    bool
 	inRoom      bool
+	rooms       int // Number of joined rooms
 	voteState   protocol.VoteState
+	playerID    protocol.PlayerID
+	observer    bool // The player doesn't vote
 }
 
 func New() Model {
@@ -36,6 +41,7 @@ func New() Model {
 		commandMode: false,
 		isDealer:    false,
 		inRoom:      false,
+		rooms:       0,
 	}
 }
 
@@ -47,14 +53,24 @@ func (m Model) Update(msg tea.Msg, view states.RoomView) Model {
 	m.roomView = view
 
 	switch msg := msg.(type) {
+	case messages.AppStateMessage:
+		m.appState = msg.State
 	case messages.CommandModeChange:
 		m.commandMode = msg.CommandMode
 	case messages.RoomJoin:
 		m.inRoom = !msg.RoomID.Empty()
 		m.isDealer = msg.IsDealer
+		m.rooms = len(msg.Rooms)
+	case messages.DealerChanged:
+		m.isDealer = msg.IsDealer
+	case messages.PlayerIDMessage:
+		m.playerID = msg.PlayerID
 	case messages.GameStateMessage:
+		m.observer = false
 		if msg.State != nil {
 			m.voteState = msg.State.VoteState()
+			player, found := msg.State.Players.Get(m.playerID)
+			m.observer = found && player.Observer()
 		} else {
 			m.voteState = protocol.IdleState
 		}
@@ -66,6 +82,10 @@ func (m Model) Update(msg tea.Msg, view states.RoomView) Model {
 func (m Model) View() string {
 	keys := commands.DefaultKeyMap
 
+	if m.appState == states.SelectingRoom {
+		return m.savedRoomsView()
+	}
+
 	var rows []string
 
 	if !m.inRoom {
@@ -106,14 +126,27 @@ func (m Model) View() string {
 		case states.ActiveIssueView:
 			switch m.voteState {
 			case protocol.VotingState:
-				row += text(" Vote") + separator2 + keyHelp(keys.RevokeVote)
+				if m.observer {
+					// Observers don't vote, only the dealer keys are left
+					row = strings.TrimSuffix(row, key(keys.SelectCard)) + text("Watching as observer")
+				} else {
+					row += text(" Vote") + separator2 + keyHelp(keys.RevokeVote)
+				}
 			case protocol.RevealedState:
 				row += text(" to save estimation")
+				if m.isDealer {
+					row += separator2 + keyHelp(keys.Revote)
+				}
 			default:
 				row = ""
 			}
 		case states.IssuesListView:
 			row += text(" Deal issue")
+			if m.isDealer {
+				row += separator2 + keyHelp(keys.RemoveIssue) +
+					separator2 + keyHelp(keys.EditIssue) +
+					separator2 + key(keys.MoveIssueUp) + key(keys.MoveIssueDown) + text(" Move issue")
+			}
 		}
 
 		rows = append(rows, row)
@@ -145,6 +178,9 @@ func (m Model) View() string {
 		if m.inRoom {
 			row += separator2 + keyHelp(keys.ExitRoom)
 		}
+		if m.rooms > 1 {
+			row += separator2 + keyHelp(keys.SwitchRoom)
+		}
 
 		rows = append(rows, row)
 	}
@@ -152,6 +188,22 @@ func (m Model) View() string {
 	return lipgloss.JoinVertical(lipgloss.Top, rows...)
 }
 
+func (m Model) savedRoomsView() string {
+	keys := commands.DefaultKeyMap
+
+	row1 := text("Use ") + key(keys.PreviousRoom) +
+		text(" and ") + key(keys.NextRoom) +
+		text(" arrows to select room") + separator2 +
+		keyHelp(keys.ResumeRoom)
+
+	row2 := keyHelp(keys.NewRoom) + separator2 +
+		keyHelp(keys.RenameRoom) + separator2 +
+		keyHelp(keys.DeleteRoom) + separator2 +
+		keyHelp(keys.SkipRooms)
+
+	return lipgloss.JoinVertical(lipgloss.Top, row1, row2)
+}
+
 func key(key bubblekey.Binding) string {
 	s := fmt.Sprintf("[%s]", key.Help().Key)
 	return keyText(s)
@@ -46,6 +46,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		switch msg.State {
 		case states.Playing:
 			m.input.Placeholder = "Type a command..."
+		case states.SelectingRoom:
+			m.input.Placeholder = "Type a new room name..."
 		case states.InputPlayerName:
 			cmd = m.input.Focus()
 			cmds = append(cmds, cmd)
@@ -60,6 +62,14 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 		default:
 		}
+	case messages.SavedRoomRename:
+		cmd = m.input.Focus()
+		cmds = append(cmds, cmd)
+	case messages.CommandInput:
+		m.input.SetValue(msg.Command)
+		m.input.CursorEnd()
+		cmd = m.input.Focus()
+		cmds = append(cmds, cmd)
 	case messages.CommandModeChange:
 		m.commandMode = msg.CommandMode
 		if m.commandMode {
@@ -84,6 +94,13 @@ func (m *Model) SetValue(s string) {
 	m.input.SetValue(s)
 }
 
+// Blur removes the focus, unless the input is kept focused by the command mode.
+func (m *Model) Blur() {
+	if !m.commandMode {
+		m.input.Blur()
+	}
+}
+
 func (m *Model) Focused() bool {
 	return m.input.Focused()
 }
@@ -47,6 +47,9 @@ func (m Model) View() string {
 	}
 
 	text := fmt.Sprintf(" Waku: %2d peer(s)", m.status.PeersCount)
+	if m.status.PeersCount == 0 && m.status.ReconnectAttempt > 0 {
+		text = fmt.Sprintf(" Waku: reconnecting (attempt %d)", m.status.ReconnectAttempt)
+	}
 
 	return lipgloss.JoinHorizontal(lipgloss.Left, marker, text)
 }
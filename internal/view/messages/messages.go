@@ -5,7 +5,9 @@ import (
 
 	"github.com/six78/2-story-points-cli/internal/transport"
 	"github.com/six78/2-story-points-cli/internal/view/states"
+	"github.com/six78/2-story-points-cli/pkg/game"
 	"github.com/six78/2-story-points-cli/pkg/protocol"
+	"github.com/six78/2-story-points-cli/pkg/storage"
 )
 
 type FatalErrorMessage struct {
@@ -51,12 +53,14 @@ type CommandModeChange struct {
 type RoomJoin struct {
 	RoomID   protocol.RoomID
 	IsDealer bool
+	Rooms    []protocol.RoomID // All joined rooms, including the current one
 }
 
 // TODO: Try to find a better solution, probably game.subscribeToMyVote().
 // With this message the logic is duplicated in Game and Model.
 type MyVote struct {
-	Result protocol.VoteResult
+	Result  protocol.VoteResult
+	Pending bool // Vote is not yet confirmed by the dealer
 }
 
 type EnableEnterKey struct {
@@ -68,3 +72,24 @@ type AutoRevealScheduled struct {
 
 type AutoRevealCancelled struct {
 }
+
+type DealerChanged struct {
+	IsDealer bool
+}
+
+type VoteDelivery struct {
+	Delivery game.VoteDelivery
+}
+
+type SavedRooms struct {
+	Rooms []storage.RoomInfo
+}
+
+// SavedRoomRename requests input of a new name for the selected saved room.
+type SavedRoomRename struct {
+}
+
+// CommandInput requests the input prefilled with given command, e.g. to edit an issue.
+type CommandInput struct {
+	Command string
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/six78/2-story-points-cli/internal/config"
 	"github.com/six78/2-story-points-cli/internal/transport"
 	"github.com/six78/2-story-points-cli/internal/view/commands"
+	"github.com/six78/2-story-points-cli/internal/view/components/countdownview"
 	"github.com/six78/2-story-points-cli/internal/view/components/deckview"
 	"github.com/six78/2-story-points-cli/internal/view/components/errorview"
 	"github.com/six78/2-story-points-cli/internal/view/components/eventhandler"
@@ -23,6 +24,8 @@ import (
 	"github.com/six78/2-story-points-cli/internal/view/components/issuesview"
 	"github.com/six78/2-story-points-cli/internal/view/components/issueview"
 	"github.com/six78/2-story-points-cli/internal/view/components/playersview"
+	"github.com/six78/2-story-points-cli/internal/view/components/roomsview"
+	"github.com/six78/2-story-points-cli/internal/view/components/roundsview"
 	"github.com/six78/2-story-points-cli/internal/view/components/shortcutsview"
 	"github.com/six78/2-story-points-cli/internal/view/components/userinput"
 	"github.com/six78/2-story-points-cli/internal/view/components/votestate"
@@ -44,6 +47,7 @@ type model struct {
 	fatalError       error
 	gameState        *protocol.State
 	roomID           protocol.RoomID
+	rooms            []protocol.RoomID
 	connectionStatus transport.ConnectionStatus
 
 	// UI components state
@@ -52,12 +56,15 @@ type model struct {
 	errorView      errorview.Model
 	playersView    playersview.Model
 	hintView       hintview.Model
+	roundsView     roundsview.Model
+	countdownView  countdownview.Model
 	shortcutsView  shortcutsview.Model
 	wakuStatusView wakustatusview.Model
 	deckView       deckview.Model
 	issueView      issueview.Model
 	issuesListView issuesview.Model
 	voteStateView  votestate.Model
+	roomsView      roomsview.Model
 
 	gameEventHandler      eventhandler.Model[game.Event, interface{}]
 	transportEventHandler eventhandler.Model[transport.ConnectionStatus, messages.ConnectionStatus]
@@ -93,12 +100,15 @@ func InitialModel(game *game.Game, transport transport.Service) model {
 		errorView:      errorview.New(),
 		playersView:    playersview.New(),
 		hintView:       hintview.New(),
+		roundsView:     roundsview.New(),
+		countdownView:  countdownview.New(),
 		shortcutsView:  shortcutsview.New(),
 		wakuStatusView: wakustatusview.New(),
 		deckView:       deckView,
 		issueView:      issueview.New(),
 		issuesListView: issuesview.New(),
 		voteStateView:  votestate.Model{},
+		roomsView:      roomsview.New(),
 		// Other
 		disableEnterKey:     false,
 		disableEnterRestart: nil,
@@ -119,12 +129,15 @@ func (m model) Init() tea.Cmd {
 		m.errorView.Init(),
 		m.playersView.Init(),
 		m.hintView.Init(),
+		m.roundsView.Init(),
+		m.countdownView.Init(),
 		m.shortcutsView.Init(),
 		m.wakuStatusView.Init(),
 		m.deckView.Init(),
 		m.issueView.Init(),
 		m.issuesListView.Init(),
 		m.voteStateView.Init(),
+		m.roomsView.Init(),
 		commands.InitializeApp(m.game, m.transport),
 	)
 }
@@ -167,12 +180,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switchToState(states.WaitingForPeers)
 
 		case states.WaitingForPeers:
-			switchToState(states.Playing)
 			if config.InitialAction() != "" {
+				switchToState(states.Playing)
 				m.input.SetValue(config.InitialAction())
 				cmd := ProcessInput(&m)
 				cmds.AppendCommand(cmd)
+				break
 			}
+			// Offer to resume one of the saved rooms
+			switchToState(states.SelectingRoom)
+			cmds.AppendCommand(commands.LoadSavedRooms(m.game))
+
+		case states.SelectingRoom:
+			switchToState(states.Playing)
+
 		case states.Playing:
 			break
 		}
@@ -193,20 +214,31 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case messages.GameStateMessage:
-		if m.gameState != nil && msg.State != nil && msg.State.ActiveIssue != m.gameState.ActiveIssue {
-			cmds.AppendMessage(messages.MyVote{Result: m.game.MyVote()})
-		}
+		// Also updates the pending mark, as the state may confirm the vote
+		cmds.AppendMessage(messages.MyVote{Result: m.game.MyVote(), Pending: m.game.VotePending()})
 		m.gameState = msg.State
 
 	case messages.CommandModeChange:
 		m.commandMode = msg.CommandMode
 
+	case messages.SavedRooms:
+		if m.state != states.SelectingRoom {
+			break
+		}
+		// Skip the list when there's nothing to resume
+		if len(msg.Rooms) == 0 {
+			cmds.AppendMessage(messages.AppStateFinishedMessage{State: states.SelectingRoom})
+		}
+		m.input.Blur()
+
 	case messages.RoomJoin:
 		m.roomID = msg.RoomID
+		m.rooms = msg.Rooms
 		config.Logger.Debug("room joined",
 			zap.String("roomID", msg.RoomID.String()),
 			zap.Bool("isDealer", msg.IsDealer))
-		cmds.AppendMessage(messages.MyVote{Result: m.game.MyVote()})
+		cmds.AppendMessage(messages.MyVote{Result: m.game.MyVote(), Pending: m.game.VotePending()})
+		cmds.AppendMessage(messages.VoteDelivery{Delivery: m.game.VoteDelivery()})
 
 	case messages.EnableEnterKey:
 		m.disableEnterKey = false
@@ -224,6 +256,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.input.Focused() {
 				cmd = ProcessUserInput(&m)
 				cmds.AppendCommand(cmd)
+				if m.state == states.Playing {
+					// Leave the one-off input, e.g. issue editing. Kept in the command mode.
+					m.input.Blur()
+				}
 				break
 			}
 			if m.gameState == nil {
@@ -233,7 +269,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case states.ActiveIssueView:
 				// FIXME: https://github.com/six78/2-story-points-cli/issues/8
 				//		  Check `m.gameState == nil`
-				if m.gameState.VoteState() == protocol.VotingState {
+				if m.gameState.VoteState() == protocol.VotingState && !m.game.IsObserver() {
 					cmd = VoteOnCursor(&m)
 				} else if m.gameState.VoteState() == protocol.RevealedState {
 					cmd = FinishOnCursor(&m)
@@ -255,6 +291,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		if m.input.Focused() {
+			if msg.Type == tea.KeyEsc && (m.state == states.SelectingRoom || m.state == states.Playing && !m.commandMode) {
+				m.input.Reset()
+				m.input.Blur()
+			}
+			break
+		}
+
+		if m.state == states.SelectingRoom {
+			cmds.AppendCommand(m.handleSavedRoomsKey(msg))
 			break
 		}
 
@@ -266,8 +311,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds.AppendCommand(runRevealAction(&m, nil))
 			case key.Matches(msg, commands.DefaultKeyMap.FinishVote):
 				cmds.AppendCommand(runFinishAction(&m, nil))
+			case key.Matches(msg, commands.DefaultKeyMap.Revote):
+				cmds.AppendCommand(runRevoteAction(&m, nil))
 			case key.Matches(msg, commands.DefaultKeyMap.RevokeVote):
 				cmds.AppendCommand(commands.PublishVote(m.game, ""))
+			case key.Matches(msg, commands.DefaultKeyMap.SwitchRoom):
+				if len(m.rooms) > 1 {
+					cmds.AppendCommand(runSwitchAction(&m, nil))
+				}
+			}
+			if m.roomViewState == states.IssuesListView {
+				cmds.AppendCommand(m.handleIssuesListKey(msg))
 			}
 		} else {
 			switch {
@@ -286,12 +340,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.errorView = m.errorView.Update(msg)
 	m.playersView, cmds.PlayersCommand = m.playersView.Update(msg)
 	m.hintView, _ = m.hintView.Update(msg)
+	m.roundsView, _ = m.roundsView.Update(msg)
+	m.countdownView, _ = m.countdownView.Update(msg)
 	m.shortcutsView = m.shortcutsView.Update(msg, m.roomViewState)
 	m.wakuStatusView = m.wakuStatusView.Update(msg)
 	m.deckView = m.deckView.Update(msg)
 	m.issueView, cmds.IssueViewCommand = m.issueView.Update(msg)
 	m.issuesListView, cmds.IssuesListViewCommand = m.issuesListView.Update(msg)
 	m.voteStateView, _ = m.voteStateView.Update(msg)
+	m.roomsView = m.roomsView.Update(msg)
 	m.gameEventHandler, cmds.GameEventHandlerCommand = m.gameEventHandler.Update(msg)
 	m.transportEventHandler, cmds.TransportEventHandlerCommand = m.transportEventHandler.Update(msg)
 
@@ -312,6 +369,73 @@ func (m model) View() string {
 	return lipgloss.JoinHorizontal(lipgloss.Left, "  ", view)
 }
 
+func (m *model) handleSavedRoomsKey(msg tea.KeyMsg) tea.Cmd {
+	finish := func() tea.Msg {
+		return messages.AppStateFinishedMessage{State: states.SelectingRoom}
+	}
+
+	switch {
+	case key.Matches(msg, commands.DefaultKeyMap.ResumeRoom):
+		room, ok := m.roomsView.Selected()
+		if !ok {
+			return nil
+		}
+		if room.Archived {
+			return tea.Batch(finish, commands.OpenArchivedRoom(m.game, room.RoomID))
+		}
+		return tea.Batch(finish, commands.JoinRoom(m.game, room.RoomID))
+	case key.Matches(msg, commands.DefaultKeyMap.NewRoom):
+		return tea.Batch(finish, runNewAction(m, nil))
+	case key.Matches(msg, commands.DefaultKeyMap.RenameRoom):
+		if room, ok := m.roomsView.Selected(); ok && !room.Archived {
+			return func() tea.Msg {
+				return messages.SavedRoomRename{}
+			}
+		}
+	case key.Matches(msg, commands.DefaultKeyMap.DeleteRoom):
+		room, ok := m.roomsView.Selected()
+		if ok && !room.Archived && m.roomsView.Delete() {
+			return commands.DeleteSavedRoom(m.game, room.RoomID)
+		}
+	case key.Matches(msg, commands.DefaultKeyMap.SkipRooms):
+		return finish
+	}
+	return nil
+}
+
+// handleIssuesListKey handles dealer keys for the issue under the cursor.
+func (m *model) handleIssuesListKey(msg tea.KeyMsg) tea.Cmd {
+	if m.gameState == nil || !m.game.IsDealer() {
+		return nil
+	}
+
+	index := m.issuesListView.CursorPosition()
+	if index < 0 || index >= len(m.gameState.Issues) {
+		return nil
+	}
+
+	switch {
+	case key.Matches(msg, commands.DefaultKeyMap.RemoveIssue):
+		return commands.RemoveIssue(m.game, index)
+	case key.Matches(msg, commands.DefaultKeyMap.EditIssue):
+		command := fmt.Sprintf("%s %d %s", Edit, index, m.gameState.Issues[index].TitleOrURL)
+		return func() tea.Msg {
+			return messages.CommandInput{Command: command}
+		}
+	case key.Matches(msg, commands.DefaultKeyMap.MoveIssueUp):
+		if index > 0 {
+			m.issuesListView.SetCursorPosition(index - 1)
+			return commands.MoveIssue(m.game, index, index-1)
+		}
+	case key.Matches(msg, commands.DefaultKeyMap.MoveIssueDown):
+		if index < len(m.gameState.Issues)-1 {
+			m.issuesListView.SetCursorPosition(index + 1)
+			return commands.MoveIssue(m.game, index, index+1)
+		}
+	}
+	return nil
+}
+
 func VoteOnCursor(m *model) tea.Cmd {
 	return cursorCommand(m, m.deckView.VoteCursor(), commands.PublishVote)
 }
@@ -327,7 +451,7 @@ func cursorCommand(m *model, cursor int, command func(*game.Game, protocol.VoteV
 	if cursor < 0 || cursor > len(m.gameState.Deck) {
 		return nil
 	}
-	cursorValue := m.gameState.Deck[cursor]
+	cursorValue := m.gameState.Deck[cursor].Value
 	return command(m.game, cursorValue)
 }
 
@@ -412,6 +536,14 @@ func gameEventToMessage(event game.Event) interface{} {
 		}
 	case game.EventAutoRevealCancelled:
 		return messages.AutoRevealCancelled{}
+	case game.EventDealerChanged:
+		if isDealer, ok := event.Data.(bool); ok {
+			return messages.DealerChanged{IsDealer: isDealer}
+		}
+	case game.EventVoteDelivery:
+		if delivery, ok := event.Data.(game.VoteDelivery); ok {
+			return messages.VoteDelivery{Delivery: delivery}
+		}
 	default:
 		return nil
 	}
@@ -5,9 +5,11 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/exp/slices"
 
 	"github.com/six78/2-story-points-cli/internal/config"
 	"github.com/six78/2-story-points-cli/internal/view/states"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
 )
 
 /*
@@ -31,6 +33,8 @@ func (m model) renderAppState() string {
 		return m.renderPlayerNameInput()
 	case states.WaitingForPeers:
 		return m.spinner.View() + " Connecting to Waku peers..."
+	case states.SelectingRoom:
+		return m.renderSavedRooms()
 	case states.Playing:
 		return m.renderGame()
 	}
@@ -46,6 +50,13 @@ func (m model) renderPlayerNameInput() string {
 	)
 }
 
+func (m model) renderSavedRooms() string {
+	return lipgloss.JoinVertical(lipgloss.Top,
+		m.roomsView.View(),
+		m.renderActionInput(),
+		m.errorView.View())
+}
+
 func (m model) renderGame() string {
 	roomViewSeparator := ""
 	if !m.roomID.Empty() {
@@ -69,8 +80,17 @@ func (m model) renderRoomID() string {
 	var dealerString string
 	if m.game.IsDealer() {
 		dealerString = foregroundShadeStyle.Render(" (dealer)")
+	} else if m.game.ReadOnly() {
+		dealerString = foregroundShadeStyle.Render(" (archived, read-only)")
+	} else if m.game.StateArchived() {
+		dealerString = foregroundShadeStyle.Render(" (archived, waiting for dealer)")
+	}
+	var roomsString string
+	if len(m.rooms) > 1 {
+		index := slices.Index(m.rooms, m.roomID)
+		roomsString = foregroundShadeStyle.Render(fmt.Sprintf(" [%d/%d]", index+1, len(m.rooms)))
 	}
-	return "Room: " + m.roomID.String() + dealerString
+	return "Room: " + m.roomID.String() + dealerString + roomsString
 }
 
 func (m model) renderRoomView() string {
@@ -98,18 +118,32 @@ func (m model) renderRoomCurrentIssueView() string {
 	playersView := m.playersView.View()
 	if m.gameState.VotesRevealed {
 		playersView = lipgloss.JoinHorizontal(lipgloss.Center, playersView, "  ", m.hintView.View())
-	} else if m.game.IsDealer() && m.gameState.AllPlayersVoted() {
-		playersView = lipgloss.JoinHorizontal(0.75, playersView, "  ", m.voteStateView.View())
+	} else if voteStateView := m.voteStateView.View(); m.game.IsDealer() && voteStateView != "" {
+		// Auto reveal is scheduled by the room policy, not necessarily when all players voted
+		playersView = lipgloss.JoinHorizontal(0.75, playersView, "  ", voteStateView)
+	}
+	if roundsView := m.roundsView.View(); roundsView != "" {
+		playersView = lipgloss.JoinHorizontal(lipgloss.Center, playersView, "    ", roundsView)
 	}
 
+	// Countdown takes the empty line between the issue and the players
 	return lipgloss.JoinVertical(lipgloss.Top,
 		m.issueView.View(),
-		"",
+		m.countdownView.View(),
 		playersView,
-		m.deckView.View(),
+		m.renderDeck(),
 	)
 }
 
+// renderDeck hides the deck from observers, as they don't vote.
+// Observing dealer still needs the deck to pick the result of revealed votes.
+func (m model) renderDeck() string {
+	if m.game.IsObserver() && !(m.game.IsDealer() && m.gameState.VoteState() == protocol.RevealedState) {
+		return ""
+	}
+	return m.deckView.View()
+}
+
 func (m model) renderActionInput() string {
 	if m.commandMode {
 		return m.input.View()
@@ -7,6 +7,7 @@ const (
 	Initializing
 	InputPlayerName
 	WaitingForPeers
+	SelectingRoom
 	Playing
 )
 
@@ -0,0 +1,122 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+var AvailableAutoRevealPolicies = []protocol.AutoRevealPolicy{
+	protocol.AutoRevealOff,
+	protocol.AutoRevealAllVoted,
+	protocol.AutoRevealQuorum,
+	protocol.AutoRevealAllOnlineVoted,
+}
+
+var (
+	ErrUnknownAutoRevealPolicy = errors.New("unknown auto reveal policy")
+	ErrInvalidAutoRevealQuorum = errors.New("auto reveal quorum must be between 1 and 100 percent")
+)
+
+// ValidateAutoRevealSettings checks that the settings can be used in a room.
+// Empty settings are valid and mean defaults of the dealer.
+func ValidateAutoRevealSettings(settings *protocol.AutoRevealSettings) error {
+	if settings.Empty() {
+		return nil
+	}
+	switch settings.Policy {
+	case "", protocol.AutoRevealOff, protocol.AutoRevealAllVoted, protocol.AutoRevealAllOnlineVoted:
+		if settings.Quorum != 0 {
+			return errors.New("auto reveal quorum is only used with quorum policy")
+		}
+	case protocol.AutoRevealQuorum:
+		if settings.Quorum < 1 || settings.Quorum > 100 {
+			return ErrInvalidAutoRevealQuorum
+		}
+	default:
+		return errors.Wrap(ErrUnknownAutoRevealPolicy, string(settings.Policy))
+	}
+	if settings.Delay != nil && *settings.Delay < 0 {
+		return errors.New("auto reveal delay can't be negative")
+	}
+	return nil
+}
+
+// autoRevealSettings returns the auto reveal settings of the room, completed with defaults of this client.
+func (g *Game) autoRevealSettings() protocol.AutoRevealSettings {
+	delay := g.config.AutoRevealDelay.Milliseconds()
+	settings := protocol.AutoRevealSettings{
+		Policy: protocol.AutoRevealOff,
+		Delay:  &delay,
+	}
+	if g.config.AutoRevealEnabled {
+		settings.Policy = protocol.AutoRevealAllVoted
+	}
+	if g.state == nil || g.state.AutoReveal.Empty() {
+		return settings
+	}
+	if g.state.AutoReveal.Policy != "" {
+		settings.Policy = g.state.AutoReveal.Policy
+		settings.Quorum = g.state.AutoReveal.Quorum
+	}
+	if g.state.AutoReveal.Delay != nil {
+		delay = *g.state.AutoReveal.Delay
+	}
+	return settings
+}
+
+// autoRevealReady tells if votes of the active issue should be revealed according to the policy.
+func autoRevealReady(state *protocol.State, settings protocol.AutoRevealSettings) bool {
+	if state.VoteState() != protocol.VotingState {
+		return false
+	}
+	issue := state.GetActiveIssue()
+	if issue == nil || len(issue.Votes) == 0 {
+		return false
+	}
+
+	switch settings.Policy {
+	case protocol.AutoRevealAllVoted:
+		return state.AllPlayersVoted()
+	case protocol.AutoRevealQuorum:
+		voters := state.Players.Voters()
+		required := int(math.Ceil(float64(len(voters)*settings.Quorum) / 100))
+		voted := 0
+		for _, player := range voters {
+			if _, ok := issue.Votes[player.ID]; ok {
+				voted++
+			}
+		}
+		return voted >= max(required, 1)
+	case protocol.AutoRevealAllOnlineVoted:
+		for _, player := range state.Players.Voters() {
+			if _, voted := issue.Votes[player.ID]; player.Online && !voted {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// updateAutoReveal schedules the auto reveal when the policy is met and cancels it otherwise.
+// Already scheduled reveal is kept, so that new votes don't postpone it.
+func (g *Game) updateAutoReveal() {
+	settings := g.autoRevealSettings()
+	if g.state == nil || !autoRevealReady(g.state, settings) {
+		g.cancelAutoReveal()
+		return
+	}
+
+	g.revealTimerLock.Lock()
+	scheduled := g.revealTimer != nil
+	g.revealTimerLock.Unlock()
+
+	if !scheduled {
+		g.scheduleAutoReveal(time.Duration(*settings.Delay) * time.Millisecond)
+	}
+}
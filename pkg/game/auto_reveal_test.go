@@ -0,0 +1,73 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+func TestAutoRevealReady(t *testing.T) {
+	issue := &protocol.Issue{ID: "1", Votes: protocol.IssueVotes{}}
+	state := &protocol.State{
+		Players: protocol.PlayersList{
+			{ID: "a", Online: true},
+			{ID: "b", Online: true},
+			{ID: "c", Online: true},
+			{ID: "d", Online: false},
+			{ID: "e", Online: true, Role: protocol.ObserverRole}, // Never waited for
+		},
+		Issues:      protocol.IssuesList{issue},
+		ActiveIssue: issue.ID,
+	}
+
+	allVoted := protocol.AutoRevealSettings{Policy: protocol.AutoRevealAllVoted}
+	allOnlineVoted := protocol.AutoRevealSettings{Policy: protocol.AutoRevealAllOnlineVoted}
+	quorum := protocol.AutoRevealSettings{Policy: protocol.AutoRevealQuorum, Quorum: 50}
+	off := protocol.AutoRevealSettings{Policy: protocol.AutoRevealOff}
+
+	// Nothing is revealed without votes
+	for _, settings := range []protocol.AutoRevealSettings{allVoted, allOnlineVoted, quorum, off} {
+		require.False(t, autoRevealReady(state, settings), settings.Policy)
+	}
+
+	issue.Votes["a"] = *protocol.NewVoteResult("1")
+	require.False(t, autoRevealReady(state, quorum))
+
+	issue.Votes["b"] = *protocol.NewVoteResult("1")
+	require.True(t, autoRevealReady(state, quorum))
+	require.False(t, autoRevealReady(state, allOnlineVoted))
+
+	issue.Votes["c"] = *protocol.NewVoteResult("1")
+	require.True(t, autoRevealReady(state, allOnlineVoted))
+	require.False(t, autoRevealReady(state, allVoted))
+
+	issue.Votes["d"] = *protocol.NewVoteResult("1")
+	require.True(t, autoRevealReady(state, allVoted))
+	require.False(t, autoRevealReady(state, off))
+
+	// Revealed votes are not revealed again
+	state.VotesRevealed = true
+	require.False(t, autoRevealReady(state, allVoted))
+}
+
+func TestValidateAutoRevealSettings(t *testing.T) {
+	valid := []protocol.AutoRevealSettings{
+		{},
+		{Policy: protocol.AutoRevealOff},
+		{Policy: protocol.AutoRevealAllOnlineVoted, Delay: ptr[int64](3000)},
+		{Policy: protocol.AutoRevealQuorum, Quorum: 100},
+		{Delay: ptr[int64](500)},
+		{Delay: ptr[int64](0)},
+	}
+	for _, settings := range valid {
+		require.NoError(t, ValidateAutoRevealSettings(&settings), settings)
+	}
+
+	require.ErrorIs(t, ValidateAutoRevealSettings(&protocol.AutoRevealSettings{Policy: "unknown"}), ErrUnknownAutoRevealPolicy)
+	require.ErrorIs(t, ValidateAutoRevealSettings(&protocol.AutoRevealSettings{Policy: protocol.AutoRevealQuorum}), ErrInvalidAutoRevealQuorum)
+	require.ErrorIs(t, ValidateAutoRevealSettings(&protocol.AutoRevealSettings{Policy: protocol.AutoRevealQuorum, Quorum: 101}), ErrInvalidAutoRevealQuorum)
+	require.Error(t, ValidateAutoRevealSettings(&protocol.AutoRevealSettings{Policy: protocol.AutoRevealAllVoted, Quorum: 50}))
+	require.Error(t, ValidateAutoRevealSettings(&protocol.AutoRevealSettings{Delay: ptr[int64](-1)}))
+}
@@ -1,25 +1,33 @@
 package game
 
-import "time"
+import (
+	"time"
+
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
 
 type configuration struct {
 	PlayerName                string
+	PlayerRole                protocol.PlayerRole
 	EnableSymmetricEncryption bool
 	OnlineMessagePeriod       time.Duration
 	StateMessagePeriod        time.Duration
 	PublishStateLoopEnabled   bool
 	AutoRevealEnabled         bool
 	AutoRevealDelay           time.Duration
+	Encoding                  protocol.Encoding
 }
 
 func defaultConfig() configuration {
 	return configuration{
 		PlayerName:                "",
+		PlayerRole:                protocol.VoterRole,
 		EnableSymmetricEncryption: true,
 		OnlineMessagePeriod:       5 * time.Second,
 		StateMessagePeriod:        30 * time.Second,
 		PublishStateLoopEnabled:   true,
 		AutoRevealEnabled:         true,
 		AutoRevealDelay:           1 * time.Second,
+		Encoding:                  protocol.EncodingJSON,
 	}
 }
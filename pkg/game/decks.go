@@ -1,38 +1,162 @@
 package game
 
 import (
-	"github.com/six78/2-story-points-cli/pkg/protocol"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/six78/2-story-points-cli/pkg/protocol"
 )
 
 const (
-	DefaultDeck   = FibonacciDeck
-	FibonacciDeck = "fibonacci"
-	PriorityDeck  = "priority"
+	DefaultDeck           = FibonacciDeck
+	FibonacciDeck         = "fibonacci"
+	ModifiedFibonacciDeck = "modified-fibonacci"
+	PriorityDeck          = "priority"
+	TShirtDeck            = "tshirt"
+	PowersOfTwoDeck       = "powers-of-two"
+	HoursDeck             = "hours"
 )
 
-//var TShirtDeck = []protocol.VoteResult{
-//	"XS", "S", "M", "L", "XL", "XXL",
-//}
+var builtinDecks = map[string]protocol.Deck{
+	FibonacciDeck:         protocol.DeckFromValues("1", "2", "3", "5", "8", "13", "21", "?"),
+	ModifiedFibonacciDeck: protocol.DeckFromValues("0", "0.5", "1", "2", "3", "5", "8", "13", "20", "40", "100", "?", "☕"),
+	PriorityDeck:          protocol.DeckFromValues("4", "3", "2", "1", "0", "?"),
+	TShirtDeck: {
+		numericCard("XS", 1, "Extra small"),
+		numericCard("S", 2, "Small"),
+		numericCard("M", 3, "Medium"),
+		numericCard("L", 5, "Large"),
+		numericCard("XL", 8, "Extra large"),
+		numericCard("XXL", 13, "Huge"),
+		{Value: protocol.UncertaintyCard},
+	},
+	PowersOfTwoDeck: protocol.DeckFromValues("1", "2", "4", "8", "16", "32", "64", "?"),
+	HoursDeck: {
+		numericCard("1h", 1, ""),
+		numericCard("2h", 2, ""),
+		numericCard("4h", 4, ""),
+		numericCard("8h", 8, "One day"),
+		numericCard("16h", 16, "Two days"),
+		numericCard("24h", 24, "Three days"),
+		numericCard("40h", 40, "One week"),
+		{Value: protocol.UncertaintyCard},
+	},
+}
 
-var decks = map[string]protocol.Deck{
-	FibonacciDeck: {"1", "2", "3", "5", "8", "13", "21", "?"},
-	PriorityDeck:  {"4", "3", "2", "1", "0", "?"},
+func numericCard(value protocol.VoteValue, number float64, label string) protocol.Card {
+	return protocol.Card{Value: value, Number: &number, Label: label}
 }
 
+// userDecks are the named decks loaded from the decks file, see LoadDecks.
+var (
+	userDecks     = map[string]protocol.Deck{}
+	userDecksLock sync.RWMutex
+)
+
+// GetDeck returns a user or built-in deck by name.
+// User decks take precedence, so that a built-in deck can be redefined.
 func GetDeck(deckName string) (protocol.Deck, bool) {
-	deck, ok := decks[deckName]
+	userDecksLock.RLock()
+	defer userDecksLock.RUnlock()
+
+	if deck, ok := userDecks[deckName]; ok {
+		return deck, true
+	}
+	deck, ok := builtinDecks[deckName]
 	return deck, ok
 }
 
+// AvailableDecks returns sorted names of all built-in and user decks.
 func AvailableDecks() []string {
-	return maps.Keys(decks)
+	userDecksLock.RLock()
+	defer userDecksLock.RUnlock()
+
+	names := maps.Keys(builtinDecks)
+	for name := range userDecks {
+		if _, ok := builtinDecks[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LoadDecks loads user decks from a JSON file, which maps deck names to lists of cards.
+// Cards are either plain values or objects with metadata:
+//
+//	{"team": ["1", "2", "3", "?"], "sizes": [{"value": "S", "number": 1}, {"value": "L", "number": 3}]}
+//
+// A missing file is not an error. Loaded decks replace the previously loaded ones.
+func LoadDecks(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read decks file")
+	}
+
+	var loaded map[string]protocol.Deck
+	err = json.Unmarshal(data, &loaded)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse decks file")
+	}
+
+	decks := make(map[string]protocol.Deck, len(loaded))
+	for name, deck := range loaded {
+		err = validateDeck(deck)
+		if err != nil {
+			return errors.Wrapf(err, "invalid deck '%s'", name)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return errors.Errorf("invalid deck name '%s'", name)
+		}
+		decks[name] = deck
+	}
+
+	userDecksLock.Lock()
+	defer userDecksLock.Unlock()
+	userDecks = decks
+
+	return nil
+}
+
+// NewDeck creates a deck from given cards, which must be non-empty and unique.
+func NewDeck(values []string) (protocol.Deck, error) {
+	deck := CreateDeck(values)
+	err := validateDeck(deck)
+	if err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+func validateDeck(deck protocol.Deck) error {
+	if len(deck) == 0 {
+		return errors.New("deck can't be empty")
+	}
+	for i, card := range deck {
+		if card.Value == "" {
+			return errors.New("card can't be empty")
+		}
+		if deck.Index(card.Value) != i {
+			return errors.Errorf("duplicate card: '%s'", card.Value)
+		}
+	}
+	return nil
 }
 
 func CreateDeck(votes []string) protocol.Deck {
 	result := protocol.Deck{}
 	for _, value := range votes {
-		result = append(result, protocol.VoteValue(value))
+		result = append(result, protocol.Card{Value: protocol.VoteValue(value)})
 	}
 	return result
 }
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+func TestLoadDecks(t *testing.T) {
+	t.Cleanup(func() {
+		userDecks = map[string]protocol.Deck{}
+	})
+
+	path := filepath.Join(t.TempDir(), "decks.json")
+
+	// Missing file is fine
+	err := LoadDecks(path)
+	require.NoError(t, err)
+	require.Len(t, AvailableDecks(), len(builtinDecks))
+
+	err = os.WriteFile(path, []byte(`{"Team": ["1", "2", "?"], "tshirt": ["S", "M", "L"]}`), 0600)
+	require.NoError(t, err)
+	err = LoadDecks(path)
+	require.NoError(t, err)
+
+	deck, ok := GetDeck("team")
+	require.True(t, ok)
+	require.Equal(t, protocol.DeckFromValues("1", "2", "?"), deck)
+
+	// User deck replaces the built-in one
+	deck, ok = GetDeck(TShirtDeck)
+	require.True(t, ok)
+	require.Equal(t, protocol.DeckFromValues("S", "M", "L"), deck)
+
+	names := AvailableDecks()
+	require.Len(t, names, len(builtinDecks)+1)
+	require.True(t, slices.IsSorted(names))
+	require.Contains(t, names, "team")
+
+	// Invalid decks are rejected
+	for _, content := range []string{
+		`not a json`,
+		`{"empty": []}`,
+		`{"duplicate": ["1", "1"]}`,
+		`{"": ["1", "2"]}`,
+		`{"two words": ["1", "2"]}`,
+	} {
+		err = os.WriteFile(path, []byte(content), 0600)
+		require.NoError(t, err)
+		err = LoadDecks(path)
+		require.Error(t, err, content)
+	}
+
+	// Previously loaded decks are kept on error
+	_, ok = GetDeck("team")
+	require.True(t, ok)
+}
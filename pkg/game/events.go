@@ -1,11 +1,19 @@
 package game
 
+import (
+	"sync"
+
+	"golang.org/x/exp/slices"
+)
+
 type EventTag int
 
 const (
 	EventStateChanged EventTag = iota
 	EventAutoRevealScheduled
 	EventAutoRevealCancelled
+	EventDealerChanged
+	EventVoteDelivery
 )
 
 type Event struct {
@@ -14,7 +22,8 @@ type Event struct {
 }
 
 type Subscription struct {
-	Events chan Event
+	Events      chan Event
+	nonBlocking bool // Subscription is closed instead of blocking the sender when the channel is full
 }
 
 type EventPublisher interface {
@@ -26,7 +35,10 @@ type EventSubscriber interface {
 }
 
 type EventManager struct {
+	lock          sync.Mutex
 	subscriptions []*Subscription
+	// Held for reading while sending events, so that channels are never closed during the send
+	sendLock sync.RWMutex
 }
 
 func NewEventManager() *EventManager {
@@ -35,27 +47,133 @@ func NewEventManager() *EventManager {
 	}
 }
 
+// Send sends the event to all subscribers.
+// Subscribers are copied, so that a slow subscriber doesn't block subscribing or unsubscribing others.
 func (m *EventManager) Send(event Event) {
-	for _, sub := range m.subscriptions {
-		sub.Events <- event
+	m.lock.Lock()
+	subscriptions := slices.Clone(m.subscriptions)
+	m.lock.Unlock()
+
+	slow := make([]*Subscription, 0)
+
+	m.sendLock.RLock()
+	for _, sub := range subscriptions {
+		if !sub.nonBlocking {
+			sub.Events <- event
+			continue
+		}
+		select {
+		case sub.Events <- event:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	m.sendLock.RUnlock()
+
+	for _, sub := range slow {
+		m.Unsubscribe(sub)
 	}
 }
 
 func (m *EventManager) Subscribe() *Subscription {
+	return m.subscribe(false)
+}
+
+// SubscribeNonBlocking subscribes to events without ever blocking the sender, e.g. for remote clients.
+// When the subscriber doesn't keep up with the events, the subscription is closed.
+func (m *EventManager) SubscribeNonBlocking() *Subscription {
+	return m.subscribe(true)
+}
+
+func (m *EventManager) subscribe(nonBlocking bool) *Subscription {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	subscription := &Subscription{
-		Events: make(chan Event, 10),
+		Events:      make(chan Event, 10),
+		nonBlocking: nonBlocking,
 	}
 	m.subscriptions = append(m.subscriptions, subscription)
 	return subscription
 }
 
+// Unsubscribe removes the subscription and closes its channel.
+// Send blocks on a full channel of a blocking subscription,
+// so the subscriber must keep reading the channel until it's closed.
+func (m *EventManager) Unsubscribe(subscription *Subscription) {
+	m.lock.Lock()
+	index := slices.Index(m.subscriptions, subscription)
+	if index < 0 {
+		m.lock.Unlock()
+		return
+	}
+	m.subscriptions = slices.Delete(m.subscriptions, index, index+1)
+	m.lock.Unlock()
+
+	m.sendLock.Lock()
+	defer m.sendLock.Unlock()
+	close(subscription.Events)
+}
+
 func (m *EventManager) Count() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	return len(m.subscriptions)
 }
 
 func (m *EventManager) Close() {
-	for _, sub := range m.subscriptions {
+	m.lock.Lock()
+	subscriptions := m.subscriptions
+	m.subscriptions = nil
+	m.lock.Unlock()
+
+	m.sendLock.Lock()
+	defer m.sendLock.Unlock()
+	for _, sub := range subscriptions {
 		close(sub.Events)
 	}
-	m.subscriptions = nil
+}
+
+// eventQueue keeps the order of events sent by the game, without blocking the sender.
+// The game state is locked while events are queued, so a slow subscriber must never block it.
+type eventQueue struct {
+	lock    sync.Mutex
+	events  []Event
+	pending chan struct{} // Signaled when events are queued
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newEventQueue() *eventQueue {
+	return &eventQueue{
+		pending: make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (q *eventQueue) push(event Event) {
+	q.lock.Lock()
+	q.events = append(q.events, event)
+	q.lock.Unlock()
+
+	select {
+	case q.pending <- struct{}{}:
+	default:
+	}
+}
+
+func (q *eventQueue) pop() []Event {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	events := q.events
+	q.events = nil
+	return events
+}
+
+func (q *eventQueue) close() {
+	q.once.Do(func() {
+		close(q.closed)
+	})
 }
@@ -38,3 +38,45 @@ func TestEventManager(t *testing.T) {
 
 	require.Zero(t, manager.Count())
 }
+
+func TestEventManagerUnsubscribe(t *testing.T) {
+	manager := NewEventManager()
+	first := manager.Subscribe()
+	second := manager.Subscribe()
+	require.Equal(t, 2, manager.Count())
+
+	manager.Unsubscribe(first)
+	require.Equal(t, 1, manager.Count())
+	_, ok := <-first.Events
+	require.False(t, ok)
+
+	// Unsubscribed channel is not closed twice
+	manager.Unsubscribe(first)
+	manager.Close()
+
+	_, ok = <-second.Events
+	require.False(t, ok)
+}
+
+func TestEventManagerSlowSubscriber(t *testing.T) {
+	manager := NewEventManager()
+	slow := manager.SubscribeNonBlocking()
+	fast := manager.Subscribe()
+
+	// Slow subscriber never reads, it's disconnected once the channel is full
+	for i := 0; i < cap(slow.Events)+1; i++ {
+		manager.Send(Event{Tag: EventStateChanged})
+		<-fast.Events
+	}
+	require.Equal(t, 1, manager.Count())
+
+	for i := 0; i < cap(slow.Events); i++ {
+		<-slow.Events
+	}
+	_, ok := <-slow.Events
+	require.False(t, ok)
+
+	manager.Close()
+	_, ok = <-fast.Events
+	require.False(t, ok)
+}
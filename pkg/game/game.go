@@ -1,13 +1,15 @@
 package game
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/ecdsa"
 	"fmt"
 	"reflect"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/jonboulle/clockwork"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -21,53 +23,58 @@ import (
 var (
 	ErrNoRoom             = errors.New("no room")
 	ErrGameNotInitialized = errors.New("game is not initialized")
+	ErrRoomReadOnly       = errors.New("room is opened read-only")
 
 	playerOnlineTimeout = 20 * time.Second
+	stateRequestTimeout = 5 * time.Second // Minimal period between state requests of a player
+	stateSnapshotPeriod = 1 * time.Second // Minimal period between full states published by the dealer on requests
+
+	// Every n-th heartbeat of the dealer is the full state, for clients that don't support state deltas
+	stateSnapshotHeartbeats = 2
 )
 
 type Game struct {
+	*common
+
+	// Current room of the game. Routines of each room run on a view of the game bound to the room session.
+	*session
+}
+
+// common is the part of the game shared by all joined rooms, see Game.forSession.
+type common struct {
 	logger       *zap.Logger
 	ctx          context.Context
 	transport    transport.Service
 	storage      storage.Service
 	clock        clockwork.Clock
-	exitRoom     chan struct{}
-	messages     chan []byte
 	config       configuration
 	features     FeatureFlags
 	codeControls codeControlFlags
 	initialized  bool
 
-	isDealer bool
-	player   *protocol.Player
-	myVote   protocol.VoteResult // We save our vote to show it in UI
-
-	room            *protocol.Room
-	roomID          protocol.RoomID
-	state           *protocol.State
-	stateTimestamp  int64
-	events          EventManager
-	revealTimer     clockwork.Timer
-	revealTimerLock sync.Mutex
+	player     *protocol.Player
+	playerLock sync.RWMutex // Player is shared by the routines of all rooms, its name and role can be changed
+	privateKey *ecdsa.PrivateKey
+	events     *EventManager
+	eventQueue *eventQueue
+	sessions   *sessions
 }
 
 func NewGame(opts []Option) *Game {
 	game := &Game{
-		exitRoom:     nil,
-		messages:     make(chan []byte, 42),
-		config:       defaultConfig(),
-		features:     defaultFeatureFlags(),
-		codeControls: defaultCodeControlFlags(),
-		initialized:  false,
-		isDealer:     false,
-		player:       nil,
-		myVote: protocol.VoteResult{
-			Value:     "",
-			Timestamp: 0,
+		common: &common{
+			config:       defaultConfig(),
+			features:     defaultFeatureFlags(),
+			codeControls: defaultCodeControlFlags(),
+			initialized:  false,
+			player:       nil,
+			events:       NewEventManager(),
+			eventQueue:   newEventQueue(),
+			sessions:     &sessions{},
 		},
-		room:           nil,
-		stateTimestamp: 0,
+		session: newSession(),
 	}
+	game.sessions.setCurrent(game.session)
 
 	for _, opt := range opts {
 		opt(game)
@@ -91,6 +98,8 @@ func NewGame(opts []Option) *Game {
 		return nil
 	}
 
+	go game.sendEventsLoop()
+
 	return game
 }
 
@@ -107,72 +116,267 @@ func (g *Game) Initialize() error {
 		return err
 	}
 
+	g.privateKey, err = g.loadIdentityKey(g.storage)
+	if err != nil {
+		return err
+	}
+
 	g.player = &protocol.Player{
-		ID:     player.ID,
-		Name:   player.Name,
-		Online: true,
+		ID:        player.ID,
+		Name:      player.Name,
+		Online:    true,
+		PublicKey: crypto.FromECDSAPub(&g.privateKey.PublicKey),
+		Role:      g.config.PlayerRole,
 	}
 
+	go g.watchOutboxLoop(g.transport.SubscribeToConnectionStatus())
+
 	g.initialized = true
 	return nil
 }
 
+// LeaveRoom leaves the current room and switches to the last joined one, if any.
 func (g *Game) LeaveRoom() {
+	g.stateLock.Lock()
+
 	if g.room != nil {
 		g.publishUserOnline(false)
 	}
 
-	if g.exitRoom != nil {
-		close(g.exitRoom)
-	}
+	g.stopRoleRoutines()
+	g.cancelAutoReveal()
+	g.cancelDeadlineTimer()
+
+	g.session.exit()
 
 	g.logger.Info("left room", zap.String("roomID", g.roomID.String()))
 
-	g.exitRoom = nil
 	g.isDealer = false
 	g.room = nil
-	g.roomID = protocol.NewRoomID("")
 	g.state = nil
+	g.dealerKey = nil
 	g.stateTimestamp = 0
-	g.notifyChangedState(false)
+	g.resetStateSequence()
+
+	g.stateLock.Unlock()
+
+	next := g.sessions.remove(g.session)
+	if next == nil {
+		next = newSession()
+	}
+	g.switchSession(next)
+}
+
+func (g *Game) LeaveAllRooms() {
+	for len(g.sessions.roomIDs()) > 0 {
+		g.LeaveRoom()
+	}
 }
 
 func (g *Game) Stop() {
 	g.events.Close()
-	g.LeaveRoom()
+	g.LeaveAllRooms()
+	g.eventQueue.close()
 	// WARNING: wait for all routines to finish
 }
 
+// Rooms returns IDs of all joined rooms in the order of joining.
+func (g *Game) Rooms() []protocol.RoomID {
+	return g.sessions.roomIDs()
+}
+
+// SwitchRoom makes one of the joined rooms the current one.
+// Other rooms keep running in the background, but only the current room sends events.
+func (g *Game) SwitchRoom(roomID protocol.RoomID) error {
+	session := g.sessions.get(roomID)
+	if session == nil {
+		return errors.New("room not joined")
+	}
+	if session == g.session {
+		return nil
+	}
+	g.switchSession(session)
+	g.logger.Info("switched room", zap.String("roomID", roomID.String()))
+	return nil
+}
+
+// SavedRooms returns the rooms saved in the storage, most recently used first.
+// Rooms joined as a player are included as archived, see OpenArchivedRoom.
+func (g *Game) SavedRooms() ([]storage.RoomInfo, error) {
+	if !g.HasStorage() {
+		return []storage.RoomInfo{}, nil
+	}
+	rooms, err := g.storage.ListRooms()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list saved rooms")
+	}
+	archived, err := g.storage.ListArchivedRooms()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list archived rooms")
+	}
+	rooms = append(rooms, archived...)
+	slices.SortStableFunc(rooms, func(a, b storage.RoomInfo) int {
+		return b.LastUsed.Compare(a.LastUsed)
+	})
+	return rooms, nil
+}
+
+// OpenArchivedRoom shows the last known state of a room joined as a player, without joining it.
+// The room is read-only: nothing is received from or published to it. Joining the room replaces it.
+func (g *Game) OpenArchivedRoom(roomID protocol.RoomID) error {
+	if !g.initialized {
+		return ErrGameNotInitialized
+	}
+	if g.sessions.get(roomID) != nil {
+		return g.SwitchRoom(roomID)
+	}
+
+	room, err := protocol.ParseRoomID(roomID.String())
+	if err != nil {
+		return errors.Wrap(err, "failed to parse room ID")
+	}
+
+	state, _ := g.loadArchivedState(roomID)
+	if state == nil {
+		return errors.New("room not found in archive")
+	}
+
+	session := newSession()
+	session.room = room
+	session.roomID = roomID
+	session.state = state
+	session.archived = true
+	session.readOnly = true
+
+	g.sessions.add(session)
+	g.switchSession(session)
+	g.logger.Info("opened archived room", zap.String("roomID", roomID.String()))
+	return nil
+}
+
+func (g *Game) RenameSavedRoom(roomID protocol.RoomID, name string) error {
+	if !g.HasStorage() {
+		return errors.New("storage is not available")
+	}
+	err := g.storage.RenameRoom(roomID, name)
+	if err != nil {
+		return errors.Wrap(err, "failed to rename room")
+	}
+	return nil
+}
+
+func (g *Game) DeleteSavedRoom(roomID protocol.RoomID) error {
+	if !g.HasStorage() {
+		return errors.New("storage is not available")
+	}
+	if g.sessions.get(roomID) != nil {
+		return errors.New("cannot delete a joined room")
+	}
+	err := g.storage.DeleteRoom(roomID)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete room")
+	}
+	return nil
+}
+
+func (g *Game) switchSession(session *session) {
+	g.session = session
+	g.sessions.setCurrent(session)
+
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+	g.notifyChangedState(false)
+}
+
+// forSession returns a view of the game bound to given session, sharing everything else with the game.
+// Used for routines and callbacks that must keep working with their room when the current room is switched.
+func (g *Game) forSession(session *session) *Game {
+	return &Game{
+		common:  g.common,
+		session: session,
+	}
+}
+
+// sendEvent queues the event for subscribers, unless it comes from a background room.
+func (g *Game) sendEvent(event Event) {
+	if !g.sessions.isCurrent(g.session) {
+		return
+	}
+	g.eventQueue.push(event)
+}
+
+// sendEventsLoop sends the queued events to subscribers in the order they were queued, see eventQueue.
+func (g *Game) sendEventsLoop() {
+	for {
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-g.eventQueue.closed:
+			return
+		case <-g.eventQueue.pending:
+			for _, event := range g.eventQueue.pop() {
+				g.events.Send(event)
+			}
+		}
+	}
+}
+
 func (g *Game) handleMessage(payload []byte) {
-	g.logger.Debug("handling message", zap.String("payload", string(payload)))
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	g.logger.Debug("handling message", payloadField(payload))
 
 	message := protocol.Message{}
-	err := json.Unmarshal(payload, &message)
+	err := protocol.Unmarshal(payload, &message)
 	if err != nil {
 		g.logger.Error("failed to unmarshal message", zap.Error(err))
 		return
 	}
 	logger := g.logger.With(zap.String("type", string(message.Type)))
 
+	// Unsigned messages are still accepted to support older clients,
+	// but such players are not marked as verified.
+	signer, err := protocol.VerifyMessage(payload)
+	if err != nil && !errors.Is(err, protocol.ErrMessageNotSigned) {
+		logger.Warn("message dropped: invalid signature", zap.Error(err))
+		return
+	}
+
 	switch message.Type {
 	case protocol.MessageTypeState:
-		if !g.isDealer {
-			g.handleStateMessage(payload)
-		}
+		g.handleStateMessage(payload, signer)
 
 	case protocol.MessageTypePlayerOnline:
 		if g.isDealer {
-			g.handlePlayerOnlineMessage(payload)
+			g.handlePlayerOnlineMessage(payload, signer)
+		} else {
+			g.handleDealerOnlineMessage(payload, signer)
 		}
 
 	case protocol.MessageTypePlayerOffline:
 		if g.isDealer {
-			g.handlePlayerOfflineMessage(payload)
+			g.handlePlayerOfflineMessage(payload, signer)
 		}
 
 	case protocol.MessageTypePlayerVote:
 		if g.isDealer {
-			g.handlePlayerVoteMessage(payload)
+			g.handlePlayerVoteMessage(payload, signer)
+		}
+
+	case protocol.MessageTypeStateDelta:
+		if !g.isDealer {
+			g.handleStateDeltaMessage(payload, signer)
+		}
+
+	case protocol.MessageTypeStateRequest:
+		if g.isDealer {
+			g.handleStateRequestMessage(payload, signer)
+		}
+
+	case protocol.MessageTypeDealerTransfer:
+		if !g.isDealer {
+			g.handleDealerTransferMessage(payload, signer)
 		}
 
 	default:
@@ -180,26 +384,54 @@ func (g *Game) handleMessage(payload []byte) {
 	}
 }
 
+func payloadField(payload []byte) zap.Field {
+	if protocol.DetectEncoding(payload) == protocol.EncodingJSON {
+		return zap.String("payload", string(payload))
+	}
+	return zap.Binary("payload", payload)
+}
+
 func (g *Game) Subscribe() *Subscription {
 	return g.events.Subscribe()
 }
 
+// SubscribeNonBlocking subscribes to events of a slow or remote subscriber, see EventManager.SubscribeNonBlocking.
+func (g *Game) SubscribeNonBlocking() *Subscription {
+	return g.events.SubscribeNonBlocking()
+}
+
+func (g *Game) Unsubscribe(subscription *Subscription) {
+	g.events.Unsubscribe(subscription)
+}
+
+// CurrentState returns a copy of the room state, which is safe to use while the game goes on.
 func (g *Game) CurrentState() *protocol.State {
-	return g.state
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	return g.state.Clone()
 }
 
 func (g *Game) notifyChangedState(publish bool) {
-	if g.HasStorage() && g.IsDealer() {
-		err := g.storage.SaveRoomState(g.RoomID(), g.state)
+	if g.player != nil {
+		g.outbox.confirm(g.state, g.player.ID)
+	}
+
+	if g.HasStorage() && g.isDealer {
+		err := g.storage.SaveRoomState(g.roomID, g.state)
 		if err != nil {
 			g.logger.Error("failed to save room state", zap.Error(err))
 		}
+	} else {
+		g.archiveRoomState()
 	}
 
 	if g.state != nil && g.state.VotesRevealed {
 		g.fillActiveIssueHint()
 	}
 
+	g.updateVoteDelivery()
+
 	state := g.hiddenCurrentState()
 
 	g.logger.Debug("notifying state change",
@@ -208,31 +440,27 @@ func (g *Game) notifyChangedState(publish bool) {
 		zap.Any("state", state),
 	)
 
-	g.events.Send(Event{
+	g.sendEvent(Event{
 		Tag:  EventStateChanged,
 		Data: state,
 	})
 
 	if publish {
-		go g.publishState(state)
+		g.publishState(state, publishStateChanges)
 	}
 
-	if g.config.AutoRevealEnabled {
-		if g.revealTimer != nil && (g.state != nil || !g.state.AllPlayersVoted()) {
-			g.cancelAutoReveal()
-		}
-		if g.state != nil && !g.state.VotesRevealed && g.state.AllPlayersVoted() {
-			g.scheduleAutoReveal()
-		}
+	if g.isDealer {
+		g.updateAutoReveal()
+		g.updateDeadlineTimer()
 	}
 }
 
 func (g *Game) publishOnlineState() {
-	g.publishUserOnline(true)
+	g.publishOnline()
 	for {
 		select {
 		case <-g.clock.After(g.config.OnlineMessagePeriod):
-			g.publishUserOnline(true)
+			g.publishOnline()
 		case <-g.exitRoom:
 			return
 		case <-g.ctx.Done():
@@ -241,14 +469,19 @@ func (g *Game) publishOnlineState() {
 	}
 }
 
-func (g *Game) publishStateLoop() {
+func (g *Game) publishStateLoop(roleExit chan struct{}) {
 	logger := g.logger.With(zap.String("source", "state publish loop"))
 	logger.Debug("started")
 	for {
 		select {
+		case <-roleExit:
+			logger.Debug("finished: dealer role changed")
+			return
 		case <-g.clock.After(g.config.StateMessagePeriod):
 			logger.Debug("tick")
-			g.notifyChangedState(true)
+			g.stateLock.Lock()
+			g.publishState(g.hiddenCurrentState(), publishStateHeartbeat)
+			g.stateLock.Unlock()
 		case <-g.exitRoom:
 			logger.Debug("finished: room left")
 			return
@@ -259,44 +492,55 @@ func (g *Game) publishStateLoop() {
 	}
 }
 
-func (g *Game) watchPlayersStateLoop() {
+func (g *Game) watchPlayersStateLoop(roleExit chan struct{}) {
 	g.logger.Debug("check users state loop")
 	ticker := g.clock.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 	for {
 		select {
+		case <-roleExit:
+			return
 		case <-g.exitRoom:
 			return
 		case <-g.ctx.Done():
 			return
 		case <-ticker.Chan():
-			if g.state == nil {
-				continue
-			}
-			stateChanged := false
-			now := g.clock.Now()
-			for i, player := range g.state.Players {
-				if !player.Online {
-					continue
-				}
-				if now.Sub(player.OnlineTime()) <= playerOnlineTimeout {
-					continue
-				}
-				g.logger.Info("marking user as offline",
-					zap.Any("name", player.Name),
-					zap.Any("lastSeenAt", player.OnlineTimestampMilliseconds),
-					zap.Any("now", now),
-				)
-				g.state.Players[i].Online = false
-				stateChanged = true
-			}
-			if stateChanged {
-				g.notifyChangedState(true)
-			}
+			g.markOfflinePlayers()
 		}
 	}
 }
 
+// markOfflinePlayers marks players that weren't seen for longer than playerOnlineTimeout as offline.
+func (g *Game) markOfflinePlayers() {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if g.state == nil {
+		return
+	}
+	stateChanged := false
+	now := g.clock.Now()
+	for i, player := range g.state.Players {
+		if !player.Online || player.ID == g.player.ID {
+			// The dealer is online as long as this loop runs
+			continue
+		}
+		if now.Sub(player.OnlineTime()) <= playerOnlineTimeout {
+			continue
+		}
+		g.logger.Info("marking user as offline",
+			zap.Any("name", player.Name),
+			zap.Any("lastSeenAt", player.OnlineTimestampMilliseconds),
+			zap.Any("now", now),
+		)
+		g.state.Players[i].Online = false
+		stateChanged = true
+	}
+	if stateChanged {
+		g.notifyChangedState(true)
+	}
+}
+
 func (g *Game) processIncomingMessages(sub *transport.MessagesSubscription) {
 	if sub.Unsubscribe != nil {
 		defer sub.Unsubscribe()
@@ -325,35 +569,88 @@ func (g *Game) loopPublishedMessages() {
 			return
 		case payload := <-g.messages:
 			g.handleMessage(payload)
+		case timeout := <-g.timeouts:
+			g.handleRevealTimeout(timeout)
 		}
 	}
 }
 
 func (g *Game) publishMessage(message any) error {
+	return g.publishMessageTo(message, nil)
+}
+
+// publishMessageTo publishes a message that can only be read by the owner of recipient key.
+// When recipient is nil, the message is published to the whole room.
+func (g *Game) publishMessageTo(message any, recipient *ecdsa.PublicKey) error {
 	if g.room == nil {
 		return ErrNoRoom
 	}
+	if g.readOnly {
+		return ErrRoomReadOnly
+	}
 
-	payload, err := json.Marshal(message)
+	payload, err := g.buildPayload(message)
 	if err != nil {
-		return errors.Wrap(err, "failed to marshal message")
+		return err
 	}
 
-	if g.config.EnableSymmetricEncryption {
-		err = g.transport.PublishPublicMessage(g.room, payload)
-	} else {
-		err = g.transport.PublishUnencryptedMessage(g.room, payload)
+	return g.publishPayload(payload, recipient)
+}
+
+// buildPayload marshals and signs the message.
+func (g *Game) buildPayload(message any) ([]byte, error) {
+	payload, err := protocol.Marshal(message, g.config.Encoding)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal message")
+	}
+
+	payload, err = protocol.SignMessage(payload, g.privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign message")
+	}
+
+	return payload, nil
+}
+
+func (g *Game) publishPayload(payload []byte, recipient *ecdsa.PublicKey) error {
+	return g.sendPayload(g.room, payload, recipient, g.isDealer)
+}
+
+// sendPayload publishes the payload to the room, looping it back to ourselves if requested.
+// It doesn't read the session state, so it can be called without the state lock.
+// Messages to a recipient are always encrypted with its key, even when symmetric encryption is disabled.
+func (g *Game) sendPayload(room *protocol.Room, payload []byte, recipient *ecdsa.PublicKey, loopback bool) error {
+	var err error
+	switch {
+	case recipient != nil:
+		err = g.transport.PublishPrivateMessage(room, payload, recipient)
+	case !g.config.EnableSymmetricEncryption:
+		err = g.transport.PublishUnencryptedMessage(room, payload)
+	default:
+		err = g.transport.PublishPublicMessage(room, payload)
 	}
 
 	// Loop message to ourselves
-	if g.isDealer {
+	if loopback {
 		g.messages <- payload
 	}
 
 	return errors.Wrap(err, "failed to publish message")
 }
 
+// publishOnline announces that the player is online, see publishUserOnline.
+func (g *Game) publishOnline() {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	g.publishUserOnline(true)
+}
+
 func (g *Game) publishUserOnline(online bool) {
+	if g.readOnly {
+		return
+	}
+
 	timestamp := g.timestamp()
 
 	g.logger.Debug("publishing online state",
@@ -363,7 +660,7 @@ func (g *Game) publishUserOnline(online bool) {
 
 	var message interface{}
 
-	player := *g.player
+	player := g.Player()
 	player.ApplyDeprecatedPatchOnSend()
 
 	if online {
@@ -391,15 +688,31 @@ func (g *Game) publishUserOnline(online bool) {
 }
 
 func (g *Game) PublishVote(vote protocol.VoteValue) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	return g.publishVote(vote)
+}
+
+func (g *Game) publishVote(vote protocol.VoteValue) error {
+	if g.archived {
+		return errors.New("room state is not received from the dealer yet")
+	}
 	if g.state.VoteState() != protocol.VotingState {
 		return errors.New("no voting in progress")
 	}
-	if vote != "" && !slices.Contains(g.state.Deck, vote) {
+	if vote != "" && !g.state.Deck.Contains(vote) {
 		return fmt.Errorf("invalid vote")
 	}
+	if vote != "" && g.IsObserver() {
+		return errors.New("observers can't vote")
+	}
 	g.logger.Debug("publishing vote", zap.Any("vote", vote))
-	g.myVote = *protocol.NewVoteResult(vote)
-	err := g.publishMessage(protocol.PlayerVoteMessage{
+	g.myVote = protocol.VoteResult{
+		Value:     vote,
+		Timestamp: g.timestamp(),
+	}
+	payload, err := g.buildPayload(protocol.PlayerVoteMessage{
 		Message: protocol.Message{
 			Type:      protocol.MessageTypePlayerVote,
 			Timestamp: g.timestamp(),
@@ -409,9 +722,23 @@ func (g *Game) PublishVote(vote protocol.VoteValue) error {
 		VoteResult: g.myVote,
 	})
 	if err != nil {
-		g.logger.Error("failed to publish vote", zap.Error(err))
+		g.logger.Error("failed to build vote message", zap.Error(err))
 		return err
 	}
+
+	// The vote is kept in the outbox until the dealer confirms it
+	g.outbox.add(outboxMessage{
+		issue:   g.state.ActiveIssue,
+		vote:    g.myVote,
+		payload: payload,
+	})
+	g.archiveRoomState()
+	g.updateVoteDelivery()
+
+	err = g.publishPayload(payload, g.dealerPublicKey())
+	if err != nil {
+		g.logger.Warn("failed to publish vote, it will be retried", zap.Error(err))
+	}
 	return nil
 }
 
@@ -419,7 +746,32 @@ func (g *Game) RetractVote() error {
 	return g.PublishVote("")
 }
 
-func (g *Game) publishState(state *protocol.State) {
+// dealerPublicKey returns the dealer public key from the current state.
+// Returns nil if the dealer didn't share the key, in this case votes are published to the whole room.
+func (g *Game) dealerPublicKey() *ecdsa.PublicKey {
+	if g.state == nil || len(g.state.DealerPublicKey) == 0 {
+		return nil
+	}
+	publicKey, err := crypto.UnmarshalPubkey(g.state.DealerPublicKey)
+	if err != nil {
+		g.logger.Warn("failed to parse dealer public key", zap.Error(err))
+		return nil
+	}
+	return publicKey
+}
+
+type statePublishMode int
+
+const (
+	publishStateChanges   statePublishMode = iota // Publish changes since the last published state, if any
+	publishStateHeartbeat                         // Same as above, but announce the current sequence if there are no changes, or publish the full state periodically
+	publishStateSnapshot                          // Publish the full state
+)
+
+// publishState publishes the state to players. Only the changes since the previously published state
+// are sent when possible, see protocol.StateDeltaMessage.
+// The message is built synchronously to keep the sequence order, publishing itself is asynchronous.
+func (g *Game) publishState(state *protocol.State, mode statePublishMode) {
 	if !g.isDealer {
 		g.logger.Warn("only dealer can publish state")
 		return
@@ -430,16 +782,135 @@ func (g *Game) publishState(state *protocol.State) {
 		return
 	}
 
-	g.logger.Debug("publishing state")
-	err := g.publishMessage(protocol.GameStateMessage{
+	g.publishedLock.Lock()
+	message := g.buildStateMessage(state, mode)
+	if message == nil {
+		g.publishedLock.Unlock()
+		return
+	}
+	payload, err := g.buildPayload(message)
+	if err != nil {
+		g.publishedLock.Unlock()
+		g.logger.Error("failed to publish state", zap.Error(err))
+		return
+	}
+	previous := g.statePublished
+	published := make(chan struct{})
+	g.statePublished = published
+	g.publishedLock.Unlock()
+
+	// The session state is not locked while publishing
+	room := g.room
+	bound := g.forSession(g.session)
+	go func() {
+		defer close(published)
+		if previous != nil {
+			// Keep the order of messages, otherwise players would miss deltas
+			<-previous
+		}
+		bound.logger.Debug("publishing state")
+		err := bound.sendPayload(room, payload, nil, true)
+		if err != nil {
+			bound.logger.Error("failed to publish state", zap.Error(err))
+		}
+	}()
+}
+
+// buildStateMessage must be called with publishedLock held.
+func (g *Game) buildStateMessage(state *protocol.State, mode statePublishMode) any {
+	// Message is published asynchronously, it must not share any data with the game state
+	state = state.Clone()
+	full := mode == publishStateSnapshot || g.publishedState == nil
+	baseSequence := g.stateSequence
+
+	if mode == publishStateHeartbeat {
+		g.heartbeats++
+		full = full || g.heartbeats >= stateSnapshotHeartbeats
+	}
+
+	var delta *protocol.StateDelta
+	if g.publishedState != nil {
+		delta = protocol.NewStateDelta(g.publishedState, state)
+		changed := !delta.Empty()
+
+		if !changed && mode == publishStateChanges {
+			return nil
+		}
+
+		if changed {
+			g.stateSequence++
+		}
+
+		if !full && changed {
+			// Make sure players will get exactly the same state
+			expected := g.publishedState.Clone()
+			expected.ApplyDelta(delta)
+			full = !expected.Equal(state)
+		}
+	} else {
+		g.stateSequence++
+	}
+
+	g.publishedState = state
+
+	if full {
+		g.heartbeats = 0
+		return protocol.GameStateMessage{
+			Message: protocol.Message{
+				Type:      protocol.MessageTypeState,
+				Timestamp: g.timestamp(),
+			},
+			State:    *state,
+			Sequence: g.stateSequence,
+		}
+	}
+
+	return protocol.StateDeltaMessage{
+		Message: protocol.Message{
+			Type:      protocol.MessageTypeStateDelta,
+			Timestamp: g.timestamp(),
+		},
+		Sequence:     g.stateSequence,
+		BaseSequence: baseSequence,
+		Delta:        *delta,
+	}
+}
+
+// resetStateSequence resets the state publishing history when joining or leaving a room.
+// Dealer starts the sequence from current time, so that the sequence keeps growing after restarts.
+func (g *Game) resetStateSequence() {
+	g.publishedLock.Lock()
+	defer g.publishedLock.Unlock()
+
+	g.publishedState = nil
+	g.heartbeats = 0
+	g.stateSequence = 0
+	if g.isDealer {
+		g.stateSequence = g.timestamp()
+	}
+	g.snapshotTime = time.Time{}
+	g.stateRequested = time.Time{}
+}
+
+// requestState asks the dealer to publish the full state.
+func (g *Game) requestState() {
+	now := g.clock.Now()
+	if !g.stateRequested.IsZero() && now.Sub(g.stateRequested) < stateRequestTimeout {
+		return
+	}
+	g.stateRequested = now
+
+	g.logger.Debug("requesting state", zap.Int64("sequence", g.stateSequence))
+	err := g.publishMessage(protocol.StateRequestMessage{
 		Message: protocol.Message{
-			Type:      protocol.MessageTypeState,
+			Type:      protocol.MessageTypeStateRequest,
 			Timestamp: g.timestamp(),
 		},
-		State: *state,
+		PlayerID: g.player.ID,
+		Sequence: g.stateSequence,
 	})
 	if err != nil {
-		g.logger.Error("failed to publish state", zap.Error(err))
+		g.logger.Error("failed to request state", zap.Error(err))
 	}
 }
 
@@ -448,6 +919,9 @@ func (g *Game) timestamp() int64 {
 }
 
 func (g *Game) Deal(input string) (protocol.IssueID, error) {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
 	if !g.isDealer {
 		return "", errors.New("only dealer can deal")
 	}
@@ -461,7 +935,7 @@ func (g *Game) Deal(input string) (protocol.IssueID, error) {
 		return "", errors.Wrap(err, "failed to add issue")
 	}
 
-	err = g.SelectIssue(len(g.state.Issues) - 1)
+	err = g.selectIssue(len(g.state.Issues) - 1)
 
 	return issueID, err
 }
@@ -483,11 +957,13 @@ func (g *Game) CreateNewRoom() (*protocol.Room, *protocol.State, error) {
 	}
 
 	state := &protocol.State{
-		Players:     []protocol.Player{*g.player},
-		Deck:        deck,
-		ActiveIssue: "",
-		Issues:      make([]*protocol.Issue, 0),
-		Timestamp:   g.timestamp(),
+		Players:         []protocol.Player{g.Player()},
+		Deck:            deck,
+		ActiveIssue:     "",
+		Issues:          make([]*protocol.Issue, 0),
+		Timestamp:       g.timestamp(),
+		DealerPublicKey: crypto.FromECDSAPub(&g.privateKey.PublicKey),
+		Dealer:          g.player.ID,
 	}
 
 	return room, state, nil
@@ -498,11 +974,12 @@ func (g *Game) JoinRoom(roomID protocol.RoomID, state *protocol.State) error {
 		return ErrGameNotInitialized
 	}
 
-	if g.RoomID() == roomID {
+	if g.roomID == roomID && !g.readOnly {
 		return errors.New("already in this room")
 	}
-	if g.room != nil {
-		return errors.New("exit current room to join another one")
+	replaced := g.sessions.get(roomID)
+	if replaced != nil && !replaced.readOnly {
+		return g.SwitchRoom(roomID)
 	}
 	if roomID.Empty() {
 		return errors.New("empty room ID")
@@ -520,22 +997,76 @@ func (g *Game) JoinRoom(roomID protocol.RoomID, state *protocol.State) error {
 		state = g.loadStateFromStorage(roomID)
 	}
 
-	g.exitRoom = make(chan struct{})
+	if state != nil && state.Dealer != "" && state.Dealer != g.player.ID {
+		// The dealer role was transferred to another player, join as a player
+		state = nil
+	}
+
+	if replaced != nil {
+		// Room was opened from the archive, join it for real
+		g.sessions.remove(replaced)
+	}
+
+	// Routines of the new session wait for it to be initialized
+	joined := newSession()
+	joined.stateLock.Lock()
+	defer joined.stateLock.Unlock()
+
+	previous := g.session
+	g.session = joined
+
 	g.isDealer = state != nil
 	g.room = room
 	g.roomID = roomID
 	g.state = state
+
+	if !g.isDealer {
+		// Show the last known state until the dealer publishes the actual one
+		var outbox []storage.PendingVote
+		g.state, outbox = g.loadArchivedState(roomID)
+		g.archived = g.state != nil
+		g.outbox.restore(outbox)
+	}
+	g.dealerKey = nil
+	g.dealerSeen = g.clock.Now()
 	g.stateTimestamp = 0
+	g.resetStateSequence()
+
+	if g.isDealer {
+		// Identity key is not persisted in anonymous mode, share the actual public key with players
+		g.state.DealerPublicKey = crypto.FromECDSAPub(&g.privateKey.PublicKey)
+		g.state.Dealer = g.player.ID
+	}
 
 	g.resetMyVote()
+	if g.state != nil {
+		if vote, pending := g.outbox.vote(g.state.ActiveIssue); pending {
+			// Vote restored from the archive, it's published again until the dealer confirms it
+			g.myVote = vote
+		}
+	}
+
+	g.sessions.add(g.session)
+	g.sessions.setCurrent(g.session)
 
 	err = g.startRoutines()
 	if err != nil {
+		g.sessions.remove(g.session)
+		if replaced != nil {
+			g.sessions.add(replaced)
+		}
+		g.session = previous
+		g.sessions.setCurrent(previous)
 		return errors.Wrap(err, "failed to start routines")
 	}
 
 	g.notifyChangedState(g.isDealer)
 
+	if !g.isDealer {
+		// Don't wait for the dealer to publish the state
+		g.requestState()
+	}
+
 	if state == nil {
 		g.logger.Info("joined room", zap.Any("roomID", roomID))
 	} else {
@@ -547,35 +1078,282 @@ func (g *Game) JoinRoom(roomID protocol.RoomID, state *protocol.State) error {
 }
 
 func (g *Game) startRoutines() error {
-	sub, err := g.transport.SubscribeToMessages(g.room)
+	sub, err := g.transport.SubscribeToMessages(g.room, g.privateKey)
 	if err != nil {
 		return errors.Wrap(err, "failed to subscribe to messages")
 	}
 
-	go g.loopPublishedMessages()
-	go g.processIncomingMessages(sub)
+	bound := g.forSession(g.session)
+	go bound.loopPublishedMessages()
+	go bound.processIncomingMessages(sub)
 
 	if g.codeControls.EnablePublishOnlineState {
-		go g.publishOnlineState()
+		go bound.publishOnlineState()
 	}
 
-	if !g.isDealer {
-		return nil
-	}
+	g.startRoleRoutines()
+
+	return nil
+}
+
+// startRoleRoutines starts the routines specific to the current role of the player in the room.
+func (g *Game) startRoleRoutines() {
+	g.roleExit = make(chan struct{})
+	bound := g.forSession(g.session)
+
+	if !g.isDealer {
+		go bound.watchDealerLoop(g.roleExit)
+		return
+	}
 
 	if g.config.PublishStateLoopEnabled {
-		go g.publishStateLoop()
+		go bound.publishStateLoop(g.roleExit)
+	}
+	go bound.watchPlayersStateLoop(g.roleExit)
+}
+
+func (g *Game) stopRoleRoutines() {
+	if g.roleExit != nil {
+		close(g.roleExit)
+		g.roleExit = nil
+	}
+}
+
+// watchDealerLoop elects a new dealer when the current one is offline for longer than playerOnlineTimeout.
+func (g *Game) watchDealerLoop(roleExit chan struct{}) {
+	logger := g.logger.With(zap.String("source", "watch dealer loop"))
+	ticker := g.clock.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-roleExit:
+			return
+		case <-g.exitRoom:
+			return
+		case <-g.ctx.Done():
+			return
+		case <-ticker.Chan():
+			if g.takeOverOfflineDealer(logger) {
+				return
+			}
+		}
+	}
+}
+
+// takeOverOfflineDealer becomes the dealer when the dealer is offline and this player is elected.
+// Returns true when the room was taken over.
+func (g *Game) takeOverOfflineDealer(logger *zap.Logger) bool {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if g.isDealer {
+		// Role changed while waiting for the lock
+		return false
+	}
+	if g.state == nil || g.state.Dealer == "" {
+		// Older dealers don't support dealer transfer
+		return false
+	}
+	if g.archived {
+		// Never take over a room that was only seen in the archive
+		return false
+	}
+	offline := g.clock.Now().Sub(g.dealerSeen)
+	if offline <= playerOnlineTimeout {
+		return false
+	}
+	dealer := g.electDealer(offline)
+	if dealer != g.player.ID {
+		return false
+	}
+	logger.Info("dealer is offline, taking over the room",
+		zap.Any("dealer", g.state.Dealer),
+		zap.Duration("offline", offline),
+	)
+	g.becomeDealer(g.state.Clone(), g.stateSequence, true)
+	return true
+}
+
+// electDealer returns the player that should take over the room when the dealer is offline.
+// All players choose the same one: online verified player with the lowest ID.
+// If the elected player doesn't take over within playerOnlineTimeout, the next one is elected.
+func (g *Game) electDealer(offline time.Duration) protocol.PlayerID {
+	candidates := make([]protocol.PlayerID, 0, len(g.state.Players))
+	for _, player := range g.state.Players {
+		if player.ID == g.state.Dealer || !player.Online || !player.Verified() {
+			continue
+		}
+		candidates = append(candidates, player.ID)
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	slices.Sort(candidates)
+	index := int(offline/playerOnlineTimeout-1) % len(candidates)
+	return candidates[index]
+}
+
+// becomeDealer makes this player the dealer of the room with given state.
+// On takeover the votes of the active issue are unknown, players publish them again to the new dealer.
+func (g *Game) becomeDealer(state *protocol.State, sequence int64, takeover bool) {
+	g.stopRoleRoutines()
+
+	previousDealer := state.Dealer
+	state.Dealer = g.player.ID
+	state.DealerPublicKey = crypto.FromECDSAPub(&g.privateKey.PublicKey)
+
+	// Give players some time to show up to the new dealer
+	now := g.timestamp()
+	for i, player := range state.Players {
+		if player.ID == previousDealer && takeover {
+			state.Players[i].Online = false
+			continue
+		}
+		if player.Online {
+			state.Players[i].OnlineTimestampMilliseconds = now
+		}
+	}
+
+	if takeover && state.VoteState() == protocol.VotingState {
+		if issue := state.GetActiveIssue(); issue != nil {
+			issue.Votes = make(protocol.IssueVotes)
+			if g.myVote.Value != "" {
+				issue.Votes[g.player.ID] = g.myVote
+			}
+		}
 	}
-	go g.watchPlayersStateLoop()
 
+	g.isDealer = true
+	g.dealerKey = nil
+	g.state = state
+	g.resetStateSequence()
+	// Players ignore states with lower sequence
+	g.stateSequence = max(g.stateSequence, sequence)
+
+	g.logger.Info("became dealer",
+		zap.Any("previousDealer", previousDealer),
+		zap.Bool("takeover", takeover),
+	)
+
+	g.startRoleRoutines()
+	g.notifyChangedState(true)
+	g.sendEvent(Event{
+		Tag:  EventDealerChanged,
+		Data: g.isDealer,
+	})
+}
+
+// stepDown makes this player a regular player, following the dealer with given key.
+func (g *Game) stepDown(dealerKey *ecdsa.PublicKey) {
+	g.stopRoleRoutines()
+	g.cancelAutoReveal()
+	g.cancelDeadlineTimer()
+
+	g.isDealer = false
+	g.dealerKey = dealerKey
+	g.dealerSeen = g.clock.Now()
+	g.resetStateSequence()
+
+	g.logger.Info("stepped down from dealer")
+
+	g.startRoleRoutines()
+	g.sendEvent(Event{
+		Tag:  EventDealerChanged,
+		Data: g.isDealer,
+	})
+}
+
+// TransferDealer hands the dealer role to another online verified player.
+// The new dealer receives the full state, including hidden votes, encrypted with its public key.
+func (g *Game) TransferDealer(playerID protocol.PlayerID) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if !g.isDealer {
+		return errors.New("only dealer can transfer the dealer role")
+	}
+	if playerID == g.player.ID {
+		return errors.New("already a dealer")
+	}
+
+	player, found := g.state.Players.Get(playerID)
+	if !found {
+		return errors.New("player not found")
+	}
+	if !player.Online {
+		return errors.New("player is offline")
+	}
+	if !player.Verified() {
+		return errors.New("player is not verified")
+	}
+
+	publicKey, err := crypto.UnmarshalPubkey(player.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse player public key")
+	}
+
+	state := g.state.Clone()
+	state.Dealer = playerID
+	state.DealerPublicKey = player.PublicKey
+
+	err = g.publishMessageTo(protocol.DealerTransferMessage{
+		Message: protocol.Message{
+			Type:      protocol.MessageTypeDealerTransfer,
+			Timestamp: g.timestamp(),
+		},
+		Dealer:   playerID,
+		State:    *state,
+		Sequence: g.stateSequence,
+	}, publicKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to publish dealer transfer")
+	}
+
+	// Announce the new dealer to players while we're still the dealer
+	g.cancelAutoReveal()
+	g.cancelDeadlineTimer()
+	g.state.Dealer = state.Dealer
+	g.state.DealerPublicKey = state.DealerPublicKey
+	g.notifyChangedState(true)
+
+	g.stepDown(publicKey)
 	return nil
 }
 
 func (g *Game) IsDealer() bool {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
 	return g.isDealer
 }
 
+// IsObserver tells if the player watches the game without voting.
+func (g *Game) IsObserver() bool {
+	if g.player == nil {
+		return false
+	}
+	player := g.Player()
+	return player.Observer()
+}
+
+// StateArchived returns true when the current state was loaded from the archive.
+// Such state is read-only and is replaced with the dealer state once received.
+func (g *Game) StateArchived() bool {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	return g.archived
+}
+
+// ReadOnly returns true when the current room was opened from the archive without joining it.
+func (g *Game) ReadOnly() bool {
+	return g.readOnly
+}
+
 func (g *Game) Room() protocol.Room {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
 	return *g.room
 }
 
@@ -588,10 +1366,16 @@ func (g *Game) Initialized() bool {
 }
 
 func (g *Game) Player() protocol.Player {
+	g.playerLock.RLock()
+	defer g.playerLock.RUnlock()
+
 	return *g.player
 }
 
 func (g *Game) MyVote() protocol.VoteResult {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
 	return g.myVote
 }
 
@@ -603,12 +1387,54 @@ func (g *Game) RenamePlayer(name string) error {
 		}
 	}
 
+	g.playerLock.Lock()
 	g.player.Name = name
-	g.publishUserOnline(true)
+	g.playerLock.Unlock()
+
+	g.publishOnline()
+	return nil
+}
+
+// SetPlayerRole switches the player between voting and observing the game.
+// The vote of the current issue is retracted by the dealer when the player becomes an observer.
+func (g *Game) SetPlayerRole(role protocol.PlayerRole) error {
+	if g.player == nil {
+		return ErrGameNotInitialized
+	}
+	if role != protocol.VoterRole && role != protocol.ObserverRole {
+		return errors.Errorf("unknown player role: '%s'", role)
+	}
+
+	g.playerLock.Lock()
+	g.player.Role = role
+	g.playerLock.Unlock()
+
+	// The role is shared by all rooms, announce it to each dealer
+	for _, session := range g.sessions.all() {
+		bound := g.forSession(session)
+		bound.stateLock.Lock()
+		if role == protocol.ObserverRole {
+			// Pending votes won't be accepted by the dealer
+			bound.resetMyVote()
+			bound.outbox.clear()
+			bound.updateVoteDelivery()
+		}
+		if bound.room != nil {
+			bound.publishUserOnline(true)
+		}
+		bound.stateLock.Unlock()
+	}
 	return nil
 }
 
 func (g *Game) Reveal() error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	return g.reveal()
+}
+
+func (g *Game) reveal() error {
 	if !g.isDealer {
 		return errors.New("only dealer can reveal cards")
 	}
@@ -620,40 +1446,84 @@ func (g *Game) Reveal() error {
 	g.cancelAutoReveal()
 
 	g.state.VotesRevealed = true
+	g.state.VotingDeadline = 0
 	g.notifyChangedState(true)
 	return nil
 }
 
+// Revote starts a new voting round for the active issue.
+// Revealed votes are kept in the issue rounds, so that the rounds can be compared.
+func (g *Game) Revote() error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if !g.isDealer {
+		return errors.New("only dealer can start a new voting round")
+	}
+
+	if g.state.VoteState() != protocol.RevealedState {
+		return errors.New("cannot revote when votes are not revealed")
+	}
+
+	item := g.state.Issues.Get(g.state.ActiveIssue)
+	if item == nil {
+		return errors.New("vote item not found in the vote list")
+	}
+
+	g.archiveIssueRound(item)
+	g.state.VotesRevealed = false
+	g.startVotingTimebox()
+	g.resetMyVote()
+	g.notifyChangedState(true)
+	return nil
+}
+
+// archiveIssueRound moves the issue votes to a new round. Issues without votes are left as is.
+// The hint of the round is calculated once, so that it doesn't change with the deck or hint settings.
+func (g *Game) archiveIssueRound(item *protocol.Issue) {
+	if len(item.Votes) == 0 {
+		return
+	}
+	hint, err := g.hintStrategy().Hint(g.state.Deck, g.votersVotes(item.Votes))
+	if err != nil {
+		g.logger.Error("failed to generate round hint", zap.Error(err))
+	}
+	item.Rounds = append(item.Rounds, protocol.IssueRound{
+		Votes:     item.Votes,
+		Result:    item.Result,
+		Timestamp: g.timestamp(),
+		Hint:      hint,
+	})
+	item.Votes = make(protocol.IssueVotes)
+	item.Result = nil
+	item.Hint = nil
+}
+
 func (g *Game) hiddenCurrentState() *protocol.State {
 	if g.state == nil {
 		return nil
 	}
 
-	// Create a deep copy of the state
-	hiddenState := *g.state
+	// Create a deep copy of the state, it's used after the state lock is released
+	hiddenState := g.state.Clone()
 
 	if hiddenState.VoteState() != protocol.VotingState {
-		return &hiddenState
+		return hiddenState
 	}
 
-	hiddenState.Issues = make([]*protocol.Issue, 0, len(g.state.Issues))
-	for _, item := range g.state.Issues {
-		copiedItem := *item
-		copiedItem.Votes = make(protocol.IssueVotes, len(item.Votes))
+	if item := hiddenState.GetActiveIssue(); item != nil {
 		for playerID, vote := range item.Votes {
-			if item.ID == g.state.ActiveIssue {
-				copiedItem.Votes[playerID] = vote.Hidden()
-			} else {
-				copiedItem.Votes[playerID] = vote
-			}
+			item.Votes[playerID] = vote.Hidden()
 		}
-		hiddenState.Issues = append(hiddenState.Issues, &copiedItem)
 	}
 
-	return &hiddenState
+	return hiddenState
 }
 
 func (g *Game) SetDeck(deck protocol.Deck) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
 	if !g.isDealer {
 		return errors.New("only dealer can set deck")
 	}
@@ -665,14 +1535,102 @@ func (g *Game) SetDeck(deck protocol.Deck) error {
 	return nil
 }
 
+// SetHintSettings sets the hint strategy and thresholds of the room.
+// Zero values mean defaults.
+func (g *Game) SetHintSettings(settings protocol.HintSettings) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if !g.isDealer {
+		return errors.New("only dealer can set hint settings")
+	}
+	_, err := NewHintStrategy(&settings)
+	if err != nil {
+		return err
+	}
+	if settings.Empty() {
+		g.state.Hints = nil
+	} else {
+		g.state.Hints = &settings
+	}
+	g.notifyChangedState(true)
+	return nil
+}
+
+// SetAutoReveal sets when votes of the room are revealed automatically.
+// Empty settings mean defaults of the dealer client.
+func (g *Game) SetAutoReveal(settings protocol.AutoRevealSettings) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if !g.isDealer {
+		return errors.New("only dealer can set auto reveal")
+	}
+	err := ValidateAutoRevealSettings(&settings)
+	if err != nil {
+		return err
+	}
+	if settings.Empty() {
+		g.state.AutoReveal = nil
+	} else {
+		g.state.AutoReveal = &settings
+	}
+	g.notifyChangedState(true)
+	return nil
+}
+
+// SetTimebox sets the default voting duration of the room.
+// Each deal starts a countdown, when it expires votes are revealed even if not all players voted.
+// Zero disables the timebox. The countdown of the current vote is not changed, see SetVotingDeadline.
+func (g *Game) SetTimebox(timebox time.Duration) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if !g.isDealer {
+		return errors.New("only dealer can set timebox")
+	}
+	if timebox < 0 {
+		return errors.New("timebox can't be negative")
+	}
+	g.state.Timebox = timebox.Milliseconds()
+	g.notifyChangedState(true)
+	return nil
+}
+
+// SetVotingDeadline starts a countdown of given duration for the current vote, regardless of the room timebox.
+// Zero stops the countdown, so that votes are only revealed by the dealer.
+func (g *Game) SetVotingDeadline(duration time.Duration) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if !g.isDealer {
+		return errors.New("only dealer can set voting deadline")
+	}
+	if duration < 0 {
+		return errors.New("voting duration can't be negative")
+	}
+	if g.state.VoteState() != protocol.VotingState {
+		return errors.New("cannot set voting deadline when voting is not in progress")
+	}
+	g.state.VotingDeadline = 0
+	if duration > 0 {
+		g.state.VotingDeadline = g.clock.Now().Add(duration).UnixMilli()
+	}
+	g.notifyChangedState(true)
+	return nil
+}
+
 func (g *Game) Finish(result protocol.VoteValue) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
 	if !g.isDealer {
 		return errors.New("only dealer can finish")
 	}
 	if g.state.VoteState() != protocol.RevealedState {
 		return errors.New("cannot finish when voting is not revealed")
 	}
-	if !slices.Contains(g.state.Deck, result) {
+	if !g.state.Deck.Contains(result) {
 		return errors.New("result is not in the deck")
 	}
 
@@ -684,6 +1642,7 @@ func (g *Game) Finish(result protocol.VoteValue) error {
 	item.Result = &result
 	g.state.ActiveIssue = g.state.Issues.GetNextIssueToDeal(g.state.ActiveIssue)
 	g.state.VotesRevealed = false
+	g.startVotingTimebox()
 	g.resetMyVote()
 	g.notifyChangedState(true)
 
@@ -698,6 +1657,9 @@ func (g *Game) resetMyVote() {
 }
 
 func (g *Game) AddIssue(titleOrURL string) (protocol.IssueID, error) {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
 	if !g.isDealer {
 		return "", errors.New("only dealer can add issues")
 	}
@@ -735,6 +1697,13 @@ func (g *Game) addIssue(titleOrURL string) (protocol.IssueID, error) {
 }
 
 func (g *Game) SelectIssue(index int) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	return g.selectIssue(index)
+}
+
+func (g *Game) selectIssue(index int) error {
 	if !g.isDealer {
 		return errors.New("only dealer can deal")
 	}
@@ -747,14 +1716,130 @@ func (g *Game) SelectIssue(index int) error {
 		return errors.New("invalid issue deckIndex")
 	}
 
-	g.state.Issues[index].Result = nil
-	g.state.Issues[index].Votes = make(protocol.IssueVotes)
-	g.state.ActiveIssue = g.state.Issues[index].ID
+	item := g.state.Issues[index]
+	if item.ID == g.state.ActiveIssue && g.state.VoteState() == protocol.VotingState && len(item.Votes) > 0 {
+		// Votes are hidden, they can only be kept as a round after they're revealed
+		return errors.New("cannot deal the issue again while it's voted, reveal the votes first")
+	}
+
+	// Keep the votes as the previous round, even if the issue wasn't finished
+	g.archiveIssueRound(item)
+	item.Result = nil
+	item.Votes = make(protocol.IssueVotes)
+	g.state.ActiveIssue = item.ID
+	g.startVotingTimebox()
+	g.resetMyVote()
 	g.notifyChangedState(true)
 
 	return nil
 }
 
+// RemoveIssue removes the issue at given index.
+// The active issue can't be removed while the voting is in progress.
+func (g *Game) RemoveIssue(index int) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if !g.isDealer {
+		return errors.New("only dealer can remove issues")
+	}
+
+	err := g.checkIssueIndex(index)
+	if err != nil {
+		return err
+	}
+
+	issue := g.state.Issues[index]
+	if issue.ID == g.state.ActiveIssue {
+		voteState := g.state.VoteState()
+		if voteState == protocol.VotingState || voteState == protocol.RevealedState {
+			return errors.New("cannot remove the active issue when voting is in progress")
+		}
+		g.state.ActiveIssue = ""
+		g.state.VotesRevealed = false
+		g.resetMyVote()
+	}
+
+	g.logger.Debug("removing issue", zap.String("issueID", string(issue.ID)))
+	g.state.Issues = slices.Delete(g.state.Issues, index, index+1)
+	g.notifyChangedState(true)
+
+	return nil
+}
+
+// EditIssue changes the title or URL of the issue at given index.
+func (g *Game) EditIssue(index int, titleOrURL string) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if !g.isDealer {
+		return errors.New("only dealer can edit issues")
+	}
+
+	err := g.checkIssueIndex(index)
+	if err != nil {
+		return err
+	}
+
+	if titleOrURL == "" {
+		return errors.New("empty issue")
+	}
+
+	if g.state.Issues[index].TitleOrURL == titleOrURL {
+		return nil
+	}
+
+	for i, item := range g.state.Issues {
+		if i != index && item.TitleOrURL == titleOrURL {
+			return errors.New("issue already exists")
+		}
+	}
+
+	g.state.Issues[index].TitleOrURL = titleOrURL
+	g.notifyChangedState(true)
+
+	return nil
+}
+
+// MoveIssue moves the issue to a new index, shifting the issues in between.
+// Issues are dealt in this order, see IssuesList.GetNextIssueToDeal.
+func (g *Game) MoveIssue(index int, newIndex int) error {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if !g.isDealer {
+		return errors.New("only dealer can move issues")
+	}
+
+	err := g.checkIssueIndex(index)
+	if err != nil {
+		return err
+	}
+
+	err = g.checkIssueIndex(newIndex)
+	if err != nil {
+		return err
+	}
+
+	if index == newIndex {
+		return nil
+	}
+
+	issue := g.state.Issues[index]
+	g.state.Issues = slices.Delete(g.state.Issues, index, index+1)
+	g.state.Issues = slices.Insert(g.state.Issues, newIndex, issue)
+	g.notifyChangedState(true)
+
+	return nil
+}
+
+func (g *Game) checkIssueIndex(index int) error {
+	if index < 0 || index >= len(g.state.Issues) {
+		return errors.New("invalid issue index")
+	}
+	return nil
+}
+
 func (g *Game) playerIndex(playerID protocol.PlayerID) int {
 	if g.state == nil {
 		return -1
@@ -797,6 +1882,139 @@ func (g *Game) loadPlayer(s storage.Service) (*protocol.Player, error) {
 	return &player, nil
 }
 
+func (g *Game) loadIdentityKey(s storage.Service) (*ecdsa.PrivateKey, error) {
+	if !nilStorage(s) {
+		if key := s.IdentityKey(); len(key) > 0 {
+			privateKey, err := crypto.ToECDSA(key)
+			if err == nil {
+				return privateKey, nil
+			}
+			g.logger.Warn("failed to parse identity key, generating a new one", zap.Error(err))
+		}
+	}
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate identity key")
+	}
+
+	if !nilStorage(s) {
+		err = s.SetIdentityKey(crypto.FromECDSA(privateKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to save identity key")
+		}
+	}
+
+	return privateKey, nil
+}
+
+// verifyDealerSigner checks that the state was signed by the dealer.
+// The dealer key is pinned on the first signed state received in the room.
+// Another player is only accepted as a dealer after the pinned dealer went offline, see verifyNewDealer.
+func (g *Game) verifyDealerSigner(state *protocol.State, signer *ecdsa.PublicKey) bool {
+	if g.dealerKey != nil {
+		if signer != nil && signer.Equal(g.dealerKey) {
+			return true
+		}
+		if !g.verifyNewDealer(state, signer) {
+			return false
+		}
+		g.logger.Info("new dealer accepted", zap.Any("dealer", state.Dealer))
+		g.dealerKey = signer
+		return true
+	}
+
+	if signer == nil {
+		// Older dealers don't sign the state
+		return true
+	}
+
+	if len(state.DealerPublicKey) > 0 && !bytes.Equal(state.DealerPublicKey, crypto.FromECDSAPub(signer)) {
+		return false
+	}
+
+	g.dealerKey = signer
+	return true
+}
+
+// verifyNewDealer checks that the state was signed by the player that it claims to be the dealer,
+// that the current dealer is not online anymore and that the new dealer is the elected one, see electDealer.
+// Explicit transfers are announced by the current dealer itself, see pinDealerKey.
+func (g *Game) verifyNewDealer(state *protocol.State, signer *ecdsa.PublicKey) bool {
+	if state.Dealer == "" || g.signerPlayerID(signer) != state.Dealer {
+		return false
+	}
+	offline := g.clock.Now().Sub(g.dealerSeen)
+	if offline <= playerOnlineTimeout/2 {
+		return false
+	}
+	// Players notice the dealer going offline at slightly different times,
+	// so the new dealer might have been elected in the previous or the next round.
+	for _, skew := range []time.Duration{-playerOnlineTimeout / 2, 0, playerOnlineTimeout / 2} {
+		if state.Dealer == g.electDealer(max(offline+skew, playerOnlineTimeout)) {
+			return true
+		}
+	}
+	return false
+}
+
+// signerPlayerID returns the ID of the verified player in the room that owns the signer key.
+// Returns empty ID for unknown keys and for our own key.
+func (g *Game) signerPlayerID(signer *ecdsa.PublicKey) protocol.PlayerID {
+	if signer == nil || g.state == nil || signer.Equal(&g.privateKey.PublicKey) {
+		return ""
+	}
+	signerKey := crypto.FromECDSAPub(signer)
+	for _, player := range g.state.Players {
+		if player.Verified() && bytes.Equal(player.PublicKey, signerKey) {
+			return player.ID
+		}
+	}
+	return ""
+}
+
+// pinDealerKey follows the dealer public key shared in the state, e.g. when the dealer role was transferred.
+// Must only be called for states signed by the pinned dealer.
+func (g *Game) pinDealerKey(state *protocol.State) {
+	if g.dealerKey == nil || len(state.DealerPublicKey) == 0 {
+		return
+	}
+	if bytes.Equal(state.DealerPublicKey, crypto.FromECDSAPub(g.dealerKey)) {
+		return
+	}
+	publicKey, err := crypto.UnmarshalPubkey(state.DealerPublicKey)
+	if err != nil {
+		g.logger.Warn("failed to parse dealer public key", zap.Error(err))
+		return
+	}
+	g.logger.Info("dealer changed", zap.Any("dealer", state.Dealer))
+	g.dealerKey = publicKey
+}
+
+// verifyPlayerSigner checks that the message was signed by the known key of the player.
+// Players without a known key (new or unverified players) are always accepted, but they can't vote,
+// see handlePlayerVoteMessage.
+func (g *Game) verifyPlayerSigner(playerID protocol.PlayerID, signer *ecdsa.PublicKey) bool {
+	index := g.playerIndex(playerID)
+	if index < 0 {
+		return true
+	}
+
+	knownKey := g.state.Players[index].PublicKey
+	if len(knownKey) == 0 {
+		return true
+	}
+
+	return signer != nil && bytes.Equal(knownKey, crypto.FromECDSAPub(signer))
+}
+
+func publicKeyBytes(key *ecdsa.PublicKey) []byte {
+	if key == nil {
+		return nil
+	}
+	return crypto.FromECDSAPub(key)
+}
+
 func nilStorage(s storage.Service) bool {
 	return s == nil || reflect.ValueOf(s).IsNil()
 }
@@ -826,6 +2044,38 @@ func (g *Game) loadStateFromStorage(roomID protocol.RoomID) *protocol.State {
 	return state
 }
 
+// archiveRoomState saves the last known state of the room joined as a player, with the unconfirmed votes.
+func (g *Game) archiveRoomState() {
+	if !g.HasStorage() || g.isDealer || g.state == nil || g.archived {
+		return
+	}
+	err := g.storage.ArchiveRoomState(g.roomID, g.state, g.outbox.stored())
+	if err != nil {
+		g.logger.Error("failed to archive room state", zap.Error(err))
+	}
+}
+
+// loadArchivedState loads the last known state of the room joined as a player,
+// with the votes that were not confirmed by the dealer.
+func (g *Game) loadArchivedState(roomID protocol.RoomID) (*protocol.State, []storage.PendingVote) {
+	if !g.HasStorage() {
+		return nil, nil
+	}
+	state, outbox, err := g.storage.LoadArchivedRoomState(roomID)
+	if err != nil || state == nil {
+		g.logger.Info("room not found in archive", zap.Error(err))
+		return nil, nil
+	}
+	g.logger.Info("loaded room from archive", zap.Any("roomID", roomID), zap.Int("pendingVotes", len(outbox)))
+
+	// Online state is unknown until the dealer publishes the state
+	for i := range state.Players {
+		state.Players[i].Online = false
+	}
+
+	return state, outbox
+}
+
 func (g *Game) fillActiveIssueHint() {
 	if g.state == nil {
 		return
@@ -837,37 +2087,56 @@ func (g *Game) fillActiveIssueHint() {
 		return
 	}
 
+	strategy := g.hintStrategy()
+
 	var err error
-	item.Hint, err = GetResultHint(g.state.Deck, item.Votes)
+	item.Hint, err = strategy.Hint(g.state.Deck, g.votersVotes(item.Votes))
 	if err != nil {
 		g.logger.Error("failed to generate hint", zap.Error(err))
 	}
 }
 
-func (g *Game) scheduleAutoReveal() {
+// votersVotes returns the votes without the votes of observers, which are not counted in hints.
+// Votes of players that left the room are kept.
+func (g *Game) votersVotes(votes protocol.IssueVotes) protocol.IssueVotes {
+	voters := make(protocol.IssueVotes, len(votes))
+	for playerID, vote := range votes {
+		if player, found := g.state.Players.Get(playerID); found && player.Observer() {
+			continue
+		}
+		voters[playerID] = vote
+	}
+	return voters
+}
+
+func (g *Game) hintStrategy() HintStrategy {
+	strategy, err := NewHintStrategy(g.state.Hints)
+	if err != nil {
+		g.logger.Warn("invalid hint settings, using defaults", zap.Error(err))
+		strategy, _ = NewHintStrategy(nil)
+	}
+	return strategy
+}
+
+func (g *Game) scheduleAutoReveal(delay time.Duration) {
 	g.revealTimerLock.Lock()
 	defer g.revealTimerLock.Unlock()
 
-	g.logger.Debug("scheduling auto reveal")
+	g.logger.Debug("scheduling auto reveal", zap.Duration("delay", delay))
 
-	issueToReveal := g.state.ActiveIssue
-
-	g.events.Send(Event{
+	g.sendEvent(Event{
 		Tag:  EventAutoRevealScheduled,
-		Data: g.config.AutoRevealDelay,
+		Data: delay,
 	})
 
-	g.revealTimer = g.clock.AfterFunc(g.config.AutoRevealDelay, func() {
-		g.cancelAutoReveal()
-		go func() {
-			if g.state.ActiveIssue != issueToReveal {
-				g.logger.Debug("auto reveal cancelled: issue changed")
-			}
-			err := g.Reveal()
-			if err != nil {
-				g.logger.Warn("auto reveal failed", zap.Error(err))
-			}
-		}()
+	g.revealTimerID++
+	timeout := revealTimeout{
+		issue:         g.state.ActiveIssue,
+		revealTimerID: g.revealTimerID,
+	}
+	bound := g.forSession(g.session)
+	g.revealTimer = g.clock.AfterFunc(delay, func() {
+		bound.postRevealTimeout(timeout)
 	})
 }
 
@@ -883,8 +2152,129 @@ func (g *Game) cancelAutoReveal() {
 	g.revealTimer = nil
 
 	if cancelled {
-		g.events.Send(Event{
+		g.sendEvent(Event{
 			Tag: EventAutoRevealCancelled,
 		})
 	}
 }
+
+// startVotingTimebox sets the voting deadline of the active issue according to the room timebox.
+func (g *Game) startVotingTimebox() {
+	g.state.VotingDeadline = 0
+	if g.state.Timebox > 0 && g.state.VoteState() == protocol.VotingState {
+		g.state.VotingDeadline = g.timestamp() + g.state.Timebox
+	}
+}
+
+// updateDeadlineTimer makes sure that votes are revealed when the voting deadline expires.
+// Deadlines that already expired, e.g. while the dealer was offline, are revealed immediately.
+func (g *Game) updateDeadlineTimer() {
+	if g.state == nil || g.state.VotingDeadline == 0 || g.state.VoteState() != protocol.VotingState {
+		g.cancelDeadlineTimer()
+		return
+	}
+
+	g.deadlineLock.Lock()
+	defer g.deadlineLock.Unlock()
+
+	deadline := g.state.VotingDeadline
+	if g.deadlineTimer != nil {
+		if g.deadlineTimerAt == deadline {
+			return
+		}
+		g.deadlineTimer.Stop()
+	}
+
+	delay := max(time.UnixMilli(deadline).Sub(g.clock.Now()), 0)
+
+	g.logger.Debug("scheduling deadline reveal", zap.Duration("delay", delay))
+
+	timeout := revealTimeout{
+		issue:    g.state.ActiveIssue,
+		deadline: deadline,
+	}
+	bound := g.forSession(g.session)
+	g.deadlineTimerAt = deadline
+	g.deadlineTimer = g.clock.AfterFunc(delay, func() {
+		bound.postRevealTimeout(timeout)
+	})
+}
+
+func (g *Game) cancelDeadlineTimer() {
+	g.deadlineLock.Lock()
+	defer g.deadlineLock.Unlock()
+
+	if g.deadlineTimer == nil {
+		return
+	}
+
+	g.deadlineTimer.Stop()
+	g.deadlineTimer = nil
+	g.deadlineTimerAt = 0
+}
+
+// revealTimeout is posted to the session loop when a timer that reveals votes expires.
+// Timers never change the state themselves, it's only changed under the state lock.
+type revealTimeout struct {
+	issue         protocol.IssueID
+	revealTimerID int   // Set for the auto reveal timer
+	deadline      int64 // Set for the voting deadline timer
+}
+
+// postRevealTimeout hands the expired timer over to the session loop, see handleRevealTimeout.
+func (g *Game) postRevealTimeout(timeout revealTimeout) {
+	select {
+	case g.timeouts <- timeout:
+	case <-g.exitRoom:
+	case <-g.ctx.Done():
+	}
+}
+
+// handleRevealTimeout reveals the votes, unless the timer was cancelled or the vote changed since it expired.
+func (g *Game) handleRevealTimeout(timeout revealTimeout) {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if !g.expireRevealTimer(timeout) {
+		g.logger.Debug("reveal timeout ignored: timer cancelled")
+		return
+	}
+
+	if !g.isDealer || g.state.VoteState() != protocol.VotingState || g.state.ActiveIssue != timeout.issue {
+		g.logger.Debug("reveal timeout ignored: vote changed")
+		return
+	}
+	if timeout.deadline != 0 && g.state.VotingDeadline != timeout.deadline {
+		g.logger.Debug("reveal timeout ignored: deadline changed")
+		return
+	}
+
+	err := g.reveal()
+	if err != nil {
+		g.logger.Warn("reveal on timeout failed", zap.Error(err))
+	}
+}
+
+// expireRevealTimer forgets the expired timer. Returns false if it's not the current timer anymore.
+func (g *Game) expireRevealTimer(timeout revealTimeout) bool {
+	if timeout.deadline != 0 {
+		g.deadlineLock.Lock()
+		defer g.deadlineLock.Unlock()
+
+		if g.deadlineTimer == nil || g.deadlineTimerAt != timeout.deadline {
+			return false
+		}
+		g.deadlineTimer = nil
+		g.deadlineTimerAt = 0
+		return true
+	}
+
+	g.revealTimerLock.Lock()
+	defer g.revealTimerLock.Unlock()
+
+	if g.revealTimer == nil || g.revealTimerID != timeout.revealTimerID {
+		return false
+	}
+	g.revealTimer = nil
+	return true
+}
@@ -1,40 +1,230 @@
 package game
 
 import (
-	"encoding/json"
+	"crypto/ecdsa"
 
 	"go.uber.org/zap"
-	"golang.org/x/exp/slices"
 
 	"github.com/six78/2-story-points-cli/pkg/protocol"
 )
 
-func (g *Game) handleStateMessage(payload []byte) {
+func (g *Game) handleStateMessage(payload []byte, signer *ecdsa.PublicKey) {
 	var message protocol.GameStateMessage
-	err := json.Unmarshal(payload, &message)
+	err := protocol.Unmarshal(payload, &message)
 	if err != nil {
 		g.logger.Error("failed to unmarshal message", zap.Error(err))
 		return
 	}
 
-	g.logger.Info("state message received", zap.Any("state", message.State))
+	if g.isDealer {
+		g.handleAnotherDealerState(&message, signer)
+		return
+	}
+
+	if !g.verifyDealerSigner(&message.State, signer) {
+		g.logger.Warn("state message dropped: not signed by the dealer")
+		return
+	}
+	g.dealerSeen = g.clock.Now()
+
+	g.logger.Info("state message received",
+		zap.Int64("sequence", message.Sequence),
+		zap.Any("state", message.State))
+
+	// Zero sequence is sent by dealers that don't support state deltas
+	if message.Sequence != 0 && message.Sequence < g.stateSequence {
+		g.logger.Warn("state message ignored as outdated",
+			zap.Int64("sequence", message.Sequence),
+			zap.Int64("currentSequence", g.stateSequence))
+		return
+	}
+
+	g.updateState(&message.State, message.Sequence)
+}
+
+func (g *Game) handleStateDeltaMessage(payload []byte, signer *ecdsa.PublicKey) {
+	var message protocol.StateDeltaMessage
+	err := protocol.Unmarshal(payload, &message)
+	if err != nil {
+		g.logger.Error("failed to unmarshal message", zap.Error(err))
+		return
+	}
+
+	logger := g.logger.With(
+		zap.Int64("sequence", message.Sequence),
+		zap.Int64("baseSequence", message.BaseSequence),
+		zap.Int64("currentSequence", g.stateSequence),
+	)
+
+	if g.state == nil || g.archived {
+		logger.Info("state delta received before the state")
+		g.requestState()
+		return
+	}
+
+	if !g.verifyDealerSigner(g.state, signer) {
+		logger.Warn("state delta message dropped: not signed by the dealer")
+		if g.signerPlayerID(signer) != "" {
+			// Another player might have taken over the room, the full state will tell
+			g.requestState()
+		}
+		return
+	}
+	g.dealerSeen = g.clock.Now()
+
+	if message.Sequence <= g.stateSequence {
+		// Outdated delta or dealer announcing current sequence
+		return
+	}
+
+	if message.BaseSequence != g.stateSequence {
+		logger.Info("state delta gap detected")
+		g.requestState()
+		return
+	}
 
-	if g.state != nil && message.State.ActiveIssue != g.state.ActiveIssue {
-		// Voting finished or new issue dealt. Reset our vote.
+	logger.Info("state delta message received", zap.Any("delta", message.Delta))
+
+	state := g.state.Clone()
+	state.ApplyDelta(&message.Delta)
+	g.updateState(state, message.Sequence)
+}
+
+func (g *Game) updateState(state *protocol.State, sequence int64) {
+	if g.state != nil && (state.ActiveIssue != g.state.ActiveIssue || activeIssueRounds(state) != activeIssueRounds(g.state)) {
+		// Voting finished, new issue dealt or a new voting round started. Reset our vote.
 		g.resetMyVote()
 	}
 
-	g.state = &message.State
+	dealerChanged := g.state != nil && state.Dealer != g.state.Dealer
+
+	g.state = state
+	g.stateSequence = sequence
+	g.archived = false
+	g.pinDealerKey(state)
 	if g.state.Deck == nil {
 		// Fallback to FibonacciDeck deck, it was default before 1.2.0
 		g.state.Deck, _ = GetDeck(FibonacciDeck)
 	}
 	g.notifyChangedState(false)
+
+	if dealerChanged && g.myVote.Value != "" && g.state.VoteState() == protocol.VotingState {
+		// New dealer might not know our vote
+		err := g.publishVote(g.myVote.Value)
+		if err != nil {
+			g.logger.Warn("failed to publish vote to the new dealer", zap.Error(err))
+		}
+	}
+}
+
+func activeIssueRounds(state *protocol.State) int {
+	issue := state.GetActiveIssue()
+	if issue == nil {
+		return 0
+	}
+	return len(issue.Rounds)
+}
+
+// handleAnotherDealerState handles a state published by another player while we're the dealer.
+// This happens when the room was taken over while we were offline. The newer state wins.
+func (g *Game) handleAnotherDealerState(message *protocol.GameStateMessage, signer *ecdsa.PublicKey) {
+	dealer := g.signerPlayerID(signer)
+	if dealer == "" || dealer != message.State.Dealer {
+		// Own state or state of an unknown dealer
+		return
+	}
+
+	logger := g.logger.With(
+		zap.Any("dealer", dealer),
+		zap.Int64("sequence", message.Sequence),
+		zap.Int64("currentSequence", g.stateSequence),
+	)
+
+	if message.Sequence <= g.stateSequence {
+		logger.Warn("state of another dealer ignored as outdated")
+		return
+	}
+
+	logger.Info("room was taken over by another dealer")
+	g.stepDown(signer)
+	g.updateState(&message.State, message.Sequence)
 }
 
-func (g *Game) handlePlayerOnlineMessage(payload []byte) {
+func (g *Game) handleDealerTransferMessage(payload []byte, signer *ecdsa.PublicKey) {
+	var message protocol.DealerTransferMessage
+	err := protocol.Unmarshal(payload, &message)
+	if err != nil {
+		g.logger.Error("failed to unmarshal message", zap.Error(err))
+		return
+	}
+
+	if message.Dealer != g.player.ID {
+		return
+	}
+
+	if signer == nil || !g.verifyDealerSigner(&message.State, signer) {
+		g.logger.Warn("dealer transfer dropped: not signed by the dealer")
+		return
+	}
+
+	g.logger.Info("dealer role transferred", zap.Int64("sequence", message.Sequence))
+	g.becomeDealer(&message.State, message.Sequence, false)
+}
+
+// handleDealerOnlineMessage tracks the dealer liveness on the player side.
+func (g *Game) handleDealerOnlineMessage(payload []byte, signer *ecdsa.PublicKey) {
+	if g.state == nil || g.state.Dealer == "" {
+		return
+	}
+
 	var message protocol.PlayerOnlineMessage
-	err := json.Unmarshal(payload, &message)
+	err := protocol.Unmarshal(payload, &message)
+	if err != nil {
+		g.logger.Error("failed to unmarshal message", zap.Error(err))
+		return
+	}
+
+	if message.Player.ID != g.state.Dealer {
+		return
+	}
+
+	if g.dealerKey != nil && (signer == nil || !signer.Equal(g.dealerKey)) {
+		return
+	}
+
+	g.dealerSeen = g.clock.Now()
+}
+
+func (g *Game) handleStateRequestMessage(payload []byte, signer *ecdsa.PublicKey) {
+	var message protocol.StateRequestMessage
+	err := protocol.Unmarshal(payload, &message)
+	if err != nil {
+		g.logger.Error("failed to unmarshal message", zap.Error(err))
+		return
+	}
+
+	logger := g.logger.With(zap.Any("playerID", message.PlayerID))
+
+	if !g.verifyPlayerSigner(message.PlayerID, signer) {
+		logger.Warn("state request ignored as not signed by the player")
+		return
+	}
+
+	// Several players might request the state at the same time, one snapshot is enough
+	now := g.clock.Now()
+	if !g.snapshotTime.IsZero() && now.Sub(g.snapshotTime) < stateSnapshotPeriod {
+		logger.Debug("state request ignored as the state was just published")
+		return
+	}
+	g.snapshotTime = now
+
+	logger.Info("state requested", zap.Int64("sequence", message.Sequence))
+	g.publishState(g.hiddenCurrentState(), publishStateSnapshot)
+}
+
+func (g *Game) handlePlayerOnlineMessage(payload []byte, signer *ecdsa.PublicKey) {
+	var message protocol.PlayerOnlineMessage
+	err := protocol.Unmarshal(payload, &message)
 	if err != nil {
 		g.logger.Error("failed to unmarshal message", zap.Error(err))
 		return
@@ -43,6 +233,14 @@ func (g *Game) handlePlayerOnlineMessage(payload []byte) {
 	g.logger.Info("player online message received", zap.Any("player", message.Player))
 	message.Player.ApplyDeprecatedPatchOnReceive()
 
+	if !g.verifyPlayerSigner(message.Player.ID, signer) {
+		g.logger.Warn("player online message dropped: signature mismatch", zap.Any("player", message.Player))
+		return
+	}
+
+	// Never trust the key claimed in the message, only the actual signer
+	message.Player.PublicKey = publicKeyBytes(signer)
+
 	// TODO: Store player pointers in a map
 
 	index := g.playerIndex(message.Player.ID)
@@ -56,7 +254,13 @@ func (g *Game) handlePlayerOnlineMessage(payload []byte) {
 	}
 
 	playerChanged := !g.state.Players[index].Online ||
-		g.state.Players[index].Name != message.Player.Name
+		g.state.Players[index].Name != message.Player.Name ||
+		g.state.Players[index].Role != message.Player.Role ||
+		(!g.state.Players[index].Verified() && message.Player.Verified())
+
+	if message.Player.Verified() {
+		g.state.Players[index].PublicKey = message.Player.PublicKey
+	}
 
 	g.state.Players[index].OnlineTimestampMilliseconds = g.timestamp()
 
@@ -66,20 +270,40 @@ func (g *Game) handlePlayerOnlineMessage(payload []byte) {
 
 	g.state.Players[index].Online = true
 	g.state.Players[index].Name = message.Player.Name
+	g.state.Players[index].Role = message.Player.Role
+	if message.Player.Observer() {
+		g.removeObserverVote(message.Player.ID)
+	}
 	g.notifyChangedState(true)
 }
 
-func (g *Game) handlePlayerOfflineMessage(payload []byte) {
+// removeObserverVote removes the vote of a player who became an observer while voting.
+// Revealed votes are kept, they're only excluded from the hint.
+func (g *Game) removeObserverVote(playerID protocol.PlayerID) {
+	if g.state.VoteState() != protocol.VotingState {
+		return
+	}
+	if issue := g.state.GetActiveIssue(); issue != nil {
+		delete(issue.Votes, playerID)
+	}
+}
+
+func (g *Game) handlePlayerOfflineMessage(payload []byte, signer *ecdsa.PublicKey) {
 	if g.state == nil {
 		return
 	}
 	var message protocol.PlayerOfflineMessage
-	err := json.Unmarshal(payload, &message)
+	err := protocol.Unmarshal(payload, &message)
 	if err != nil {
 		g.logger.Error("failed to unmarshal message", zap.Error(err))
 		return
 	}
 
+	if !g.verifyPlayerSigner(message.Player.ID, signer) {
+		g.logger.Warn("player offline message dropped: signature mismatch", zap.Any("player", message.Player))
+		return
+	}
+
 	g.logger.Info("player is offline", zap.Any("player", message.Player))
 	index := g.playerIndex(message.Player.ID)
 	if index < 0 {
@@ -90,9 +314,9 @@ func (g *Game) handlePlayerOfflineMessage(payload []byte) {
 	g.notifyChangedState(true)
 }
 
-func (g *Game) handlePlayerVoteMessage(payload []byte) {
+func (g *Game) handlePlayerVoteMessage(payload []byte, signer *ecdsa.PublicKey) {
 	var message protocol.PlayerVoteMessage
-	err := json.Unmarshal(payload, &message)
+	err := protocol.Unmarshal(payload, &message)
 
 	if err != nil {
 		g.logger.Error("failed to unmarshal message", zap.Error(err))
@@ -102,12 +326,29 @@ func (g *Game) handlePlayerVoteMessage(payload []byte) {
 	logger := g.logger.With(zap.Any("playerID", message.PlayerID))
 	logger.Info("player vote message received")
 
+	// Otherwise anyone could vote on behalf of a player that is not in the room yet
+	player, found := g.state.Players.Get(message.PlayerID)
+	if !found || !player.Verified() {
+		logger.Warn("player vote ignored as the player is unknown or not verified")
+		return
+	}
+
+	if !g.verifyPlayerSigner(message.PlayerID, signer) {
+		logger.Warn("player vote ignored as not signed by the player")
+		return
+	}
+
 	if g.state.VoteState() != protocol.VotingState {
 		g.logger.Warn("player vote ignored as not in voting state")
 		return
 	}
 
-	if message.VoteResult.Value != "" && !slices.Contains(g.state.Deck, message.VoteResult.Value) {
+	if player.Observer() && message.VoteResult.Value != "" {
+		logger.Warn("player vote ignored as the player is an observer")
+		return
+	}
+
+	if message.VoteResult.Value != "" && !g.state.Deck.Contains(message.VoteResult.Value) {
 		logger.Warn("player vote ignored as not found in deck",
 			zap.Any("vote", message.VoteResult),
 			zap.Any("deck", g.state.Deck))
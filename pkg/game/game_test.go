@@ -2,13 +2,17 @@ package game
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
@@ -19,6 +23,7 @@ import (
 	"github.com/six78/2-story-points-cli/internal/transport"
 	mocktransport "github.com/six78/2-story-points-cli/internal/transport/mock"
 	"github.com/six78/2-story-points-cli/pkg/protocol"
+	"github.com/six78/2-story-points-cli/pkg/storage"
 	mockstorage "github.com/six78/2-story-points-cli/pkg/storage/mock"
 )
 
@@ -29,10 +34,11 @@ func TestGame(t *testing.T) {
 type Suite struct {
 	testcommon.Suite
 
-	ctx       context.Context
-	cancel    context.CancelFunc
-	transport *mocktransport.MockService
-	clock     clockwork.FakeClock
+	ctx          context.Context
+	cancel       context.CancelFunc
+	transport    *mocktransport.MockService
+	clock        clockwork.FakeClock
+	stateTracker *matchers.StateTracker
 }
 
 func (s *Suite) newGame(extraOptions []Option) *Game {
@@ -62,7 +68,11 @@ func (s *Suite) SetupTest() {
 
 	ctrl := gomock.NewController(s.T())
 	s.transport = mocktransport.NewMockService(ctrl)
+	s.transport.EXPECT().SubscribeToConnectionStatus().
+		Return(make(transport.ConnectionStatusSubscription)).
+		AnyTimes()
 	s.clock = clockwork.NewFakeClock()
+	s.stateTracker = matchers.NewStateTracker()
 }
 
 func (s *Suite) TearDownTest() {
@@ -70,7 +80,7 @@ func (s *Suite) TearDownTest() {
 }
 
 func (s *Suite) newStateMatcher() *matchers.StateMatcher {
-	return matchers.NewStateMatcher(s.T(), nil)
+	return matchers.NewStateMatcher(s.T(), nil).WithTracker(s.stateTracker)
 }
 
 func (s *Suite) expectSubscribeToMessages(room *protocol.Room) func(room *protocol.Room, payload []byte) {
@@ -81,7 +91,7 @@ func (s *Suite) expectSubscribeToMessages(room *protocol.Room) func(room *protoc
 		Unsubscribe: func() {},
 	}
 
-	s.transport.EXPECT().SubscribeToMessages(roomMatcher).
+	s.transport.EXPECT().SubscribeToMessages(roomMatcher, gomock.Any()).
 		Return(subscription, nil).
 		Times(1)
 
@@ -126,7 +136,7 @@ func (s *Suite) TestStateSize() {
 			ID:         issueID,
 			TitleOrURL: fmt.Sprintf("https://github.com/six78/waku-poker-planing/issues/%d", i),
 			Votes:      votes, // same votes for each issue, whatever
-			Result:     &deck[i%len(deck)],
+			Result:     &deck[i%len(deck)].Value,
 		})
 	}
 
@@ -170,8 +180,12 @@ func (s *Suite) TestSimpleGame() {
 	s.Require().False(state.VotesRevealed)
 	s.Require().Empty(state.ActiveIssue)
 	s.Require().Len(state.Players, 1)
+	s.Require().Equal(crypto.FromECDSAPub(&dealer.privateKey.PublicKey), state.DealerPublicKey)
 	s.Logger.Info("match on join room")
 
+	// Votes must be encrypted with dealer public key
+	dealerPublicKey := gomock.Eq(&dealer.privateKey.PublicKey)
+
 	// Deal first vote item
 
 	firstItemText := gofakeit.LetterN(10)
@@ -211,7 +225,7 @@ func (s *Suite) TestSimpleGame() {
 	{ // Publish dealer vote
 		voteMatcher := matchers.NewVoteMatcher(dealer.Player().ID, currentIssue.ID, dealerVote)
 		s.transport.EXPECT().
-			PublishPublicMessage(roomMatcher, voteMatcher).
+			PublishPrivateMessage(roomMatcher, voteMatcher, dealerPublicKey).
 			Times(1)
 
 		stateMatcher = s.newStateMatcher()
@@ -239,7 +253,7 @@ func (s *Suite) TestSimpleGame() {
 
 		voteMatcher := matchers.NewVoteMatcher(dealer.Player().ID, currentIssue.ID, "")
 		s.transport.EXPECT().
-			PublishPublicMessage(roomMatcher, voteMatcher).
+			PublishPrivateMessage(roomMatcher, voteMatcher, dealerPublicKey).
 			Times(1)
 
 		stateMatcher = s.newStateMatcher()
@@ -261,7 +275,7 @@ func (s *Suite) TestSimpleGame() {
 	{ // Publish dealer vote again
 		voteMatcher := matchers.NewVoteMatcher(dealer.Player().ID, currentIssue.ID, newDealerVote)
 		s.transport.EXPECT().
-			PublishPublicMessage(roomMatcher, voteMatcher).
+			PublishPrivateMessage(roomMatcher, voteMatcher, dealerPublicKey).
 			Times(1)
 
 		stateMatcher = s.newStateMatcher()
@@ -383,14 +397,35 @@ func (s *Suite) TestPublishMessage() {
 	testCases := []struct {
 		name       string
 		encryption bool
+		encoding   protocol.Encoding
+		private    bool
 	}{
 		{
 			name:       "encryption message",
 			encryption: true,
+			encoding:   protocol.EncodingJSON,
 		},
 		{
 			name:       "unencrypted message",
 			encryption: false,
+			encoding:   protocol.EncodingJSON,
+		},
+		{
+			name:       "protobuf message",
+			encryption: true,
+			encoding:   protocol.EncodingProtobuf,
+		},
+		{
+			name:       "private message",
+			encryption: true,
+			encoding:   protocol.EncodingJSON,
+			private:    true,
+		},
+		{
+			name:       "private message without symmetric encryption",
+			encryption: false,
+			encoding:   protocol.EncodingJSON,
+			private:    true,
 		},
 	}
 
@@ -399,9 +434,13 @@ func (s *Suite) TestPublishMessage() {
 			// Create controller inside subtest
 			ctrl := gomock.NewController(s.T())
 			s.transport = mocktransport.NewMockService(ctrl)
+			s.transport.EXPECT().SubscribeToConnectionStatus().
+				Return(make(transport.ConnectionStatusSubscription)).
+				AnyTimes()
 
 			game := s.newGame([]Option{
 				WithEnableSymmetricEncryption(tc.encryption),
+				WithEncoding(tc.encoding),
 			})
 
 			var err error
@@ -409,19 +448,42 @@ func (s *Suite) TestPublishMessage() {
 			s.Require().NoError(err)
 
 			roomMatcher := matchers.NewRoomMatcher(game.room)
-			payload, jsonPayload := s.FakePayload()
+			message := protocol.PlayerOnlineMessage{
+				Message: protocol.Message{
+					Type:      protocol.MessageTypePlayerOnline,
+					Timestamp: gofakeit.Int64(),
+				},
+				Player: *game.player,
+			}
+
+			payload, err := protocol.Marshal(message, tc.encoding)
+			s.Require().NoError(err)
+			s.Require().Equal(tc.encoding, protocol.DetectEncoding(payload))
+
+			signedPayload, err := protocol.SignMessage(payload, game.privateKey)
+			s.Require().NoError(err)
 
-			if tc.encryption {
+			var recipient *ecdsa.PublicKey
+			switch {
+			case tc.private:
+				// Private messages are never published in plaintext
+				recipientKey, err := crypto.GenerateKey()
+				s.Require().NoError(err)
+				recipient = &recipientKey.PublicKey
 				s.transport.EXPECT().
-					PublishPublicMessage(roomMatcher, gomock.Eq(jsonPayload)).
+					PublishPrivateMessage(roomMatcher, gomock.Eq(signedPayload), recipient).
 					Times(1)
-			} else {
+			case tc.encryption:
 				s.transport.EXPECT().
-					PublishUnencryptedMessage(roomMatcher, gomock.Eq(jsonPayload)).
+					PublishPublicMessage(roomMatcher, gomock.Eq(signedPayload)).
+					Times(1)
+			default:
+				s.transport.EXPECT().
+					PublishUnencryptedMessage(roomMatcher, gomock.Eq(signedPayload)).
 					Times(1)
 			}
 
-			err = game.publishMessage(payload)
+			err = game.publishMessageTo(message, recipient)
 			s.Require().NoError(err)
 		})
 	}
@@ -494,7 +556,7 @@ func (s *Suite) TestOnlineState() {
 		PublishPublicMessage(roomMatcher, stateMatcher).
 		Times(1)
 
-	dealer.handlePlayerOnlineMessage(playerOnlineMessage)
+	dealer.handlePlayerOnlineMessage(playerOnlineMessage, nil)
 
 	// Ensure new player joined
 	state := stateMatcher.Wait()
@@ -511,7 +573,8 @@ func (s *Suite) TestOnlineState() {
 
 	// Advance time, make sure player is marked as offline
 	lastSeenAt := p.OnlineTimestampMilliseconds
-	s.clock.Advance(playerOnlineTimeout)
+	s.clock.BlockUntil(2) // Wait for the players watch loop to start, next to the outbox loop
+	s.clock.Advance(playerOnlineTimeout + time.Second)
 
 	state = stateMatcher.Wait()
 	s.Require().Len(state.Players, 2)
@@ -522,6 +585,1332 @@ func (s *Suite) TestOnlineState() {
 	s.Require().Equal(lastSeenAt, p.OnlineTimestampMilliseconds)
 }
 
+func (s *Suite) TestForgedMessages() {
+	dealer := s.newGame([]Option{
+		WithAutoReveal(false, 0),
+	})
+
+	room, initialState, err := dealer.CreateNewRoom()
+	s.Require().NoError(err)
+
+	dealer.isDealer = true
+	dealer.room = room
+	dealer.roomID = room.ToRoomID()
+	dealer.state = initialState
+
+	s.transport.EXPECT().PublishPublicMessage(gomock.Any(), gomock.Any()).AnyTimes()
+
+	playerKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	attackerKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	signedPayload := func(message any, key *ecdsa.PrivateKey) []byte {
+		payload, err := json.Marshal(message)
+		s.Require().NoError(err)
+		payload, err = protocol.SignMessage(payload, key)
+		s.Require().NoError(err)
+		return payload
+	}
+
+	player := protocol.Player{
+		ID:   protocol.PlayerID(gofakeit.UUID()),
+		Name: gofakeit.Username(),
+	}
+
+	// Player joins, the key is pinned
+	dealer.handleMessage(signedPayload(protocol.PlayerOnlineMessage{
+		Message: protocol.Message{
+			Type:      protocol.MessageTypePlayerOnline,
+			Timestamp: s.clock.Now().UnixMilli(),
+		},
+		Player: player,
+	}, playerKey))
+
+	p, ok := dealer.CurrentState().Players.Get(player.ID)
+	s.Require().True(ok)
+	s.Require().True(p.Verified())
+	s.Require().Equal(crypto.FromECDSAPub(&playerKey.PublicKey), p.PublicKey)
+
+	_, err = dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+
+	vote := func(value protocol.VoteValue, key *ecdsa.PrivateKey) []byte {
+		return signedPayload(protocol.PlayerVoteMessage{
+			Message: protocol.Message{
+				Type:      protocol.MessageTypePlayerVote,
+				Timestamp: s.clock.Now().UnixMilli(),
+			},
+			PlayerID:   player.ID,
+			Issue:      dealer.CurrentState().ActiveIssue,
+			VoteResult: protocol.VoteResult{Value: value, Timestamp: s.clock.Now().UnixMilli()},
+		}, key)
+	}
+
+	// Forged vote is dropped
+	dealer.handleMessage(vote("1", attackerKey))
+	s.Require().Empty(dealer.CurrentState().GetActiveIssue().Votes)
+
+	// Tampered vote is dropped
+	payload := vote("2", playerKey)
+	payload = []byte(strings.Replace(string(payload), `"estimation":"2"`, `"estimation":"3"`, 1))
+	dealer.handleMessage(payload)
+	s.Require().Empty(dealer.CurrentState().GetActiveIssue().Votes)
+
+	// Unsigned vote is dropped for a verified player
+	unsignedPayload, err := json.Marshal(protocol.PlayerVoteMessage{
+		Message: protocol.Message{
+			Type:      protocol.MessageTypePlayerVote,
+			Timestamp: s.clock.Now().UnixMilli(),
+		},
+		PlayerID:   player.ID,
+		Issue:      dealer.CurrentState().ActiveIssue,
+		VoteResult: protocol.VoteResult{Value: "1", Timestamp: s.clock.Now().UnixMilli()},
+	})
+	s.Require().NoError(err)
+	dealer.handleMessage(unsignedPayload)
+	s.Require().Empty(dealer.CurrentState().GetActiveIssue().Votes)
+
+	// Vote of a player that is not in the room is dropped
+	ghostPayload := signedPayload(protocol.PlayerVoteMessage{
+		Message: protocol.Message{
+			Type:      protocol.MessageTypePlayerVote,
+			Timestamp: s.clock.Now().UnixMilli(),
+		},
+		PlayerID:   protocol.PlayerID(gofakeit.UUID()),
+		Issue:      dealer.CurrentState().ActiveIssue,
+		VoteResult: protocol.VoteResult{Value: "1", Timestamp: s.clock.Now().UnixMilli()},
+	}, attackerKey)
+	dealer.handleMessage(ghostPayload)
+	s.Require().Empty(dealer.CurrentState().GetActiveIssue().Votes)
+
+	// Vote of a player without a pinned key is dropped
+	unverified := protocol.Player{
+		ID:   protocol.PlayerID(gofakeit.UUID()),
+		Name: gofakeit.Username(),
+	}
+	unverifiedPayload, err := json.Marshal(protocol.PlayerOnlineMessage{
+		Message: protocol.Message{
+			Type:      protocol.MessageTypePlayerOnline,
+			Timestamp: s.clock.Now().UnixMilli(),
+		},
+		Player: unverified,
+	})
+	s.Require().NoError(err)
+	dealer.handleMessage(unverifiedPayload)
+	_, ok = dealer.CurrentState().Players.Get(unverified.ID)
+	s.Require().True(ok)
+
+	unverifiedPayload, err = json.Marshal(protocol.PlayerVoteMessage{
+		Message: protocol.Message{
+			Type:      protocol.MessageTypePlayerVote,
+			Timestamp: s.clock.Now().UnixMilli(),
+		},
+		PlayerID:   unverified.ID,
+		Issue:      dealer.CurrentState().ActiveIssue,
+		VoteResult: protocol.VoteResult{Value: "1", Timestamp: s.clock.Now().UnixMilli()},
+	})
+	s.Require().NoError(err)
+	dealer.handleMessage(unverifiedPayload)
+	s.Require().Empty(dealer.CurrentState().GetActiveIssue().Votes)
+
+	// Genuine vote is accepted
+	dealer.handleMessage(vote("5", playerKey))
+	votes := dealer.CurrentState().GetActiveIssue().Votes
+	s.Require().Len(votes, 1)
+	s.Require().Equal(protocol.VoteValue("5"), votes[player.ID].Value)
+}
+
+// newPublishedRoom creates a dealer and a player in the same room.
+// Published messages are returned by nextMessage, it's up to the test to deliver them.
+func (s *Suite) newPublishedRoom() (*Game, *Game, func(protocol.MessageType) []byte) {
+	options := []Option{
+		WithAutoReveal(false, 0),
+		WithEnablePublishOnlineState(false),
+	}
+	dealer := s.newGame(options)
+	player := s.newGame(options)
+
+	room, initialState, err := dealer.CreateNewRoom()
+	s.Require().NoError(err)
+
+	dealer.isDealer = true
+	dealer.room = room
+	dealer.roomID = room.ToRoomID()
+	dealer.state = initialState
+	dealer.resetStateSequence()
+
+	player.room = room
+	player.roomID = room.ToRoomID()
+
+	dealer.sessions.add(dealer.session)
+	player.sessions.add(player.session)
+
+	// Session loops are not running, handle the expired timers only
+	ctx := s.ctx
+	for _, game := range []*Game{dealer, player} {
+		go func(game *Game) {
+			for {
+				select {
+				case timeout := <-game.timeouts:
+					game.handleRevealTimeout(timeout)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(game)
+	}
+
+	published := make(chan []byte, 42)
+	publish := func(payload []byte) error {
+		published <- payload
+		return nil
+	}
+	s.transport.EXPECT().PublishPublicMessage(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(room *protocol.Room, payload []byte) error {
+			return publish(payload)
+		}).
+		AnyTimes()
+	s.transport.EXPECT().PublishPrivateMessage(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(room *protocol.Room, payload []byte, key *ecdsa.PublicKey) error {
+			return publish(payload)
+		}).
+		AnyTimes()
+
+	nextMessage := func(messageType protocol.MessageType) []byte {
+		select {
+		case payload := <-published:
+			message, err := protocol.UnmarshalMessage(payload)
+			s.Require().NoError(err)
+			s.Require().Equal(messageType, message.Type)
+			return payload
+		case <-time.After(1 * time.Second):
+			s.Require().Fail("timeout waiting for published message")
+		}
+		return nil
+	}
+
+	return dealer, player, nextMessage
+}
+
+func (s *Suite) TestStateDelta() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+
+	requireSameState := func() {
+		s.Require().True(dealer.hiddenCurrentState().Equal(player.CurrentState()))
+		s.Require().Equal(dealer.stateSequence, player.stateSequence)
+	}
+
+	// First state is published in full
+	dealer.notifyChangedState(true)
+	fullPayload := nextMessage(protocol.MessageTypeState)
+	player.handleMessage(fullPayload)
+	requireSameState()
+
+	// Further changes are published as deltas
+	_, err := dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	deltaPayload := nextMessage(protocol.MessageTypeStateDelta)
+	s.Require().Less(len(deltaPayload), len(fullPayload))
+	player.handleMessage(deltaPayload)
+	requireSameState()
+
+	// Missed delta is detected, player requests the full state
+	_, err = dealer.AddIssue(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	_ = nextMessage(protocol.MessageTypeStateDelta)
+
+	_, err = dealer.AddIssue(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Len(player.CurrentState().Issues, 1)
+
+	requestPayload := nextMessage(protocol.MessageTypeStateRequest)
+	dealer.handleMessage(requestPayload)
+	player.handleMessage(nextMessage(protocol.MessageTypeState))
+	requireSameState()
+	s.Require().Len(player.CurrentState().Issues, 3)
+
+	// No changes, dealer only announces the current sequence
+	dealer.publishState(dealer.hiddenCurrentState(), publishStateHeartbeat)
+	heartbeatPayload := nextMessage(protocol.MessageTypeStateDelta)
+	player.handleMessage(heartbeatPayload)
+	requireSameState()
+
+	// Full state is published periodically for clients that don't support deltas
+	dealer.publishState(dealer.hiddenCurrentState(), publishStateHeartbeat)
+	player.handleMessage(nextMessage(protocol.MessageTypeState))
+	requireSameState()
+	dealer.publishState(dealer.hiddenCurrentState(), publishStateHeartbeat)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	requireSameState()
+}
+
+// joinPublishedRoom delivers the initial state to the player and makes the player verified in the room.
+func (s *Suite) joinPublishedRoom(dealer *Game, player *Game, nextMessage func(protocol.MessageType) []byte) {
+	dealer.notifyChangedState(true)
+	player.handleMessage(nextMessage(protocol.MessageTypeState))
+
+	player.publishUserOnline(true)
+	dealer.handleMessage(nextMessage(protocol.MessageTypePlayerOnline))
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	p, ok := player.CurrentState().Players.Get(player.Player().ID)
+	s.Require().True(ok)
+	s.Require().True(p.Verified())
+	s.Require().Equal(dealer.Player().ID, player.CurrentState().Dealer)
+}
+
+func (s *Suite) TestDealerTransfer() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	_, err := dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	const dealerVote = protocol.VoteValue("3")
+	err = dealer.PublishVote(dealerVote)
+	s.Require().NoError(err)
+	dealer.handleMessage(nextMessage(protocol.MessageTypePlayerVote))
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	// Only dealer can transfer the role, and only to a known player
+	err = player.TransferDealer(dealer.Player().ID)
+	s.Require().Error(err)
+	err = dealer.TransferDealer(protocol.PlayerID(gofakeit.UUID()))
+	s.Require().Error(err)
+
+	err = dealer.TransferDealer(player.Player().ID)
+	s.Require().NoError(err)
+	s.Require().False(dealer.IsDealer())
+
+	transferPayload := nextMessage(protocol.MessageTypeDealerTransfer)
+	_ = nextMessage(protocol.MessageTypeStateDelta) // Announces the new dealer to other players
+
+	player.handleMessage(transferPayload)
+	s.Require().True(player.IsDealer())
+	s.Require().Equal(player.Player().ID, player.CurrentState().Dealer)
+	s.Require().Equal(crypto.FromECDSAPub(&player.privateKey.PublicKey), player.CurrentState().DealerPublicKey)
+
+	// New dealer knows the hidden votes
+	vote, ok := player.CurrentState().GetActiveIssue().Votes[dealer.Player().ID]
+	s.Require().True(ok)
+	s.Require().Equal(dealerVote, vote.Value)
+
+	// Previous dealer follows the new one
+	dealer.handleMessage(nextMessage(protocol.MessageTypeState))
+	s.Require().Equal(player.Player().ID, dealer.CurrentState().Dealer)
+	s.Require().Equal(player.stateSequence, dealer.stateSequence)
+	s.Require().True(dealer.dealerKey.Equal(&player.privateKey.PublicKey))
+}
+
+func (s *Suite) TestDealerFailover() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	_, err := dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	// Player takes over when the dealer is offline
+	player.startRoleRoutines()
+	s.clock.BlockUntil(3) // Wait for the dealer watch loop to start, next to the outbox loops of both games
+	s.clock.Advance(playerOnlineTimeout / 2)
+	s.Require().False(player.IsDealer())
+
+	s.clock.Advance(playerOnlineTimeout/2 + time.Second)
+	statePayload := nextMessage(protocol.MessageTypeState)
+	s.Require().True(player.IsDealer())
+
+	state := player.CurrentState()
+	s.Require().Equal(player.Player().ID, state.Dealer)
+	s.Require().NotEmpty(state.ActiveIssue)
+	p, ok := state.Players.Get(dealer.Player().ID)
+	s.Require().True(ok)
+	s.Require().False(p.Online)
+
+	// Dealer is back online and steps down
+	dealer.handleMessage(statePayload)
+	s.Require().False(dealer.IsDealer())
+	s.Require().Equal(player.Player().ID, dealer.CurrentState().Dealer)
+	s.Require().True(dealer.dealerKey.Equal(&player.privateKey.PublicKey))
+}
+
+func (s *Suite) TestCurrentStateCopy() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	_, err := dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	_ = nextMessage(protocol.MessageTypeStateDelta)
+
+	// Changing the returned state doesn't change the game
+	state := dealer.CurrentState()
+	state.GetActiveIssue().Votes[dealer.Player().ID] = protocol.VoteResult{Value: "1"}
+	state.Players[0].Name = gofakeit.LetterN(10)
+	s.Require().Empty(dealer.CurrentState().GetActiveIssue().Votes)
+	s.Require().Equal(dealer.Player().Name, dealer.CurrentState().Players[0].Name)
+
+	// State can be read while the game changes it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = dealer.CurrentState()
+			_ = dealer.IsDealer()
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		_, err = dealer.AddIssue(gofakeit.LetterN(10))
+		s.Require().NoError(err)
+		_ = nextMessage(protocol.MessageTypeStateDelta)
+	}
+	<-done
+	s.Require().Len(dealer.CurrentState().Issues, 11)
+}
+
+func (s *Suite) TestDealerTakeoverNotElected() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+
+	players := []*Game{player}
+	for i := 0; i < 2; i++ {
+		other := s.newGame([]Option{
+			WithAutoReveal(false, 0),
+			WithEnablePublishOnlineState(false),
+		})
+		other.room = player.room
+		other.roomID = player.roomID
+		other.sessions.add(other.session)
+		players = append(players, other)
+	}
+
+	deliver := func(payload []byte, players ...*Game) {
+		for _, p := range players {
+			p.handleMessage(payload)
+		}
+	}
+
+	dealer.notifyChangedState(true)
+	deliver(nextMessage(protocol.MessageTypeState), players...)
+	for _, p := range players {
+		p.publishUserOnline(true)
+		dealer.handleMessage(nextMessage(protocol.MessageTypePlayerOnline))
+		deliver(nextMessage(protocol.MessageTypeStateDelta), players...)
+	}
+
+	// Dealer goes offline, all players agree on the elected one
+	offline := playerOnlineTimeout + time.Second
+	s.clock.Advance(offline)
+	electedID := player.electDealer(offline)
+	for i, p := range players {
+		s.Require().Equal(electedID, p.electDealer(offline))
+		if p.Player().ID == electedID {
+			players[0], players[i] = players[i], players[0]
+		}
+	}
+	elected, notElected, follower := players[0], players[1], players[2]
+	s.Require().Equal(electedID, elected.Player().ID)
+
+	// Not elected player can't take over
+	notElected.becomeDealer(notElected.CurrentState().Clone(), notElected.stateSequence, true)
+	deliver(nextMessage(protocol.MessageTypeState), elected, follower)
+	for _, p := range []*Game{elected, follower} {
+		s.Require().Equal(dealer.Player().ID, p.CurrentState().Dealer)
+		s.Require().True(p.dealerKey.Equal(&dealer.privateKey.PublicKey))
+	}
+
+	// Elected player takes over
+	elected.becomeDealer(elected.CurrentState().Clone(), elected.stateSequence, true)
+	deliver(nextMessage(protocol.MessageTypeState), follower)
+	s.Require().Equal(elected.Player().ID, follower.CurrentState().Dealer)
+	s.Require().True(follower.dealerKey.Equal(&elected.privateKey.PublicKey))
+}
+
+func (s *Suite) TestVoteOutbox() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	_, err := dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().False(player.VotePending())
+
+	// Vote is lost, it stays pending
+	err = player.PublishVote("3")
+	s.Require().NoError(err)
+	lostPayload := nextMessage(protocol.MessageTypePlayerVote)
+	s.Require().True(player.VotePending())
+
+	// Vote is published again
+	s.clock.BlockUntil(2) // Wait for the outbox loops of both games
+	s.clock.Advance(outboxRetryPeriod)
+	retryPayload := nextMessage(protocol.MessageTypePlayerVote)
+	s.Require().Equal(lostPayload, retryPayload)
+	s.Require().True(player.VotePending())
+
+	// Dealer state confirms the vote
+	dealer.handleMessage(retryPayload)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().False(player.VotePending())
+	s.Require().Empty(player.outbox.pending())
+
+	// Nothing is published again
+	s.clock.Advance(outboxRetryPeriod)
+	s.Require().Never(func() bool {
+		return len(player.outbox.pending()) > 0
+	}, 50*time.Millisecond, 10*time.Millisecond)
+}
+
+func (s *Suite) TestVoteDelivery() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	_, err := dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Equal(VoteNotSent, player.VoteDelivery())
+
+	// Hidden vote in the dealer's state confirms the delivery
+	err = player.PublishVote("3")
+	s.Require().NoError(err)
+	s.Require().Equal(VoteSent, player.VoteDelivery())
+
+	dealer.handleMessage(nextMessage(protocol.MessageTypePlayerVote))
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Equal(VoteReceived, player.VoteDelivery())
+
+	// Changed vote is lost, votes are revealed without it
+	s.clock.Advance(time.Second)
+	err = player.PublishVote("5")
+	s.Require().NoError(err)
+	_ = nextMessage(protocol.MessageTypePlayerVote)
+	s.Require().Equal(VoteSent, player.VoteDelivery())
+
+	err = dealer.Reveal()
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Equal(VoteLost, player.VoteDelivery())
+}
+
+func (s *Suite) TestHintSettings() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	_, err := dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	for _, game := range []*Game{player, dealer} {
+		vote := protocol.VoteValue("1")
+		if game == dealer {
+			vote = "3"
+		}
+		err = game.PublishVote(vote)
+		s.Require().NoError(err)
+		dealer.handleMessage(nextMessage(protocol.MessageTypePlayerVote))
+		player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	}
+
+	err = dealer.Reveal()
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	activeHint := func() *protocol.Hint {
+		issue := player.CurrentState().GetActiveIssue()
+		s.Require().NotNil(issue)
+		s.Require().NotNil(issue.Hint)
+		return issue.Hint
+	}
+	s.Require().Equal(protocol.VoteValue("3"), activeHint().Value)
+
+	// Hint is recalculated with the strategy chosen by the dealer
+	settings := protocol.HintSettings{Strategy: protocol.MeanHintStrategy, MaxDeviation: 2}
+	err = dealer.SetHintSettings(settings)
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Equal(&settings, player.CurrentState().Hints)
+	s.Require().Equal(protocol.VoteValue("2"), activeHint().Value)
+
+	// Default settings are not carried in the state
+	err = dealer.SetHintSettings(protocol.HintSettings{})
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Nil(player.CurrentState().Hints)
+	s.Require().Equal(protocol.VoteValue("3"), activeHint().Value)
+
+	err = dealer.SetHintSettings(protocol.HintSettings{Strategy: "unknown"})
+	s.Require().ErrorIs(err, ErrUnknownHintStrategy)
+
+	err = player.SetHintSettings(settings)
+	s.Require().Error(err)
+}
+
+func (s *Suite) TestEditIssues() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	requireIssues := func(titles ...string) {
+		player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+		s.Require().True(dealer.hiddenCurrentState().Equal(player.CurrentState()))
+		s.Require().Len(player.CurrentState().Issues, len(titles))
+		for i, title := range titles {
+			s.Require().Equal(title, player.CurrentState().Issues[i].TitleOrURL)
+		}
+	}
+
+	titles := []string{"a", "b", "c", "d"}
+	for i, title := range titles {
+		_, err := dealer.AddIssue(title)
+		s.Require().NoError(err)
+		requireIssues(titles[:i+1]...)
+	}
+
+	err := dealer.MoveIssue(3, 0)
+	s.Require().NoError(err)
+	requireIssues("d", "a", "b", "c")
+
+	err = dealer.MoveIssue(0, 2)
+	s.Require().NoError(err)
+	requireIssues("a", "b", "d", "c")
+
+	err = dealer.EditIssue(1, "e")
+	s.Require().NoError(err)
+	requireIssues("a", "e", "d", "c")
+
+	err = dealer.EditIssue(1, "a")
+	s.Require().Error(err) // Duplicate
+
+	err = dealer.EditIssue(1, "e")
+	s.Require().NoError(err) // Unchanged, nothing is published
+
+	err = dealer.RemoveIssue(3)
+	s.Require().NoError(err)
+	requireIssues("a", "e", "d")
+
+	// Active issue can't be removed while voting
+	err = dealer.SelectIssue(1)
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	err = dealer.RemoveIssue(1)
+	s.Require().Error(err)
+
+	err = dealer.Reveal()
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	err = dealer.RemoveIssue(1)
+	s.Require().Error(err)
+
+	// Other issues can be removed
+	err = dealer.RemoveIssue(0)
+	s.Require().NoError(err)
+	requireIssues("e", "d")
+	s.Require().Equal(protocol.RevealedState, player.CurrentState().VoteState())
+
+	// Finished issue can be removed, the next one is dealt
+	err = dealer.Finish("1")
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	err = dealer.RemoveIssue(1)
+	s.Require().Error(err)
+
+	err = dealer.RemoveIssue(0)
+	s.Require().NoError(err)
+	requireIssues("d")
+
+	for _, index := range []int{-1, 1} {
+		s.Require().Error(dealer.RemoveIssue(index))
+		s.Require().Error(dealer.EditIssue(index, "f"))
+		s.Require().Error(dealer.MoveIssue(index, 0))
+		s.Require().Error(dealer.MoveIssue(0, index))
+	}
+
+	s.Require().Error(player.RemoveIssue(0))
+	s.Require().Error(player.EditIssue(0, "f"))
+	s.Require().Error(player.MoveIssue(0, 0))
+}
+
+func (s *Suite) TestRevote() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	_, err := dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	vote := func(value protocol.VoteValue) {
+		err := player.PublishVote(value)
+		s.Require().NoError(err)
+		dealer.handleMessage(nextMessage(protocol.MessageTypePlayerVote))
+		player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	}
+
+	err = dealer.Revote()
+	s.Require().Error(err) // Votes are not revealed
+
+	vote("1")
+	err = dealer.Reveal()
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	err = player.Revote()
+	s.Require().Error(err)
+
+	// Revealed votes are kept in the first round
+	s.clock.Advance(time.Second)
+	err = dealer.Revote()
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().True(dealer.hiddenCurrentState().Equal(player.CurrentState()))
+
+	issue := player.CurrentState().GetActiveIssue()
+	s.Require().NotNil(issue)
+	s.Require().Equal(protocol.VotingState, player.CurrentState().VoteState())
+	s.Require().Empty(issue.Votes)
+	s.Require().Len(issue.Rounds, 1)
+	s.Require().Equal(protocol.VoteValue("1"), issue.Rounds[0].Votes[player.Player().ID].Value)
+	s.Require().Nil(issue.Rounds[0].Result) // Voted again before finished
+	s.Require().Equal(s.clock.Now().UnixMilli(), issue.Rounds[0].Timestamp)
+	s.Require().NotNil(issue.Rounds[0].Hint)
+	s.Require().Equal(protocol.VoteValue("1"), issue.Rounds[0].Hint.Value)
+	s.Require().Empty(player.MyVote().Value)
+
+	// Second round
+	vote("2")
+	err = dealer.Reveal()
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	err = dealer.Finish("2")
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	// Dealing a finished issue again keeps its votes too
+	err = dealer.SelectIssue(0)
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	issue = player.CurrentState().GetActiveIssue()
+	s.Require().NotNil(issue)
+	s.Require().Nil(issue.Result)
+	s.Require().Len(issue.Rounds, 2)
+	s.Require().Equal(protocol.VoteValue("2"), issue.Rounds[1].Votes[player.Player().ID].Value)
+	s.Require().NotNil(issue.Rounds[1].Result)
+	s.Require().Equal(protocol.VoteValue("2"), *issue.Rounds[1].Result)
+
+	// Hints of the rounds are kept when another issue is dealt
+	vote("3")
+	err = dealer.Reveal()
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	err = dealer.Finish("3")
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	_, err = dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	issue = player.CurrentState().Issues[0]
+	s.Require().NotEqual(player.CurrentState().ActiveIssue, issue.ID)
+	s.Require().Len(issue.Rounds, 2)
+	for _, round := range issue.Rounds {
+		s.Require().NotNil(round.Hint)
+	}
+
+	// Hidden votes are not wiped by dealing the issue again
+	vote("5")
+	err = dealer.SelectIssue(1)
+	s.Require().Error(err)
+
+	// Votes of an issue that wasn't finished are kept when it's dealt again
+	err = dealer.SelectIssue(0)
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	err = dealer.SelectIssue(1)
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	issue = player.CurrentState().GetActiveIssue()
+	s.Require().NotNil(issue)
+	s.Require().Empty(issue.Votes)
+	s.Require().Len(issue.Rounds, 1)
+	s.Require().Nil(issue.Rounds[0].Result)
+	s.Require().Equal(protocol.VoteValue("5"), issue.Rounds[0].Votes[player.Player().ID].Value)
+}
+
+func (s *Suite) TestAutoRevealSettings() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	settings := protocol.AutoRevealSettings{Policy: protocol.AutoRevealQuorum, Quorum: 50, Delay: ptr[int64](2000)}
+	err := player.SetAutoReveal(settings)
+	s.Require().Error(err)
+	err = dealer.SetAutoReveal(protocol.AutoRevealSettings{Policy: "unknown"})
+	s.Require().ErrorIs(err, ErrUnknownAutoRevealPolicy)
+
+	err = dealer.SetAutoReveal(settings)
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Equal(&settings, player.CurrentState().AutoReveal)
+
+	_, err = dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	// Half of the players voted, votes are revealed after the delay
+	err = player.PublishVote("1")
+	s.Require().NoError(err)
+	dealer.handleMessage(nextMessage(protocol.MessageTypePlayerVote))
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().False(player.CurrentState().VotesRevealed)
+
+	s.clock.Advance(2 * time.Second)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().True(player.CurrentState().VotesRevealed)
+
+	// Votes are only revealed by the dealer when auto reveal is off
+	err = dealer.Finish("1")
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	err = dealer.SetAutoReveal(protocol.AutoRevealSettings{Policy: protocol.AutoRevealOff})
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	_, err = dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	for _, game := range []*Game{player, dealer} {
+		err = game.PublishVote("1")
+		s.Require().NoError(err)
+		dealer.handleMessage(nextMessage(protocol.MessageTypePlayerVote))
+		player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	}
+	s.clock.Advance(time.Minute)
+	s.Require().Nil(dealer.revealTimer)
+	s.Require().Equal(protocol.VotingState, dealer.CurrentState().VoteState())
+
+	// Missing delay means the dealer default, while zero delay is kept
+	s.Require().Equal(dealer.config.AutoRevealDelay.Milliseconds(), *dealer.autoRevealSettings().Delay)
+
+	err = dealer.SetAutoReveal(protocol.AutoRevealSettings{Policy: protocol.AutoRevealOff, Delay: ptr[int64](0)})
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().NotNil(player.CurrentState().AutoReveal.Delay)
+	s.Require().Zero(*player.CurrentState().AutoReveal.Delay)
+	s.Require().Zero(*dealer.autoRevealSettings().Delay)
+
+	// Default settings are not carried in the state
+	err = dealer.SetAutoReveal(protocol.AutoRevealSettings{})
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Nil(player.CurrentState().AutoReveal)
+}
+
+func (s *Suite) TestObserver() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	vote := func(game *Game, value protocol.VoteValue) {
+		err := game.PublishVote(value)
+		s.Require().NoError(err)
+		dealer.handleMessage(nextMessage(protocol.MessageTypePlayerVote))
+		player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	}
+	setRole := func(role protocol.PlayerRole) {
+		err := player.SetPlayerRole(role)
+		s.Require().NoError(err)
+		dealer.handleMessage(nextMessage(protocol.MessageTypePlayerOnline))
+		player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+		p, ok := player.CurrentState().Players.Get(player.Player().ID)
+		s.Require().True(ok)
+		s.Require().Equal(role, p.Role)
+	}
+
+	err := player.SetPlayerRole("unknown")
+	s.Require().Error(err)
+
+	_, err = dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	vote(player, "1")
+	vote(dealer, "3")
+	err = dealer.Reveal()
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	// Revealed votes of observers are kept, but not counted in the hint
+	setRole(protocol.ObserverRole)
+	issue := player.CurrentState().GetActiveIssue()
+	s.Require().Len(issue.Votes, 2)
+	s.Require().NotNil(issue.Hint)
+	s.Require().Equal(protocol.VoteValue("3"), issue.Hint.Value)
+	s.Require().True(issue.Hint.Acceptable)
+
+	err = dealer.Finish("3")
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	_, err = dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	// Observers can't vote and are not waited for
+	err = player.PublishVote("1")
+	s.Require().Error(err)
+	vote(dealer, "2")
+	s.Require().True(player.CurrentState().AllPlayersVoted())
+
+	setRole(protocol.VoterRole)
+	s.Require().False(player.CurrentState().AllPlayersVoted())
+	vote(player, "2")
+	s.Require().True(player.CurrentState().AllPlayersVoted())
+
+	// Vote is retracted when the player becomes an observer during the voting
+	setRole(protocol.ObserverRole)
+	issue = player.CurrentState().GetActiveIssue()
+	s.Require().Len(issue.Votes, 1)
+	s.Require().Empty(player.MyVote().Value)
+	s.Require().False(player.VotePending())
+}
+
+func (s *Suite) TestTimebox() {
+	dealer, player, nextMessage := s.newPublishedRoom()
+	s.joinPublishedRoom(dealer, player, nextMessage)
+
+	err := player.SetTimebox(time.Minute)
+	s.Require().Error(err)
+	err = dealer.SetTimebox(-time.Minute)
+	s.Require().Error(err)
+	err = dealer.SetVotingDeadline(time.Minute)
+	s.Require().Error(err) // Voting is not in progress
+
+	err = dealer.SetTimebox(time.Minute)
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Equal(time.Minute.Milliseconds(), player.CurrentState().Timebox)
+	s.Require().Zero(player.CurrentState().VotingDeadline)
+
+	// Deal starts the countdown
+	_, err = dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	deadline := s.clock.Now().Add(time.Minute).UnixMilli()
+	s.Require().Equal(deadline, player.CurrentState().VotingDeadline)
+
+	err = player.PublishVote("1")
+	s.Require().NoError(err)
+	dealer.handleMessage(nextMessage(protocol.MessageTypePlayerVote))
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	// Votes are revealed when the deadline expires, even though the dealer didn't vote
+	s.clock.Advance(time.Minute)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().True(player.CurrentState().VotesRevealed)
+	s.Require().Zero(player.CurrentState().VotingDeadline)
+
+	err = dealer.Finish("1")
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Zero(player.CurrentState().VotingDeadline)
+
+	// Countdown of the current vote can be changed or stopped
+	_, err = dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+
+	err = player.SetVotingDeadline(10 * time.Second)
+	s.Require().Error(err)
+	err = dealer.SetVotingDeadline(10 * time.Second)
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Equal(s.clock.Now().Add(10*time.Second).UnixMilli(), player.CurrentState().VotingDeadline)
+
+	err = dealer.SetVotingDeadline(0)
+	s.Require().NoError(err)
+	player.handleMessage(nextMessage(protocol.MessageTypeStateDelta))
+	s.Require().Zero(player.CurrentState().VotingDeadline)
+
+	s.clock.Advance(time.Minute)
+	s.Require().Equal(protocol.VotingState, dealer.CurrentState().VoteState())
+	s.Require().Nil(dealer.deadlineTimer)
+
+	// Timeout of a cancelled timer is ignored
+	dealer.handleRevealTimeout(revealTimeout{issue: dealer.CurrentState().ActiveIssue, deadline: deadline})
+	s.Require().Equal(protocol.VotingState, dealer.CurrentState().VoteState())
+}
+
+func (s *Suite) TestLoopbackGame() {
+	network := transport.NewLoopbackNetwork()
+
+	newLoopbackGame := func(publishStateLoop bool) *Game {
+		node := network.NewNode(s.Logger)
+		err := node.Start()
+		s.Require().NoError(err)
+		s.T().Cleanup(node.Stop)
+
+		return s.newGame([]Option{
+			WithTransport(node),
+			WithAutoReveal(false, 0),
+			WithPublishStateLoop(publishStateLoop),
+		})
+	}
+
+	dealer := newLoopbackGame(true)
+	player := newLoopbackGame(false)
+
+	room, initialState, err := dealer.CreateNewRoom()
+	s.Require().NoError(err)
+	roomID := room.ToRoomID()
+
+	err = dealer.JoinRoom(roomID, initialState)
+	s.Require().NoError(err)
+	err = player.JoinRoom(roomID, nil)
+	s.Require().NoError(err)
+	s.Require().False(player.IsDealer())
+
+	// Player receives the state with both players.
+	// Messages might be reordered, advance the clock to let the heartbeat and state requests recover.
+	s.Require().Eventually(func() bool {
+		s.clock.Advance(stateRequestTimeout)
+		state := player.CurrentState()
+		return state != nil && len(state.Players) == 2
+	}, time.Second, 50*time.Millisecond)
+
+	issueID, err := dealer.Deal(gofakeit.URL())
+	s.Require().NoError(err)
+
+	s.Require().Eventually(func() bool {
+		return player.CurrentState().ActiveIssue == issueID
+	}, time.Second, 10*time.Millisecond)
+
+	// Private vote reaches the dealer
+	err = player.PublishVote("3")
+	s.Require().NoError(err)
+
+	s.Require().Eventually(func() bool {
+		issue := dealer.CurrentState().Issues.Get(issueID)
+		_, voted := issue.Votes[player.Player().ID]
+		return voted
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *Suite) TestMultipleRooms() {
+	dealer := s.newGame([]Option{
+		WithAutoReveal(false, 0),
+		WithEnablePublishOnlineState(false),
+	})
+
+	observerAnnounced := make(chan protocol.RoomID, 42)
+	s.transport.EXPECT().PublishPublicMessage(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(room *protocol.Room, payload []byte) error {
+			message := protocol.PlayerOnlineMessage{}
+			err := protocol.Unmarshal(payload, &message)
+			if err == nil && message.Type == protocol.MessageTypePlayerOnline && message.Player.Observer() {
+				observerAnnounced <- room.ToRoomID()
+			}
+			return nil
+		}).
+		AnyTimes()
+
+	joinNewRoom := func() protocol.RoomID {
+		room, initialState, err := dealer.CreateNewRoom()
+		s.Require().NoError(err)
+		s.expectSubscribeToMessages(room)
+		err = dealer.JoinRoom(room.ToRoomID(), initialState)
+		s.Require().NoError(err)
+		return room.ToRoomID()
+	}
+
+	room1 := joinNewRoom()
+	room2 := joinNewRoom()
+	s.Require().Equal([]protocol.RoomID{room1, room2}, dealer.Rooms())
+	s.Require().Equal(room2, dealer.RoomID())
+	s.Require().True(dealer.IsDealer())
+
+	sub := dealer.Subscribe()
+
+	// Switching room notifies the state of the room
+	err := dealer.SwitchRoom(room1)
+	s.Require().NoError(err)
+	s.Require().Equal(room1, dealer.RoomID())
+	event := <-sub.Events
+	s.Require().Equal(EventStateChanged, event.Tag)
+	s.Require().Equal(dealer.CurrentState(), event.Data)
+
+	// Rooms are played independently
+	_, err = dealer.Deal(gofakeit.LetterN(10))
+	s.Require().NoError(err)
+	s.Require().Len(dealer.CurrentState().Issues, 1)
+
+	// Joining already joined room switches to it
+	err = dealer.JoinRoom(room2, nil)
+	s.Require().NoError(err)
+	s.Require().Equal(room2, dealer.RoomID())
+	s.Require().Empty(dealer.CurrentState().Issues)
+
+	err = dealer.SwitchRoom(protocol.NewRoomID(gofakeit.LetterN(5)))
+	s.Require().Error(err)
+
+	// Role is announced in all rooms
+	err = dealer.SetPlayerRole(protocol.ObserverRole)
+	s.Require().NoError(err)
+	s.Require().ElementsMatch([]protocol.RoomID{room1, room2}, []protocol.RoomID{<-observerAnnounced, <-observerAnnounced})
+
+	// Leaving the room switches to the remaining one, only the routines of the left room are stopped
+	left := dealer.session
+	dealer.LeaveRoom()
+	s.Require().NotNil(left.exitRoom)
+	s.Require().NotNil(dealer.exitRoom)
+	s.Require().True(isClosed(left.exitRoom))
+	s.Require().False(isClosed(dealer.exitRoom))
+	s.Require().Equal([]protocol.RoomID{room1}, dealer.Rooms())
+	s.Require().Equal(room1, dealer.RoomID())
+	s.Require().Len(dealer.CurrentState().Issues, 1)
+
+	dealer.LeaveRoom()
+	s.Require().Empty(dealer.Rooms())
+	s.Require().True(dealer.RoomID().Empty())
+	s.Require().Nil(dealer.CurrentState())
+}
+
+func (s *Suite) TestArchivedRoom() {
+	ctrl := gomock.NewController(s.T())
+	storageMock := mockstorage.NewMockService(ctrl)
+	storageMock.EXPECT().Initialize().Return(nil).AnyTimes()
+	storageMock.EXPECT().PlayerID().Return(protocol.PlayerID(gofakeit.UUID())).AnyTimes()
+	storageMock.EXPECT().IdentityKey().Return(nil).AnyTimes()
+	storageMock.EXPECT().SetIdentityKey(gomock.Any()).Return(nil).AnyTimes()
+
+	player := s.newGame([]Option{
+		WithStorage(storageMock),
+		WithEnablePublishOnlineState(false),
+	})
+
+	room, err := protocol.NewRoom()
+	s.Require().NoError(err)
+	roomID := room.ToRoomID()
+
+	dealerKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	result := protocol.VoteValue("3")
+	archivedState := &protocol.State{
+		Players: protocol.PlayersList{{ID: protocol.PlayerID(gofakeit.UUID()), Online: true}},
+		Issues: protocol.IssuesList{{
+			ID:     protocol.IssueID(gofakeit.UUID()),
+			Result: &result,
+		}},
+		Dealer:          protocol.PlayerID(gofakeit.UUID()),
+		DealerPublicKey: crypto.FromECDSAPub(&dealerKey.PublicKey),
+	}
+
+	// Player never loads the room as a dealer
+	storageMock.EXPECT().LoadRoomState(roomID).Return(nil, errors.New("not found")).Times(1)
+	storageMock.EXPECT().LoadArchivedRoomState(roomID).Return(archivedState.Clone(), nil, nil).Times(1)
+	storageMock.EXPECT().SaveRoomState(gomock.Any(), gomock.Any()).Times(0)
+
+	s.expectSubscribeToMessages(room)
+	s.transport.EXPECT().PublishPublicMessage(gomock.Any(), gomock.Any()).AnyTimes()
+
+	err = player.JoinRoom(roomID, nil)
+	s.Require().NoError(err)
+	s.Require().False(player.IsDealer())
+	s.Require().True(player.StateArchived())
+	s.Require().Equal(archivedState.Issues, player.CurrentState().Issues)
+	s.Require().False(player.CurrentState().Players[0].Online)
+
+	// Archived state is read-only
+	err = player.PublishVote("1")
+	s.Require().Error(err)
+
+	// Player doesn't take over the room, even though the dealer is not online
+	s.clock.BlockUntil(2) // Dealer watch loop and outbox loop
+	s.clock.Advance(2 * playerOnlineTimeout)
+	s.Require().Never(player.IsDealer, 100*time.Millisecond, 10*time.Millisecond)
+
+	// Dealer state replaces the archived one and is archived
+	dealerState := archivedState.Clone()
+	dealerState.Issues = append(dealerState.Issues, &protocol.Issue{ID: protocol.IssueID(gofakeit.UUID())})
+
+	storageMock.EXPECT().ArchiveRoomState(roomID, gomock.Any(), gomock.Len(0)).Return(nil).Times(1)
+
+	payload, err := json.Marshal(protocol.GameStateMessage{
+		Message: protocol.Message{
+			Type:      protocol.MessageTypeState,
+			Timestamp: s.clock.Now().UnixMilli(),
+		},
+		State:    *dealerState,
+		Sequence: s.clock.Now().UnixMilli(),
+	})
+	s.Require().NoError(err)
+	payload, err = protocol.SignMessage(payload, dealerKey)
+	s.Require().NoError(err)
+
+	player.handleMessage(payload)
+	s.Require().False(player.StateArchived())
+	s.Require().Len(player.CurrentState().Issues, 2)
+}
+
+func (s *Suite) TestArchivedOutbox() {
+	ctrl := gomock.NewController(s.T())
+	storageMock := mockstorage.NewMockService(ctrl)
+	storageMock.EXPECT().Initialize().Return(nil).AnyTimes()
+	storageMock.EXPECT().PlayerID().Return(protocol.PlayerID(gofakeit.UUID())).AnyTimes()
+	storageMock.EXPECT().IdentityKey().Return(nil).AnyTimes()
+	storageMock.EXPECT().SetIdentityKey(gomock.Any()).Return(nil).AnyTimes()
+
+	player := s.newGame([]Option{
+		WithStorage(storageMock),
+		WithEnablePublishOnlineState(false),
+	})
+
+	room, err := protocol.NewRoom()
+	s.Require().NoError(err)
+	roomID := room.ToRoomID()
+
+	dealerKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+
+	issueID := protocol.IssueID(gofakeit.UUID())
+	archivedState := &protocol.State{
+		Players:         protocol.PlayersList{{ID: player.Player().ID}},
+		Issues:          protocol.IssuesList{{ID: issueID, Votes: protocol.IssueVotes{}}},
+		ActiveIssue:     issueID,
+		Deck:            protocol.DeckFromValues("1", "2", "3"),
+		Dealer:          protocol.PlayerID(gofakeit.UUID()),
+		DealerPublicKey: crypto.FromECDSAPub(&dealerKey.PublicKey),
+	}
+
+	// Vote was not confirmed by the dealer before the restart
+	pendingVote := storage.PendingVote{
+		Issue:   issueID,
+		Vote:    protocol.VoteResult{Value: "2", Timestamp: s.clock.Now().UnixMilli()},
+		Payload: []byte(gofakeit.Sentence(3)),
+	}
+
+	storageMock.EXPECT().LoadRoomState(roomID).Return(nil, errors.New("not found")).Times(1)
+	storageMock.EXPECT().LoadArchivedRoomState(roomID).
+		Return(archivedState.Clone(), []storage.PendingVote{pendingVote}, nil).
+		Times(1)
+
+	s.expectSubscribeToMessages(room)
+	s.transport.EXPECT().PublishPublicMessage(gomock.Any(), gomock.Any()).AnyTimes()
+
+	err = player.JoinRoom(roomID, nil)
+	s.Require().NoError(err)
+	s.Require().True(player.VotePending())
+	s.Require().Equal(pendingVote.Vote, player.MyVote())
+
+	// Restored vote is published again
+	published := make(chan []byte, 1)
+	s.transport.EXPECT().PublishPrivateMessage(gomock.Any(), pendingVote.Payload, gomock.Any()).
+		DoAndReturn(func(room *protocol.Room, payload []byte, key *ecdsa.PublicKey) error {
+			published <- payload
+			return nil
+		}).
+		Times(1)
+
+	s.clock.BlockUntil(2) // Dealer watch loop and outbox loop
+	s.clock.Advance(outboxRetryPeriod)
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		s.Require().Fail("timeout waiting for the vote to be published")
+	}
+
+	// Dealer state confirms the vote, it's removed from the archive
+	dealerState := archivedState.Clone()
+	dealerState.Issues[0].Votes[player.Player().ID] = pendingVote.Vote
+
+	storageMock.EXPECT().ArchiveRoomState(roomID, gomock.Any(), gomock.Len(0)).Return(nil).Times(1)
+
+	payload, err := json.Marshal(protocol.GameStateMessage{
+		Message: protocol.Message{
+			Type:      protocol.MessageTypeState,
+			Timestamp: s.clock.Now().UnixMilli(),
+		},
+		State:    *dealerState,
+		Sequence: s.clock.Now().UnixMilli(),
+	})
+	s.Require().NoError(err)
+	payload, err = protocol.SignMessage(payload, dealerKey)
+	s.Require().NoError(err)
+
+	player.handleMessage(payload)
+	s.Require().False(player.VotePending())
+}
+
+func (s *Suite) TestOpenArchivedRoom() {
+	ctrl := gomock.NewController(s.T())
+	storageMock := mockstorage.NewMockService(ctrl)
+	storageMock.EXPECT().Initialize().Return(nil).AnyTimes()
+	storageMock.EXPECT().PlayerID().Return(protocol.PlayerID(gofakeit.UUID())).AnyTimes()
+	storageMock.EXPECT().IdentityKey().Return(nil).AnyTimes()
+	storageMock.EXPECT().SetIdentityKey(gomock.Any()).Return(nil).AnyTimes()
+
+	player := s.newGame([]Option{
+		WithStorage(storageMock),
+	})
+
+	room, err := protocol.NewRoom()
+	s.Require().NoError(err)
+	roomID := room.ToRoomID()
+
+	issueID := protocol.IssueID(gofakeit.UUID())
+	archivedState := &protocol.State{
+		Players:     protocol.PlayersList{{ID: player.Player().ID, Online: true}},
+		Issues:      protocol.IssuesList{{ID: issueID, Votes: protocol.IssueVotes{}}},
+		ActiveIssue: issueID,
+		Deck:        protocol.DeckFromValues("1", "2", "3"),
+		Dealer:      protocol.PlayerID(gofakeit.UUID()),
+	}
+
+	storageMock.EXPECT().LoadArchivedRoomState(roomID).
+		Return(archivedState.Clone(), nil, nil).
+		Times(1)
+
+	// Nothing is subscribed to or published when the room is opened
+	err = player.OpenArchivedRoom(roomID)
+	s.Require().NoError(err)
+	s.Require().Equal(roomID, player.RoomID())
+	s.Require().True(player.ReadOnly())
+	s.Require().True(player.StateArchived())
+	s.Require().NotNil(player.CurrentState())
+	s.Require().Equal(archivedState.Issues, player.CurrentState().Issues)
+	s.Require().False(player.CurrentState().Players[0].Online)
+
+	err = player.PublishVote("1")
+	s.Require().Error(err)
+	storageMock.EXPECT().SetPlayerName(gomock.Any()).Return(nil).Times(1)
+	err = player.RenamePlayer(gofakeit.Username())
+	s.Require().NoError(err)
+	err = player.SetPlayerRole(protocol.ObserverRole)
+	s.Require().NoError(err)
+
+	// Joining the room replaces the read-only one
+	storageMock.EXPECT().LoadRoomState(roomID).Return(nil, errors.New("not found")).Times(1)
+	storageMock.EXPECT().LoadArchivedRoomState(roomID).
+		Return(archivedState.Clone(), nil, nil).
+		Times(1)
+	s.expectSubscribeToMessages(room)
+	s.transport.EXPECT().PublishPublicMessage(gomock.Any(), gomock.Any()).AnyTimes()
+
+	err = player.JoinRoom(roomID, nil)
+	s.Require().NoError(err)
+	s.Require().Equal(roomID, player.RoomID())
+	s.Require().False(player.ReadOnly())
+	s.Require().Equal([]protocol.RoomID{roomID}, player.Rooms())
+}
+
 func (s *Suite) TestGameNotInitialized() {
 	options := []Option{
 		WithContext(s.ctx),
@@ -544,6 +1933,9 @@ func (s *Suite) TestGameNotInitialized() {
 	err = g.JoinRoom(protocol.NewRoomID(gofakeit.LetterN(5)), nil)
 	s.Require().ErrorIs(err, ErrGameNotInitialized)
 
+	err = g.SetPlayerRole(protocol.ObserverRole)
+	s.Require().ErrorIs(err, ErrGameNotInitialized)
+
 	err = g.Initialize()
 	s.Require().NoError(err)
 	s.Require().True(g.Initialized())
@@ -588,3 +1980,12 @@ func (s *Suite) TestNotifyChangedState() {
 
 	g.notifyChangedState(false) // WARNING: true
 }
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -5,19 +5,20 @@ import (
 	"sort"
 
 	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
 
 	"github.com/six78/2-story-points-cli/pkg/protocol"
 )
 
 type hintMeasurements struct {
-	// median is the index median value of given votes
-	median int
+	// value is the deck index of the hint value, e.g. the median index of given votes
+	value int
 
-	// meanDeviation is the mean absolute deviation around the median
+	// meanDeviation is the mean absolute deviation around the value
 	// Measured in cards count.
 	meanDeviation float64
 
-	// maxDeviation is the maximum absolute deviation from the median
+	// maxDeviation is the maximum absolute deviation from the value
 	// Measured in cards count.
 	maxDeviation float64
 }
@@ -40,10 +41,91 @@ const (
 )
 
 var (
-	ErrVoteNotFoundInDeck = errors.New("vote not found in deck")
+	ErrVoteNotFoundInDeck    = errors.New("vote not found in deck")
+	ErrUnknownHintStrategy   = errors.New("unknown hint strategy")
+	ErrInvalidHintThresholds = errors.New("hint thresholds can't be negative")
 )
 
+var (
+	DefaultHintThresholds = HintThresholds{
+		MaxMeanDeviation: maxAcceptableMeanDeviation,
+		MaxDeviation:     maxAcceptableMaximumDeviation,
+	}
+	AvailableHintStrategies = []protocol.HintStrategy{
+		protocol.MedianHintStrategy,
+		protocol.MeanHintStrategy,
+		protocol.ModeHintStrategy,
+		protocol.HighestHintStrategy,
+	}
+	defaultHintStrategy = protocol.MedianHintStrategy
+	// hintValueSelectors return the deck index of the hint value for each strategy
+	hintValueSelectors = map[protocol.HintStrategy]func(indexes []int, deck protocol.Deck) int{
+		protocol.MedianHintStrategy:  ignoreDeck(median),
+		protocol.MeanHintStrategy:    meanRoundedUp,
+		protocol.ModeHintStrategy:    ignoreDeck(mode),
+		protocol.HighestHintStrategy: ignoreDeck(highest),
+	}
+)
+
+// HintStrategy calculates the hint for the revealed votes of an issue.
+type HintStrategy interface {
+	Hint(deck protocol.Deck, issueVotes protocol.IssueVotes) (*protocol.Hint, error)
+}
+
+// HintThresholds define when the votes are considered acceptable, measured in cards count.
+type HintThresholds struct {
+	MaxMeanDeviation float64
+	MaxDeviation     float64
+}
+
+// hintStrategy selects the hint value from the votes and rates the votes by their deviation from the value.
+// Votes are given as deck indexes, the actual deck values are ignored.
+type hintStrategy struct {
+	thresholds  HintThresholds
+	selectValue func(indexes []int, deck protocol.Deck) int
+}
+
+// NewHintStrategy returns the strategy for given room settings. Nil settings mean defaults.
+func NewHintStrategy(settings *protocol.HintSettings) (HintStrategy, error) {
+	name := defaultHintStrategy
+	thresholds := DefaultHintThresholds
+
+	if settings != nil {
+		if settings.Strategy != "" {
+			name = settings.Strategy
+		}
+		if settings.MaxMeanDeviation < 0 || settings.MaxDeviation < 0 {
+			return nil, ErrInvalidHintThresholds
+		}
+		if settings.MaxMeanDeviation != 0 {
+			thresholds.MaxMeanDeviation = settings.MaxMeanDeviation
+		}
+		if settings.MaxDeviation != 0 {
+			thresholds.MaxDeviation = settings.MaxDeviation
+		}
+	}
+
+	selectValue, ok := hintValueSelectors[name]
+	if !ok {
+		return nil, errors.Wrap(ErrUnknownHintStrategy, string(name))
+	}
+
+	return &hintStrategy{
+		thresholds:  thresholds,
+		selectValue: selectValue,
+	}, nil
+}
+
+// GetResultHint calculates the hint with the default median strategy.
 func GetResultHint(deck protocol.Deck, issueVotes protocol.IssueVotes) (*protocol.Hint, error) {
+	strategy, err := NewHintStrategy(nil)
+	if err != nil {
+		return nil, err
+	}
+	return strategy.Hint(deck, issueVotes)
+}
+
+func (s *hintStrategy) Hint(deck protocol.Deck, issueVotes protocol.IssueVotes) (*protocol.Hint, error) {
 	// Get votes as deck indexes.
 	// We ignore the actual deck values when calculating the hint.
 	indexes, err := getVotesAsDeckIndexes(issueVotes, deck)
@@ -60,35 +142,43 @@ func GetResultHint(deck protocol.Deck, issueVotes protocol.IssueVotes) (*protoco
 	}
 
 	// Calculate measures for the votes
-	resultMeasures := getMeasures(indexes)
-	medianValueIndex := resultMeasures.median
-	medianValue := deck[medianValueIndex]
+	resultMeasures := getMeasuresAround(indexes, s.selectValue(indexes, deck))
 
 	// Build the hint based on the measures
 	hint := &protocol.Hint{
-		Value:      medianValue,
+		Value:      deck[resultMeasures.value].Value,
 		Acceptable: true,
 	}
 
-	if resultMeasures.maxDeviation > maxAcceptableMaximumDeviation {
+	if average, ok := getVotesAverage(indexes, deck); ok {
+		hint.Average = &average
+	}
+
+	maxMeanDeviation := s.thresholds.MaxMeanDeviation
+
+	if resultMeasures.maxDeviation > s.thresholds.MaxDeviation {
 		hint.Acceptable = false
 		hint.Description = maximumDeviationIsTooHigh
 	}
 
-	if resultMeasures.meanDeviation > maxAcceptableMeanDeviation {
+	if resultMeasures.meanDeviation > maxMeanDeviation {
 		hint.Acceptable = false
 		hint.Description = varietyOfVotesIsTooHigh
 	}
 
+	if !hint.Acceptable {
+		hint.LowestVoters, hint.HighestVoters = getOutliers(issueVotes, deck, resultMeasures.value)
+	}
+
 	if hint.Acceptable {
 		switch {
 		case resultMeasures.meanDeviation == 0:
 			hint.Description = descriptionBingo
-		case resultMeasures.meanDeviation < maxAcceptableMeanDeviation/2:
+		case resultMeasures.meanDeviation < maxMeanDeviation/2:
 			hint.Description = descriptionGoodJob
-		case resultMeasures.meanDeviation < maxAcceptableMeanDeviation:
+		case resultMeasures.meanDeviation < maxMeanDeviation:
 			hint.Description = descriptionNotBad
-		case compareFloats(resultMeasures.meanDeviation, maxAcceptableMeanDeviation):
+		case compareFloats(resultMeasures.meanDeviation, maxMeanDeviation):
 			hint.Description = descriptionYouCanDoBetter
 		}
 	}
@@ -99,30 +189,96 @@ func GetResultHint(deck protocol.Deck, issueVotes protocol.IssueVotes) (*protoco
 func getVotesAsDeckIndexes(issueVotes protocol.IssueVotes, deck protocol.Deck) ([]int, error) {
 	indexes := make([]int, 0, len(issueVotes))
 	for _, vote := range issueVotes {
-		if vote.Value == protocol.UncertaintyCard {
-			continue
+		index, ok, err := getVoteDeckIndex(vote, deck)
+		if err != nil {
+			return nil, err
 		}
-		index := deck.Index(vote.Value)
-		if index < 0 {
-			return nil, ErrVoteNotFoundInDeck
+		if ok {
+			indexes = append(indexes, index)
 		}
-		indexes = append(indexes, index)
 	}
 	return indexes, nil
 }
 
+// getVoteDeckIndex returns the deck index of the vote.
+// Returns false when the vote is not counted in the hint.
+func getVoteDeckIndex(vote protocol.VoteResult, deck protocol.Deck) (int, bool, error) {
+	if vote.Value == protocol.UncertaintyCard {
+		return -1, false, nil
+	}
+	index := deck.Index(vote.Value)
+	if index < 0 {
+		return -1, false, ErrVoteNotFoundInDeck
+	}
+	if kind := deck[index].CardKind(); kind == protocol.UncertaintyCardKind || kind == protocol.BreakCardKind {
+		// Player can't or doesn't want to estimate
+		return -1, false, nil
+	}
+	return index, true, nil
+}
+
+// getOutliers returns the players with the lowest vote below the value and the players with the highest
+// vote above the value. Players are sorted by ID, so that the result doesn't depend on the map order.
+func getOutliers(issueVotes protocol.IssueVotes, deck protocol.Deck, value int) ([]protocol.PlayerID, []protocol.PlayerID) {
+	lowest, highest := value, value
+	playerIndexes := make(map[protocol.PlayerID]int, len(issueVotes))
+	for playerID, vote := range issueVotes {
+		index, ok, _ := getVoteDeckIndex(vote, deck)
+		if !ok {
+			continue
+		}
+		playerIndexes[playerID] = index
+		lowest = min(lowest, index)
+		highest = max(highest, index)
+	}
+
+	var lowestVoters, highestVoters []protocol.PlayerID
+	for playerID, index := range playerIndexes {
+		switch {
+		case index < value && index == lowest:
+			lowestVoters = append(lowestVoters, playerID)
+		case index > value && index == highest:
+			highestVoters = append(highestVoters, playerID)
+		}
+	}
+
+	slices.Sort(lowestVoters)
+	slices.Sort(highestVoters)
+	return lowestVoters, highestVoters
+}
+
+// getVotesAverage returns the mean numeric value of given votes.
+// Returns false if any of the cards has no numeric value.
+func getVotesAverage(indexes []int, deck protocol.Deck) (float64, bool) {
+	if len(indexes) == 0 {
+		return 0, false
+	}
+	sum := 0.0
+	for _, index := range indexes {
+		number, ok := deck[index].Numeric()
+		if !ok {
+			return 0, false
+		}
+		sum += number
+	}
+	return sum / float64(len(indexes)), true
+}
+
 // getMeasures returns:
 // - median value
 // - median absolute deviation
 // - maximum absolute deviation
-// - error if any occurred
 func getMeasures(values []int) hintMeasurements {
+	return getMeasuresAround(values, median(values))
+}
+
+// getMeasuresAround returns the absolute deviations of the values around given value.
+func getMeasuresAround(values []int, value int) hintMeasurements {
 	r := hintMeasurements{}
 
-	// median value
-	r.median = median(values)
+	r.value = value
 
-	if r.median < 0 {
+	if r.value < 0 {
 		r.maxDeviation = 0
 		r.meanDeviation = 0
 		return r
@@ -131,19 +287,25 @@ func getMeasures(values []int) hintMeasurements {
 	// Maximum deviation
 	r.maxDeviation = 0
 	for _, v := range values {
-		r.maxDeviation = math.Max(r.maxDeviation, deviation(v, r.median))
+		r.maxDeviation = math.Max(r.maxDeviation, deviation(v, r.value))
 	}
 
 	// Average deviation
 	sum := 0
 	for _, v := range values {
-		sum += int(deviation(v, r.median))
+		sum += int(deviation(v, r.value))
 	}
 	r.meanDeviation = float64(sum) / float64(len(values))
 
 	return r
 }
 
+func ignoreDeck(selectValue func(values []int) int) func([]int, protocol.Deck) int {
+	return func(values []int, _ protocol.Deck) int {
+		return selectValue(values)
+	}
+}
+
 func median(values []int) int {
 	if len(values) == 0 {
 		return -1
@@ -156,6 +318,63 @@ func median(values []int) int {
 	return values[center]
 }
 
+// meanRoundedUp returns the nearest card not lower than the mean vote.
+// Numeric values of the cards are used when all votes have them, otherwise the mean deck index is used.
+func meanRoundedUp(values []int, deck protocol.Deck) int {
+	if len(values) == 0 {
+		return -1
+	}
+
+	if average, ok := getVotesAverage(values, deck); ok {
+		result := -1
+		resultNumber := math.Inf(1)
+		for index, card := range deck {
+			number, ok := card.Numeric()
+			if ok && number >= average-float64Epsilon && number < resultNumber {
+				result, resultNumber = index, number
+			}
+		}
+		if result >= 0 {
+			return result
+		}
+	}
+
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+	mean := int(math.Ceil(float64(sum)/float64(len(values)) - float64Epsilon))
+
+	// Special cards in the middle of the deck are skipped
+	for index := mean; index < len(deck); index++ {
+		if deck[index].CardKind() == protocol.RegularCardKind {
+			return index
+		}
+	}
+	return highest(values)
+}
+
+// mode returns the most common vote. Higher vote wins a tie.
+func mode(values []int) int {
+	result := -1
+	counts := make(map[int]int, len(values))
+	for _, v := range values {
+		counts[v]++
+		if result < 0 || counts[v] > counts[result] || counts[v] == counts[result] && v > result {
+			result = v
+		}
+	}
+	return result
+}
+
+func highest(values []int) int {
+	result := -1
+	for _, v := range values {
+		result = max(result, v)
+	}
+	return result
+}
+
 func deviation(value int, median int) float64 {
 	return math.Abs(float64(median) - float64(value))
 }
@@ -33,7 +33,7 @@ func TestMedian(t *testing.T) {
 }
 
 func TestHint(t *testing.T) {
-	deck := protocol.Deck{"1", "2", "3", "5", "8", "13", "21", "?"}
+	deck := protocol.DeckFromValues("1", "2", "3", "5", "8", "13", "21", "?")
 
 	type Case struct {
 		values       []protocol.VoteValue
@@ -47,93 +47,93 @@ func TestHint(t *testing.T) {
 	testCases := []Case{
 		{
 			values:       []protocol.VoteValue{"3", "3", "3", "3", "3"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 0, maxDeviation: 0},
+			measurements: hintMeasurements{value: 2, meanDeviation: 0, maxDeviation: 0},
 			expectedHint: protocol.Hint{Acceptable: true, Value: "3", Description: descriptionBingo},
 		},
 		{
 			values:       []protocol.VoteValue{"3", "3", "3", "3", "5"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 0.2, maxDeviation: 1},
+			measurements: hintMeasurements{value: 2, meanDeviation: 0.2, maxDeviation: 1},
 			expectedHint: protocol.Hint{Acceptable: true, Value: "3", Description: descriptionGoodJob},
 		},
 		{
 			values:       []protocol.VoteValue{"3", "3", "3", "3", "8"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 0.4, maxDeviation: 2},
+			measurements: hintMeasurements{value: 2, meanDeviation: 0.4, maxDeviation: 2},
 			expectedHint: protocol.Hint{Acceptable: false, Value: "3", Description: maximumDeviationIsTooHigh},
 		},
 		{
 			values:       []protocol.VoteValue{"3", "3", "3", "3", "13"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 0.6, maxDeviation: 3},
+			measurements: hintMeasurements{value: 2, meanDeviation: 0.6, maxDeviation: 3},
 			// Test: varietyOfVotesIsTooHigh takes precedence over maximumDeviationIsTooHigh
 			expectedHint: protocol.Hint{Acceptable: false, Value: "3", Description: varietyOfVotesIsTooHigh},
 		},
 		{
 			values:       []protocol.VoteValue{"3", "3", "3", "3", "21"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 0.8, maxDeviation: 4},
+			measurements: hintMeasurements{value: 2, meanDeviation: 0.8, maxDeviation: 4},
 			expectedHint: protocol.Hint{Acceptable: false, Value: "3", Description: varietyOfVotesIsTooHigh},
 		},
 		{
 			values:       []protocol.VoteValue{"3", "3", "3", "5", "5"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 0.4, maxDeviation: 1},
+			measurements: hintMeasurements{value: 2, meanDeviation: 0.4, maxDeviation: 1},
 			expectedHint: protocol.Hint{Acceptable: true, Value: "3", Description: descriptionNotBad},
 		},
 		{
 			values:       []protocol.VoteValue{"3", "3", "3", "5", "8"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 0.6, maxDeviation: 2},
+			measurements: hintMeasurements{value: 2, meanDeviation: 0.6, maxDeviation: 2},
 			expectedHint: protocol.Hint{Acceptable: false, Value: "3", Description: varietyOfVotesIsTooHigh},
 		},
 		{
 			values:       []protocol.VoteValue{"2", "3", "3", "3", "3", "3", "5"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 2 / 7.0, maxDeviation: 1},
+			measurements: hintMeasurements{value: 2, meanDeviation: 2 / 7.0, maxDeviation: 1},
 			expectedHint: protocol.Hint{Acceptable: true, Value: "3", Description: descriptionNotBad},
 		},
 		{
 			values:       []protocol.VoteValue{"2", "3", "3", "3", "3", "5"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 2 / 6.0, maxDeviation: 1},
+			measurements: hintMeasurements{value: 2, meanDeviation: 2 / 6.0, maxDeviation: 1},
 			expectedHint: protocol.Hint{Acceptable: true, Value: "3", Description: descriptionNotBad},
 		},
 		{
 			values:       []protocol.VoteValue{"2", "3", "3", "3", "5"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 2 / 5.0, maxDeviation: 1},
+			measurements: hintMeasurements{value: 2, meanDeviation: 2 / 5.0, maxDeviation: 1},
 			expectedHint: protocol.Hint{Acceptable: true, Value: "3", Description: descriptionNotBad},
 		},
 		{
 			values:       []protocol.VoteValue{"2", "3", "3", "5"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 2 / 4.0, maxDeviation: 1},
+			measurements: hintMeasurements{value: 2, meanDeviation: 2 / 4.0, maxDeviation: 1},
 			expectedHint: protocol.Hint{Acceptable: true, Value: "3", Description: descriptionYouCanDoBetter},
 		},
 		{
 			values:       []protocol.VoteValue{"2", "3", "5"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 2 / 3.0, maxDeviation: 1},
+			measurements: hintMeasurements{value: 2, meanDeviation: 2 / 3.0, maxDeviation: 1},
 			expectedHint: protocol.Hint{Acceptable: false, Value: "3", Description: varietyOfVotesIsTooHigh},
 		},
 		{
 			// This also tests round up median when even number of votes
 			values:       []protocol.VoteValue{"2", "3", "5", "8"},
-			measurements: hintMeasurements{median: 3, meanDeviation: 1, maxDeviation: 2},
+			measurements: hintMeasurements{value: 3, meanDeviation: 1, maxDeviation: 2},
 			expectedHint: protocol.Hint{Acceptable: false, Value: "5", Description: varietyOfVotesIsTooHigh},
 		},
 		{
 			// This also tests round up median when even number of votes
 			values:       []protocol.VoteValue{},
-			measurements: hintMeasurements{median: -1, meanDeviation: 0, maxDeviation: 0},
+			measurements: hintMeasurements{value: -1, meanDeviation: 0, maxDeviation: 0},
 			expectedHint: protocol.Hint{Acceptable: false, Value: "", Description: notEnoughVotes},
 		},
 		{
 			// Question mark doesn't affect the hint
 			values:       []protocol.VoteValue{"3", "3", "3", "3", "?"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 0, maxDeviation: 0},
+			measurements: hintMeasurements{value: 2, meanDeviation: 0, maxDeviation: 0},
 			expectedHint: protocol.Hint{Acceptable: true, Value: "3", Description: descriptionBingo},
 		},
 		{
 			// Question mark doesn't affect the hint, can be anywhere in the deck
 			values:       []protocol.VoteValue{"3", "3", "?", "3", "3"},
-			measurements: hintMeasurements{median: 2, meanDeviation: 0, maxDeviation: 0},
+			measurements: hintMeasurements{value: 2, meanDeviation: 0, maxDeviation: 0},
 			expectedHint: protocol.Hint{Acceptable: true, Value: "3", Description: descriptionBingo},
 		},
 		{
 			// Question mark doesn't affect the hint
 			values:       []protocol.VoteValue{"?", "?", "?", "?", "?"},
-			measurements: hintMeasurements{median: -1, meanDeviation: 0, maxDeviation: 0},
+			measurements: hintMeasurements{value: -1, meanDeviation: 0, maxDeviation: 0},
 			expectedHint: protocol.Hint{Acceptable: false, Value: "", Description: notEnoughVotes},
 		},
 	}
@@ -152,13 +152,158 @@ func TestHint(t *testing.T) {
 			// Now check the actual hint (public API)
 			hint, err := GetResultHint(deck, issueVotes)
 			require.NoError(t, err)
+			hint.Average = nil // Checked in TestHintAverage
+			hint.LowestVoters = nil
+			hint.HighestVoters = nil // Checked in TestHintOutliers
 			require.Equal(t, tc.expectedHint, *hint)
 		})
 	}
 }
 
+func TestHintAverage(t *testing.T) {
+	size := func(value protocol.VoteValue, number float64) protocol.Card {
+		return protocol.Card{Value: value, Number: &number}
+	}
+	sizes := protocol.Deck{size("S", 1), size("M", 3), size("L", 5), {Value: "XL"}, {Value: "?"}, {Value: "☕"}}
+
+	testCases := []struct {
+		deck     protocol.Deck
+		values   []protocol.VoteValue
+		expected *float64
+	}{
+		{protocol.DeckFromValues("1", "2", "3", "?"), []protocol.VoteValue{"1", "2", "3", "3"}, ptr(2.25)},
+		{protocol.DeckFromValues("1", "2", "3", "?"), []protocol.VoteValue{"?", "?"}, nil},
+		{sizes, []protocol.VoteValue{"S", "M", "L", "?", "☕"}, ptr(3.0)},
+		{sizes, []protocol.VoteValue{"S", "XL"}, nil}, // XL has no numeric value
+	}
+
+	for _, tc := range testCases {
+		t.Run(voteValuesString(tc.values), func(t *testing.T) {
+			hint, err := GetResultHint(tc.deck, buildIssueVotes(tc.values))
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, hint.Average)
+		})
+	}
+}
+
+func TestHintStrategies(t *testing.T) {
+	deck := protocol.DeckFromValues("1", "2", "3", "5", "8", "13", "21", "?")
+	sizes := protocol.Deck{
+		numericCard("S", 1, ""),
+		numericCard("M", 3, ""),
+		numericCard("L", 5, ""),
+		{Value: "?"},
+	}
+
+	testCases := []struct {
+		strategy   protocol.HintStrategy
+		deck       protocol.Deck
+		values     []protocol.VoteValue
+		value      protocol.VoteValue
+		acceptable bool
+	}{
+		{protocol.MedianHintStrategy, deck, []protocol.VoteValue{"2", "2", "3"}, "2", true},
+		{protocol.MeanHintStrategy, deck, []protocol.VoteValue{"1", "2", "2", "5"}, "3", false},
+		{protocol.MeanHintStrategy, deck, []protocol.VoteValue{"3", "5", "5", "?"}, "5", true},
+		{protocol.MeanHintStrategy, sizes, []protocol.VoteValue{"S", "M", "M"}, "M", true},
+		{protocol.MeanHintStrategy, sizes, []protocol.VoteValue{"M", "M", "L"}, "L", false},
+		{protocol.ModeHintStrategy, deck, []protocol.VoteValue{"5", "8", "8", "13"}, "8", true},
+		{protocol.ModeHintStrategy, deck, []protocol.VoteValue{"1", "1", "8", "8"}, "8", false},
+		{protocol.HighestHintStrategy, deck, []protocol.VoteValue{"2", "3", "3"}, "3", true},
+		{protocol.HighestHintStrategy, deck, []protocol.VoteValue{"1", "2", "13"}, "13", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.strategy)+":"+voteValuesString(tc.values), func(t *testing.T) {
+			strategy, err := NewHintStrategy(&protocol.HintSettings{Strategy: tc.strategy})
+			require.NoError(t, err)
+
+			hint, err := strategy.Hint(tc.deck, buildIssueVotes(tc.values))
+			require.NoError(t, err)
+			require.Equal(t, tc.value, hint.Value)
+			require.Equal(t, tc.acceptable, hint.Acceptable)
+		})
+	}
+}
+
+func TestHintThresholds(t *testing.T) {
+	deck := protocol.DeckFromValues("1", "2", "3", "5", "8", "13", "21", "?")
+	votes := buildIssueVotes([]protocol.VoteValue{"1", "2", "2", "5"})
+
+	hint, err := GetResultHint(deck, votes)
+	require.NoError(t, err)
+	require.False(t, hint.Acceptable)
+	require.Equal(t, varietyOfVotesIsTooHigh, hint.Description)
+
+	strategy, err := NewHintStrategy(&protocol.HintSettings{MaxMeanDeviation: 1})
+	require.NoError(t, err)
+	hint, err = strategy.Hint(deck, votes)
+	require.NoError(t, err)
+	require.False(t, hint.Acceptable)
+	require.Equal(t, maximumDeviationIsTooHigh, hint.Description)
+
+	strategy, err = NewHintStrategy(&protocol.HintSettings{MaxMeanDeviation: 1, MaxDeviation: 2})
+	require.NoError(t, err)
+	hint, err = strategy.Hint(deck, votes)
+	require.NoError(t, err)
+	require.True(t, hint.Acceptable)
+	require.Equal(t, protocol.VoteValue("2"), hint.Value)
+
+	_, err = NewHintStrategy(&protocol.HintSettings{Strategy: "unknown"})
+	require.ErrorIs(t, err, ErrUnknownHintStrategy)
+
+	_, err = NewHintStrategy(&protocol.HintSettings{MaxDeviation: -1})
+	require.ErrorIs(t, err, ErrInvalidHintThresholds)
+}
+
+func TestHintOutliers(t *testing.T) {
+	deck := protocol.DeckFromValues("1", "2", "3", "5", "8", "13", "21", "?")
+
+	testCases := []struct {
+		name    string
+		votes   map[protocol.PlayerID]protocol.VoteValue
+		lowest  []protocol.PlayerID
+		highest []protocol.PlayerID
+	}{
+		{
+			name:  "acceptable",
+			votes: map[protocol.PlayerID]protocol.VoteValue{"a": "3", "b": "3", "c": "5"},
+		},
+		{
+			name:    "both sides",
+			votes:   map[protocol.PlayerID]protocol.VoteValue{"a": "1", "b": "1", "c": "3", "d": "5", "e": "13", "f": "?"},
+			lowest:  []protocol.PlayerID{"a", "b"},
+			highest: []protocol.PlayerID{"e"},
+		},
+		{
+			name:    "only higher",
+			votes:   map[protocol.PlayerID]protocol.VoteValue{"a": "2", "b": "2", "c": "2", "d": "8", "e": "13"},
+			highest: []protocol.PlayerID{"e"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issueVotes := make(protocol.IssueVotes, len(tc.votes))
+			for playerID, value := range tc.votes {
+				issueVotes[playerID] = protocol.VoteResult{Value: value}
+			}
+
+			hint, err := GetResultHint(deck, issueVotes)
+			require.NoError(t, err)
+			require.Equal(t, tc.lowest == nil && tc.highest == nil, hint.Acceptable)
+			require.Equal(t, tc.lowest, hint.LowestVoters)
+			require.Equal(t, tc.highest, hint.HighestVoters)
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
 func TestInvalidVote(t *testing.T) {
-	deck := protocol.Deck{"1", "2"}
+	deck := protocol.DeckFromValues("1", "2")
 	issueVotes := buildIssueVotes([]protocol.VoteValue{"1", "X"})
 	_, err := GetResultHint(deck, issueVotes)
 	require.Error(t, err)
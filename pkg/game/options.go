@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/six78/2-story-points-cli/internal/transport"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
 	"github.com/six78/2-story-points-cli/pkg/storage"
 )
 
@@ -55,6 +56,12 @@ func WithPlayerName(name string) Option {
 	}
 }
 
+func WithPlayerRole(role protocol.PlayerRole) Option {
+	return func(g *Game) {
+		g.config.PlayerRole = role
+	}
+}
+
 func WithOnlineMessagePeriod(d time.Duration) Option {
 	return func(g *Game) {
 		g.config.OnlineMessagePeriod = d
@@ -79,3 +86,9 @@ func WithAutoReveal(enabled bool, delay time.Duration) Option {
 		g.config.AutoRevealDelay = delay
 	}
 }
+
+func WithEncoding(encoding protocol.Encoding) Option {
+	return func(g *Game) {
+		g.config.Encoding = encoding
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	mocktransport "github.com/six78/2-story-points-cli/internal/transport/mock"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
 	mockstorage "github.com/six78/2-story-points-cli/pkg/storage/mock"
 )
 
@@ -24,6 +25,7 @@ func TestOptions(t *testing.T) {
 	clock := clockwork.NewFakeClock()
 	enableSymmetricEncryption := gofakeit.Bool()
 	playerName := gofakeit.Username()
+	playerRole := protocol.ObserverRole
 	onlineMessagePeriod := time.Duration(gofakeit.Int64())
 	stateMessagePeriod := time.Duration(gofakeit.Int64())
 	publishStateLoop := gofakeit.Bool()
@@ -38,6 +40,7 @@ func TestOptions(t *testing.T) {
 		WithClock(clock),
 		WithEnableSymmetricEncryption(enableSymmetricEncryption),
 		WithPlayerName(playerName),
+		WithPlayerRole(playerRole),
 		WithOnlineMessagePeriod(onlineMessagePeriod),
 		WithStateMessagePeriod(stateMessagePeriod),
 		WithPublishStateLoop(publishStateLoop),
@@ -53,6 +56,7 @@ func TestOptions(t *testing.T) {
 	require.Equal(t, clock, game.clock)
 	require.Equal(t, enableSymmetricEncryption, game.config.EnableSymmetricEncryption)
 	require.Equal(t, playerName, game.config.PlayerName)
+	require.Equal(t, playerRole, game.config.PlayerRole)
 	require.Equal(t, onlineMessagePeriod, game.config.OnlineMessagePeriod)
 	require.Equal(t, stateMessagePeriod, game.config.StateMessagePeriod)
 	require.Equal(t, publishStateLoop, game.config.PublishStateLoopEnabled)
@@ -0,0 +1,190 @@
+package game
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"github.com/six78/2-story-points-cli/internal/transport"
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+	"github.com/six78/2-story-points-cli/pkg/storage"
+)
+
+// outboxRetryPeriod is how often unconfirmed votes are published again, in case they were lost while online.
+var outboxRetryPeriod = 10 * time.Second
+
+// outboxMessage is a signed vote message, which is published again until the dealer confirms it.
+type outboxMessage struct {
+	issue   protocol.IssueID
+	vote    protocol.VoteResult
+	payload []byte
+}
+
+// outbox keeps the votes of a session, which are not yet confirmed by the dealer.
+// A vote is confirmed when the dealer's state shows it. There's at most one vote per issue,
+// the latest one replaces the previous.
+type outbox struct {
+	lock     sync.Mutex
+	messages []outboxMessage
+}
+
+func (o *outbox) add(message outboxMessage) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.messages = slices.DeleteFunc(o.messages, func(other outboxMessage) bool {
+		return other.issue == message.issue
+	})
+	o.messages = append(o.messages, message)
+}
+
+// clear removes all votes, e.g. when the player doesn't vote anymore.
+func (o *outbox) clear() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.messages = nil
+}
+
+// stored returns the votes to be saved with the archived room state.
+func (o *outbox) stored() []storage.PendingVote {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	votes := make([]storage.PendingVote, 0, len(o.messages))
+	for _, message := range o.messages {
+		votes = append(votes, storage.PendingVote{
+			Issue:   message.issue,
+			Vote:    message.vote,
+			Payload: message.payload,
+		})
+	}
+	return votes
+}
+
+// restore adds the votes loaded with the archived room state.
+func (o *outbox) restore(votes []storage.PendingVote) {
+	for _, vote := range votes {
+		o.add(outboxMessage{
+			issue:   vote.Issue,
+			vote:    vote.Vote,
+			payload: vote.Payload,
+		})
+	}
+}
+
+func (o *outbox) pending() []outboxMessage {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return slices.Clone(o.messages)
+}
+
+func (o *outbox) has(issue protocol.IssueID) bool {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return slices.ContainsFunc(o.messages, func(message outboxMessage) bool {
+		return message.issue == issue
+	})
+}
+
+// vote returns the unconfirmed vote for the issue, if any.
+func (o *outbox) vote(issue protocol.IssueID) (protocol.VoteResult, bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	index := slices.IndexFunc(o.messages, func(message outboxMessage) bool {
+		return message.issue == issue
+	})
+	if index < 0 {
+		return protocol.VoteResult{}, false
+	}
+	return o.messages[index].vote, true
+}
+
+// confirm removes the votes shown in the state, and the votes which can't be accepted anymore.
+func (o *outbox) confirm(state *protocol.State, playerID protocol.PlayerID) {
+	if state == nil {
+		return
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.messages = slices.DeleteFunc(o.messages, func(message outboxMessage) bool {
+		if state.ActiveIssue != message.issue || state.VotesRevealed {
+			// Voting for this issue is finished
+			return true
+		}
+		issue := state.Issues.Get(message.issue)
+		if issue == nil {
+			return true
+		}
+		vote, voted := issue.Votes[playerID]
+		if message.vote.Value == "" {
+			// Retracted vote is confirmed when the vote is gone
+			return !voted
+		}
+		return voted && vote.Timestamp >= message.vote.Timestamp
+	})
+}
+
+// VotePending returns true if our vote for the active issue is not yet confirmed by the dealer.
+func (g *Game) VotePending() bool {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	if g.state == nil {
+		return false
+	}
+	return g.outbox.has(g.state.ActiveIssue)
+}
+
+// publishOutbox publishes the unconfirmed votes of the session again.
+func (g *Game) publishOutbox() {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	for _, message := range g.outbox.pending() {
+		g.logger.Debug("publishing unconfirmed vote", zap.Any("issue", message.issue))
+		// Dealer might have changed since the vote was sent
+		err := g.publishPayload(message.payload, g.dealerPublicKey())
+		if err != nil {
+			g.logger.Warn("failed to publish unconfirmed vote", zap.Error(err))
+		}
+	}
+}
+
+// watchOutboxLoop publishes unconfirmed votes of all sessions when the transport gets back online,
+// and periodically in case the messages were lost.
+func (g *Game) watchOutboxLoop(statusSub transport.ConnectionStatusSubscription) {
+	ticker := g.clock.NewTicker(outboxRetryPeriod)
+	defer ticker.Stop()
+
+	online := false
+
+	for {
+		select {
+		case <-g.ctx.Done():
+			return
+		case status, more := <-statusSub:
+			if !more {
+				return
+			}
+			wasOnline := online
+			online = status.IsOnline
+			if !online || wasOnline {
+				continue
+			}
+			g.logger.Debug("transport is back online, publishing unconfirmed votes")
+		case <-ticker.Chan():
+		}
+
+		for _, session := range g.sessions.all() {
+			g.forSession(session).publishOutbox()
+		}
+	}
+}
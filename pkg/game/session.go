@@ -0,0 +1,150 @@
+package game
+
+import (
+	"crypto/ecdsa"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"golang.org/x/exp/slices"
+
+	"github.com/six78/2-story-points-cli/pkg/protocol"
+)
+
+// session holds everything related to a single room joined by the player.
+// Each session has its own subscription and routines, so that several rooms can be played at the same time.
+type session struct {
+	exitRoom chan struct{} // Closed when the room is left. Never reassigned, so routines of the room read it without a lock
+	exitOnce sync.Once
+	messages chan []byte
+
+	// Guards the session state. Held by API calls and by routines of the room while they read or change it.
+	// Events are queued instead of being sent under the lock, see eventQueue.
+	stateLock sync.Mutex
+
+	isDealer bool
+	myVote   protocol.VoteResult // We save our vote to show it in UI
+
+	room            *protocol.Room
+	roomID          protocol.RoomID
+	state           *protocol.State
+	archived        bool             // State was loaded from the archive and wasn't received from the dealer yet
+	readOnly        bool             // Room was opened from the archive without joining it, see Game.OpenArchivedRoom
+	dealerKey       *ecdsa.PublicKey // Pinned dealer identity, used to verify state messages
+	stateTimestamp  int64
+	stateSequence   int64 // Sequence of the last published (dealer) or received (player) state
+	publishedState  *protocol.State
+	publishedLock   sync.Mutex
+	heartbeats      int                // Heartbeats published since the last full state, see stateSnapshotHeartbeats
+	statePublished  chan struct{}      // Closed when the last state message is published
+	snapshotTime    time.Time          // When the dealer published the last full state on request
+	stateRequested  time.Time          // When the player requested the full state last time
+	roleExit        chan struct{}      // Closed when the dealer role of this player changes
+	dealerSeen      time.Time          // When the player received the last message from the dealer
+	timeouts        chan revealTimeout // Expired reveal timers, handled by the session loop
+	revealTimer     clockwork.Timer
+	revealTimerID   int // Identifies the current revealTimer, see revealTimeout
+	revealTimerLock sync.Mutex
+	deadlineTimer   clockwork.Timer // Dealer timer that reveals votes when the voting deadline expires
+	deadlineTimerAt int64           // Voting deadline the deadlineTimer was scheduled for
+	deadlineLock    sync.Mutex
+	outbox          outbox       // Votes not yet confirmed by the dealer
+	voteDelivery    VoteDelivery // Last sent delivery status of our vote
+}
+
+func newSession() *session {
+	return &session{
+		exitRoom: make(chan struct{}),
+		messages: make(chan []byte, 42),
+		timeouts: make(chan revealTimeout),
+		isDealer: false,
+		myVote: protocol.VoteResult{
+			Value:     "",
+			Timestamp: 0,
+		},
+		room:           nil,
+		roomID:         protocol.NewRoomID(""),
+		stateTimestamp: 0,
+	}
+}
+
+// exit stops the routines of the session. Sessions are never reused, a new one is created for each joined room.
+func (s *session) exit() {
+	s.exitOnce.Do(func() {
+		close(s.exitRoom)
+	})
+}
+
+// sessions is the list of rooms joined by the player.
+// It's shared between the game and its session-bound views, see Game.forSession.
+type sessions struct {
+	lock    sync.Mutex
+	list    []*session // In the order of joining
+	current *session
+}
+
+func (s *sessions) get(roomID protocol.RoomID) *session {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	index := slices.IndexFunc(s.list, func(other *session) bool {
+		return other.roomID == roomID
+	})
+	if index < 0 {
+		return nil
+	}
+	return s.list[index]
+}
+
+func (s *sessions) add(added *session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.list = append(s.list, added)
+}
+
+// remove removes the session from the list and returns the last joined remaining session, if any.
+func (s *sessions) remove(removed *session) *session {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.list = slices.DeleteFunc(s.list, func(other *session) bool {
+		return other == removed
+	})
+	if len(s.list) == 0 {
+		return nil
+	}
+	return s.list[len(s.list)-1]
+}
+
+func (s *sessions) all() []*session {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return slices.Clone(s.list)
+}
+
+func (s *sessions) roomIDs() []protocol.RoomID {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := make([]protocol.RoomID, 0, len(s.list))
+	for _, other := range s.list {
+		result = append(result, other.roomID)
+	}
+	return result
+}
+
+func (s *sessions) setCurrent(current *session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.current = current
+}
+
+func (s *sessions) isCurrent(other *session) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.current == other
+}
@@ -0,0 +1,79 @@
+package game
+
+import (
+	"go.uber.org/zap"
+)
+
+// VoteDelivery tells whether our vote for the active issue reached the dealer.
+type VoteDelivery int
+
+const (
+	VoteNotSent VoteDelivery = iota
+	VoteSent
+	VoteReceived // Dealer's state shows the vote
+	VoteLost     // Votes were revealed without our vote
+)
+
+func (d VoteDelivery) String() string {
+	switch d {
+	case VoteSent:
+		return "sent"
+	case VoteReceived:
+		return "received"
+	case VoteLost:
+		return "lost"
+	default:
+		return "not sent"
+	}
+}
+
+// VoteDelivery returns the delivery status of our vote for the active issue.
+func (g *Game) VoteDelivery() VoteDelivery {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+
+	return g.voteDelivery
+}
+
+// currentVoteDelivery compares our vote with the vote timestamp in the dealer's state.
+// Votes are hidden in the state, but the timestamps are not.
+func (g *Game) currentVoteDelivery() VoteDelivery {
+	if g.state == nil || g.player == nil || g.myVote.Timestamp == 0 {
+		return VoteNotSent
+	}
+
+	issue := g.state.GetActiveIssue()
+	if issue == nil {
+		return VoteNotSent
+	}
+
+	vote, voted := issue.Votes[g.player.ID]
+	switch {
+	case g.myVote.Value == "" && !voted:
+		return VoteReceived
+	case g.myVote.Value != "" && voted && vote.Timestamp >= g.myVote.Timestamp:
+		return VoteReceived
+	case g.state.VotesRevealed:
+		return VoteLost
+	default:
+		return VoteSent
+	}
+}
+
+// updateVoteDelivery sends EventVoteDelivery when the delivery status of our vote changes.
+func (g *Game) updateVoteDelivery() {
+	delivery := g.currentVoteDelivery()
+	if delivery == g.voteDelivery {
+		return
+	}
+
+	g.logger.Debug("vote delivery changed",
+		zap.Stringer("previous", g.voteDelivery),
+		zap.Stringer("delivery", delivery))
+
+	g.voteDelivery = delivery
+	g.sendEvent(Event{
+		Tag:  EventVoteDelivery,
+		Data: delivery,
+	})
+}
@@ -0,0 +1,50 @@
+package protocol
+
+type AutoRevealPolicy string
+
+const (
+	AutoRevealOff            AutoRevealPolicy = "off"              // Votes are only revealed by the dealer
+	AutoRevealAllVoted       AutoRevealPolicy = "all-voted"        // All players voted, the default
+	AutoRevealQuorum         AutoRevealPolicy = "quorum"           // Given percentage of voters voted
+	AutoRevealAllOnlineVoted AutoRevealPolicy = "all-online-voted" // All online players voted
+)
+
+// AutoRevealSettings define when the dealer reveals votes without an explicit command. Chosen by the dealer.
+// Zero values (nil delay) mean defaults of the dealer client.
+type AutoRevealSettings struct {
+	Policy AutoRevealPolicy `json:"policy,omitempty"`
+
+	// Quorum is the percentage of voters that must vote, only used with AutoRevealQuorum policy.
+	Quorum int `json:"quorum,omitempty"`
+
+	// Delay is the time in milliseconds between the policy is met and the votes are revealed.
+	// Zero delay reveals the votes immediately, nil means the default delay of the dealer.
+	Delay *int64 `json:"delay,omitempty"`
+}
+
+func (s *AutoRevealSettings) Empty() bool {
+	return s == nil || s.Policy == "" && s.Quorum == 0 && s.Delay == nil
+}
+
+func (s AutoRevealSettings) Equal(other AutoRevealSettings) bool {
+	if s.Policy != other.Policy || s.Quorum != other.Quorum {
+		return false
+	}
+	if s.Delay == nil || other.Delay == nil {
+		return s.Delay == nil && other.Delay == nil
+	}
+	return *s.Delay == *other.Delay
+}
+
+// Clone returns a deep copy of the settings.
+func (s *AutoRevealSettings) Clone() *AutoRevealSettings {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	if s.Delay != nil {
+		delay := *s.Delay
+		clone.Delay = &delay
+	}
+	return &clone
+}
@@ -1,6 +1,11 @@
 package protocol
 
-import "golang.org/x/exp/slices"
+import (
+	"encoding/json"
+	"strconv"
+
+	"golang.org/x/exp/slices"
+)
 
 // UncertaintyCard is a special value that means the player wasn't sure about the vote.
 // NOTE: For now I've chosen the simplest way of implementing this.
@@ -12,8 +17,135 @@ import "golang.org/x/exp/slices"
 // To make it more customizable, we can give user an option to specify the special symbol.
 const UncertaintyCard = VoteValue("?")
 
-type Deck []VoteValue
+const (
+	BreakCard    = VoteValue("☕")
+	InfinityCard = VoteValue("∞")
+)
+
+// CardKind tells how a card is treated when votes are summarized.
+// Only regular cards are used for hints and averages.
+type CardKind string
+
+const (
+	RegularCardKind     CardKind = "regular"
+	UncertaintyCardKind CardKind = "uncertainty"
+	BreakCardKind       CardKind = "break"
+	InfinityCardKind    CardKind = "infinity"
+)
+
+var specialCards = map[VoteValue]CardKind{
+	UncertaintyCard: UncertaintyCardKind,
+	BreakCard:       BreakCardKind,
+	InfinityCard:    InfinityCardKind,
+}
+
+// Card is a deck card. Metadata is optional: when not set, the number is parsed from the value
+// and well-known special values (?, ☕, ∞) are recognized.
+// Cards without metadata are encoded as plain strings, so that decks stay compatible with older clients.
+type Card struct {
+	Value  VoteValue `json:"value"`
+	Label  string    `json:"label,omitempty"`  // Human-readable description, e.g. "Medium" for "M"
+	Number *float64  `json:"number,omitempty"` // Numeric value, e.g. points for T-shirt sizes
+	Kind   CardKind  `json:"kind,omitempty"`
+}
+
+func (c Card) HasMetadata() bool {
+	return c.Label != "" || c.Number != nil || c.Kind != ""
+}
+
+// Numeric returns the numeric value of the card, if any.
+// Special cards are never numeric.
+func (c Card) Numeric() (float64, bool) {
+	if c.CardKind() != RegularCardKind {
+		return 0, false
+	}
+	if c.Number != nil {
+		return *c.Number, true
+	}
+	number, err := strconv.ParseFloat(string(c.Value), 64)
+	if err != nil {
+		return 0, false
+	}
+	return number, true
+}
+
+func (c Card) CardKind() CardKind {
+	if c.Kind != "" {
+		return c.Kind
+	}
+	if kind, ok := specialCards[c.Value]; ok {
+		return kind
+	}
+	return RegularCardKind
+}
+
+func (c Card) Equal(other Card) bool {
+	if c.Value != other.Value || c.Label != other.Label || c.Kind != other.Kind {
+		return false
+	}
+	if c.Number == nil || other.Number == nil {
+		return c.Number == other.Number
+	}
+	return *c.Number == *other.Number
+}
+
+func (c Card) MarshalJSON() ([]byte, error) {
+	if !c.HasMetadata() {
+		return json.Marshal(string(c.Value))
+	}
+	type card Card
+	return json.Marshal(card(c))
+}
+
+// UnmarshalJSON accepts both plain string and object cards.
+func (c *Card) UnmarshalJSON(data []byte) error {
+	var value string
+	if json.Unmarshal(data, &value) == nil {
+		*c = Card{Value: VoteValue(value)}
+		return nil
+	}
+	type card Card
+	return json.Unmarshal(data, (*card)(c))
+}
+
+type Deck []Card
+
+// DeckFromValues creates a deck of cards without metadata.
+func DeckFromValues(values ...VoteValue) Deck {
+	deck := make(Deck, 0, len(values))
+	for _, value := range values {
+		deck = append(deck, Card{Value: value})
+	}
+	return deck
+}
 
 func (d Deck) Index(value VoteValue) int {
-	return slices.Index(d, value)
+	return slices.IndexFunc(d, func(card Card) bool {
+		return card.Value == value
+	})
+}
+
+func (d Deck) Contains(value VoteValue) bool {
+	return d.Index(value) >= 0
+}
+
+// Card returns the card with given value.
+func (d Deck) Card(value VoteValue) (Card, bool) {
+	index := d.Index(value)
+	if index < 0 {
+		return Card{}, false
+	}
+	return d[index], true
+}
+
+func (d Deck) Values() []VoteValue {
+	values := make([]VoteValue, 0, len(d))
+	for _, card := range d {
+		values = append(values, card.Value)
+	}
+	return values
+}
+
+func (d Deck) Equal(other Deck) bool {
+	return slices.EqualFunc(d, other, Card.Equal)
 }
@@ -1,17 +1,71 @@
 package protocol
 
+import (
+	"golang.org/x/exp/slices"
+)
+
 type Hint struct {
 	// Acceptable shows if the voting for given issue can be considered as "acceptable".
 	// It will be false if the variety of votes is too high. In this case Advice will contain
 	// a suggestion to discuss and re-vote.
-	Acceptable bool
+	Acceptable bool `json:"acceptable"`
 
 	// Value is the recommended value for the issue.
 	// It's guaranteed to be one of the values from the deck.
-	Value VoteValue
+	Value VoteValue `json:"value"`
 
 	// Description contains text message for the team.
 	// When Acceptable is false, Description explaining the reject reasoning.
 	// When Acceptable is true, Description contains some congratulatory message.
-	Description string
+	Description string `json:"description,omitempty"`
+
+	// Average is the mean numeric value of the votes, see Card.Numeric.
+	// Nil when some of the votes have no numeric value.
+	Average *float64 `json:"average,omitempty"`
+
+	// LowestVoters and HighestVoters are the players who voted furthest below and above the Value.
+	// Only set when Acceptable is false, so that the team can hear them out first.
+	LowestVoters  []PlayerID `json:"lowestVoters,omitempty"`
+	HighestVoters []PlayerID `json:"highestVoters,omitempty"`
+}
+
+func (h *Hint) Clone() *Hint {
+	if h == nil {
+		return nil
+	}
+	clone := *h
+	if h.Average != nil {
+		average := *h.Average
+		clone.Average = &average
+	}
+	clone.LowestVoters = slices.Clone(h.LowestVoters)
+	clone.HighestVoters = slices.Clone(h.HighestVoters)
+	return &clone
+}
+
+type HintStrategy string
+
+const (
+	MedianHintStrategy  HintStrategy = "median"  // Median vote, the default
+	MeanHintStrategy    HintStrategy = "mean"    // Mean vote, rounded up to the nearest card
+	ModeHintStrategy    HintStrategy = "mode"    // The most common vote
+	HighestHintStrategy HintStrategy = "highest" // Highest vote wins
+)
+
+// HintSettings define how the hint is calculated in the room. Chosen by the dealer.
+// Zero values mean defaults, so that states of older dealers use the default median strategy.
+type HintSettings struct {
+	Strategy HintStrategy `json:"strategy,omitempty"`
+
+	// MaxMeanDeviation is the maximum acceptable mean deviation of the votes from the hint value,
+	// measured in cards count.
+	MaxMeanDeviation float64 `json:"maxMeanDeviation,omitempty"`
+
+	// MaxDeviation is the maximum acceptable deviation of a single vote from the hint value,
+	// measured in cards count.
+	MaxDeviation float64 `json:"maxDeviation,omitempty"`
+}
+
+func (s *HintSettings) Empty() bool {
+	return s == nil || *s == HintSettings{}
 }
@@ -31,3 +31,11 @@ func (l IssuesList) GetNextIssueToDeal(finishedIssueID IssueID) IssueID {
 	}
 	return ""
 }
+
+func (l IssuesList) IDs() []IssueID {
+	ids := make([]IssueID, 0, len(l))
+	for _, issue := range l {
+		ids = append(ids, issue.ID)
+	}
+	return ids
+}
@@ -1,21 +1,76 @@
 package protocol
 
 import (
+	"bytes"
 	"encoding/json"
 
 	"github.com/pkg/errors"
 )
 
+// Encoding is the wire format of the messages.
+// It's negotiated through the content topic, see transport.ContentTopicCache.
+type Encoding string
+
+const (
+	EncodingJSON     Encoding = "json"
+	EncodingProtobuf Encoding = "proto"
+)
+
+// Encodings lists all supported encodings.
+// Clients should receive messages in all of them, so that clients using different encodings can play together.
+var Encodings = []Encoding{EncodingJSON, EncodingProtobuf}
+
+func ParseEncoding(value string) (Encoding, error) {
+	for _, encoding := range Encodings {
+		if string(encoding) == value {
+			return encoding, nil
+		}
+	}
+	return "", errors.Errorf("unsupported encoding: %s", value)
+}
+
+// DetectEncoding returns the encoding of given payload.
+// All JSON messages are objects, while protobuf messages always start with the type field tag.
+// Leading whitespace is allowed in JSON. The protobuf type field tag is also a newline, but it's followed by
+// the type length and the type name, which never start with an object.
+func DetectEncoding(payload []byte) Encoding {
+	payload = bytes.TrimLeft(payload, " \t\r\n")
+	if len(payload) > 0 && payload[0] == '{' {
+		return EncodingJSON
+	}
+	return EncodingProtobuf
+}
+
+// Marshal encodes the message with given encoding.
+// Messages that have no protobuf schema are always encoded with JSON.
+func Marshal(message any, encoding Encoding) ([]byte, error) {
+	if encoding == EncodingProtobuf {
+		payload, err := marshalProtobuf(message)
+		if !errors.Is(err, ErrUnsupportedProtobufMessage) {
+			return payload, errors.Wrap(err, "failed to marshal protobuf message")
+		}
+	}
+	return json.Marshal(message)
+}
+
+// Unmarshal decodes the message, encoding is detected automatically.
+func Unmarshal(payload []byte, message any) error {
+	if DetectEncoding(payload) == EncodingJSON {
+		return json.Unmarshal(payload, message)
+	}
+	return unmarshalProtobuf(payload, message)
+}
+
 func UnmarshalMessage(payload []byte) (*Message, error) {
 	message := Message{}
-	err := json.Unmarshal(payload, &message)
+	err := Unmarshal(payload, &message)
 	return &message, errors.Wrap(err, "failed to unmarshal message")
 }
 
 func UnmarshalPlayerVote(payload []byte) (*PlayerVoteMessage, error) {
 	vote := PlayerVoteMessage{}
 
-	err := json.Unmarshal(payload, &vote)
+	err := Unmarshal(payload, &vote)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal message")
 	}
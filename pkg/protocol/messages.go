@@ -11,25 +11,61 @@ type Issue struct {
 	Votes      IssueVotes `json:"votes"`
 	Result     *VoteValue `json:"result"` // NOTE: keep pointer. Because "empty string means vote is not revealed"
 	Hint       *Hint      `json:"-"`
+	// Rounds are the previous voting rounds of the issue, oldest first. Votes of the current round are in Votes.
+	Rounds []IssueRound `json:"rounds,omitempty"`
+}
+
+// IssueRound keeps revealed votes of a finished voting round, when the issue is voted again.
+type IssueRound struct {
+	Votes     IssueVotes `json:"votes"`
+	Result    *VoteValue `json:"result,omitempty"` // Result of the round, nil when the issue was voted again before it was finished
+	Timestamp int64      `json:"timestamp"`        // When the round was closed, in Unix milliseconds
+	Hint      *Hint      `json:"hint,omitempty"`   // Calculated by the dealer when the round is closed, so that it doesn't change with the room settings
 }
 
 type MessageType string
 
 const (
-	MessageTypeState         MessageType = "__state"
-	MessageTypePlayerOnline  MessageType = "__player_online"
-	MessageTypePlayerVote    MessageType = "__player_vote"
-	MessageTypePlayerOffline MessageType = "__player_left"
+	MessageTypeState          MessageType = "__state"
+	MessageTypePlayerOnline   MessageType = "__player_online"
+	MessageTypePlayerVote     MessageType = "__player_vote"
+	MessageTypePlayerOffline  MessageType = "__player_left"
+	MessageTypeStateDelta     MessageType = "__state_delta"
+	MessageTypeStateRequest   MessageType = "__state_request"
+	MessageTypeDealerTransfer MessageType = "__dealer_transfer"
 )
 
 type Message struct {
 	Type      MessageType `json:"type"`
 	Timestamp int64       `json:"updatedAt"` // WARNING: rename to Timestamp
+	// Signature is set by SignMessage, it's not expected to be filled manually.
+	Signature []byte `json:"signature,omitempty"`
 }
 
 type GameStateMessage struct {
 	Message
 	State State `json:"state"`
+	// Sequence of the state, see StateDeltaMessage. Zero for dealers that don't support state deltas.
+	Sequence int64 `json:"sequence,omitempty"`
+}
+
+// StateDeltaMessage is published by the dealer instead of the full state when possible.
+// Each published state change increments the sequence. The delta can only be applied to the state
+// with BaseSequence, otherwise a player must request the full state with StateRequestMessage.
+// A delta with BaseSequence equal to Sequence carries no changes and is used by the dealer
+// to periodically announce the current sequence.
+type StateDeltaMessage struct {
+	Message
+	Sequence     int64      `json:"sequence"`
+	BaseSequence int64      `json:"baseSequence"`
+	Delta        StateDelta `json:"delta"`
+}
+
+// StateRequestMessage is sent by a player to request the full state from the dealer.
+type StateRequestMessage struct {
+	Message
+	PlayerID PlayerID `json:"playerId"`
+	Sequence int64    `json:"sequence"` // Last known state sequence
 }
 
 type PlayerOnlineMessage struct {
@@ -49,4 +85,13 @@ type PlayerVoteMessage struct {
 	VoteResult VoteResult `json:"vote"`
 }
 
+// DealerTransferMessage is sent by the dealer to hand the dealer role to another player.
+// It's encrypted with the new dealer public key, as the state contains votes that are not revealed yet.
+type DealerTransferMessage struct {
+	Message
+	Dealer   PlayerID `json:"dealer"`
+	State    State    `json:"state"`
+	Sequence int64    `json:"sequence"`
+}
+
 type IssueVotes map[PlayerID]VoteResult
@@ -4,10 +4,23 @@ import (
 	"time"
 )
 
+// PlayerRole tells if the player takes part in voting.
+type PlayerRole string
+
+const (
+	VoterRole    PlayerRole = "voter"
+	ObserverRole PlayerRole = "observer" // Watches the game without voting, e.g. a product owner
+)
+
 type Player struct {
 	ID     PlayerID `json:"id"`
 	Name   string   `json:"name"`
 	Online bool     `json:"online"`
+	// PublicKey is the player identity key. It's only set by the dealer after verifying
+	// the player messages signature. Players without a key are not verified.
+	PublicKey []byte `json:"publicKey,omitempty"`
+	// Role is empty for players of older clients, they are voters.
+	Role PlayerRole `json:"role,omitempty"`
 
 	// Deprecated: use OnlineTimestamp instead
 	// TODO: Those fields should be removed from json. They shouldn't be part of the protocol.
@@ -32,3 +45,12 @@ func (p *Player) ApplyDeprecatedPatchOnSend() {
 func (p *Player) OnlineTime() time.Time {
 	return time.UnixMilli(p.OnlineTimestampMilliseconds)
 }
+
+func (p *Player) Verified() bool {
+	return len(p.PublicKey) > 0
+}
+
+// Observer tells if the player doesn't vote, so that the player is not waited for and not counted in hints.
+func (p *Player) Observer() bool {
+	return p.Role == ObserverRole
+}
@@ -10,3 +10,14 @@ func (l PlayersList) Get(id PlayerID) (Player, bool) {
 	}
 	return Player{}, false
 }
+
+// Voters returns the players that take part in voting, i.e. all players except observers.
+func (l PlayersList) Voters() PlayersList {
+	voters := make(PlayersList, 0, len(l))
+	for _, player := range l {
+		if !player.Observer() {
+			voters = append(voters, player)
+		}
+	}
+	return voters
+}
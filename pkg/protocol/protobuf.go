@@ -0,0 +1,837 @@
+package protocol
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Protobuf encoding of the messages, see messages.proto for the schema.
+// Messages are encoded manually with protowire to avoid generated code and keep
+// the protocol types shared between both encodings.
+
+const (
+	headerTypeField      protowire.Number = 1
+	headerTimestampField protowire.Number = 2
+	headerSignatureField protowire.Number = 3
+)
+
+var ErrUnsupportedProtobufMessage = errors.New("message is not supported in protobuf encoding")
+
+func marshalProtobuf(message any) ([]byte, error) {
+	switch m := message.(type) {
+	case GameStateMessage:
+		return appendGameStateMessage(nil, &m), nil
+	case *GameStateMessage:
+		return appendGameStateMessage(nil, m), nil
+	case PlayerOnlineMessage:
+		return appendPlayerMessage(nil, &m.Message, &m.Player), nil
+	case *PlayerOnlineMessage:
+		return appendPlayerMessage(nil, &m.Message, &m.Player), nil
+	case PlayerOfflineMessage:
+		return appendPlayerMessage(nil, &m.Message, &m.Player), nil
+	case *PlayerOfflineMessage:
+		return appendPlayerMessage(nil, &m.Message, &m.Player), nil
+	case PlayerVoteMessage:
+		return appendPlayerVoteMessage(nil, &m), nil
+	case *PlayerVoteMessage:
+		return appendPlayerVoteMessage(nil, m), nil
+	case StateDeltaMessage:
+		return appendStateDeltaMessage(nil, &m), nil
+	case *StateDeltaMessage:
+		return appendStateDeltaMessage(nil, m), nil
+	case StateRequestMessage:
+		return appendStateRequestMessage(nil, &m), nil
+	case *StateRequestMessage:
+		return appendStateRequestMessage(nil, m), nil
+	case DealerTransferMessage:
+		return appendDealerTransferMessage(nil, &m), nil
+	case *DealerTransferMessage:
+		return appendDealerTransferMessage(nil, m), nil
+	default:
+		return nil, ErrUnsupportedProtobufMessage
+	}
+}
+
+func unmarshalProtobuf(payload []byte, message any) error {
+	var err error
+	switch m := message.(type) {
+	case *Message:
+		err = consumeMessage(payload, m, nil)
+	case *GameStateMessage:
+		err = consumeMessage(payload, &m.Message, func(f protoField) error {
+			switch f.num {
+			case 4:
+				return consumeState(f.bytes, &m.State)
+			case 5:
+				m.Sequence = int64(f.varint)
+			}
+			return nil
+		})
+	case *PlayerOnlineMessage:
+		err = consumeMessage(payload, &m.Message, func(f protoField) error {
+			if f.num == 4 {
+				return consumePlayer(f.bytes, &m.Player)
+			}
+			return nil
+		})
+	case *PlayerOfflineMessage:
+		err = consumeMessage(payload, &m.Message, func(f protoField) error {
+			if f.num == 4 {
+				return consumePlayer(f.bytes, &m.Player)
+			}
+			return nil
+		})
+	case *PlayerVoteMessage:
+		err = consumeMessage(payload, &m.Message, func(f protoField) error {
+			switch f.num {
+			case 4:
+				m.PlayerID = PlayerID(f.bytes)
+			case 5:
+				m.Issue = IssueID(f.bytes)
+			case 6:
+				return consumeVoteResult(f.bytes, &m.VoteResult)
+			}
+			return nil
+		})
+	case *StateDeltaMessage:
+		err = consumeMessage(payload, &m.Message, func(f protoField) error {
+			switch f.num {
+			case 4:
+				m.Sequence = int64(f.varint)
+			case 5:
+				m.BaseSequence = int64(f.varint)
+			case 6:
+				return consumeStateDelta(f.bytes, &m.Delta)
+			}
+			return nil
+		})
+	case *StateRequestMessage:
+		err = consumeMessage(payload, &m.Message, func(f protoField) error {
+			switch f.num {
+			case 4:
+				m.PlayerID = PlayerID(f.bytes)
+			case 5:
+				m.Sequence = int64(f.varint)
+			}
+			return nil
+		})
+	case *DealerTransferMessage:
+		err = consumeMessage(payload, &m.Message, func(f protoField) error {
+			switch f.num {
+			case 4:
+				m.Dealer = PlayerID(f.bytes)
+			case 5:
+				return consumeState(f.bytes, &m.State)
+			case 6:
+				m.Sequence = int64(f.varint)
+			}
+			return nil
+		})
+	default:
+		return ErrUnsupportedProtobufMessage
+	}
+	return err
+}
+
+func appendHeader(b []byte, message *Message) []byte {
+	b = appendString(b, headerTypeField, string(message.Type))
+	b = appendInt64(b, headerTimestampField, message.Timestamp)
+	b = appendBytes(b, headerSignatureField, message.Signature)
+	return b
+}
+
+func appendGameStateMessage(b []byte, message *GameStateMessage) []byte {
+	b = appendHeader(b, &message.Message)
+	b = appendMessage(b, 4, appendState(nil, &message.State))
+	b = appendInt64(b, 5, message.Sequence)
+	return b
+}
+
+func appendStateDeltaMessage(b []byte, message *StateDeltaMessage) []byte {
+	b = appendHeader(b, &message.Message)
+	b = appendInt64(b, 4, message.Sequence)
+	b = appendInt64(b, 5, message.BaseSequence)
+	b = appendMessage(b, 6, appendStateDelta(nil, &message.Delta))
+	return b
+}
+
+func appendStateRequestMessage(b []byte, message *StateRequestMessage) []byte {
+	b = appendHeader(b, &message.Message)
+	b = appendString(b, 4, string(message.PlayerID))
+	b = appendInt64(b, 5, message.Sequence)
+	return b
+}
+
+func appendPlayerMessage(b []byte, message *Message, player *Player) []byte {
+	b = appendHeader(b, message)
+	b = appendMessage(b, 4, appendPlayer(nil, player))
+	return b
+}
+
+func appendPlayerVoteMessage(b []byte, message *PlayerVoteMessage) []byte {
+	b = appendHeader(b, &message.Message)
+	b = appendString(b, 4, string(message.PlayerID))
+	b = appendString(b, 5, string(message.Issue))
+	b = appendMessage(b, 6, appendVoteResult(nil, &message.VoteResult))
+	return b
+}
+
+func appendState(b []byte, state *State) []byte {
+	for i := range state.Players {
+		b = appendMessage(b, 1, appendPlayer(nil, &state.Players[i]))
+	}
+	for _, issue := range state.Issues {
+		b = appendMessage(b, 2, appendIssue(nil, issue))
+	}
+	b = appendString(b, 3, string(state.ActiveIssue))
+	b = appendBool(b, 4, state.VotesRevealed)
+	for _, card := range state.Deck {
+		b = appendRepeatedString(b, 5, string(card.Value))
+	}
+	b = appendBytes(b, 6, state.DealerPublicKey)
+	b = appendString(b, 7, string(state.Dealer))
+	b = appendCardsMetadata(b, 8, state.Deck)
+	if !state.Hints.Empty() {
+		b = appendMessage(b, 9, appendHintSettings(nil, state.Hints))
+	}
+	b = appendInt64(b, 10, state.Timebox)
+	b = appendInt64(b, 11, state.VotingDeadline)
+	if !state.AutoReveal.Empty() {
+		b = appendMessage(b, 12, appendAutoRevealSettings(nil, state.AutoReveal))
+	}
+	return b
+}
+
+func appendDealerTransferMessage(b []byte, message *DealerTransferMessage) []byte {
+	b = appendHeader(b, &message.Message)
+	b = appendString(b, 4, string(message.Dealer))
+	b = appendMessage(b, 5, appendState(nil, &message.State))
+	b = appendInt64(b, 6, message.Sequence)
+	return b
+}
+
+func appendStateDelta(b []byte, delta *StateDelta) []byte {
+	for i := range delta.Players {
+		b = appendMessage(b, 1, appendPlayer(nil, &delta.Players[i]))
+	}
+	for _, issue := range delta.Issues {
+		b = appendMessage(b, 2, appendIssue(nil, issue))
+	}
+	for issueID, votes := range delta.Votes {
+		var entry []byte
+		entry = appendString(entry, 1, string(issueID))
+		entry = appendMessage(entry, 2, appendVotes(nil, 1, votes))
+		b = appendMessage(b, 3, entry)
+	}
+	for issueID, playerIDs := range delta.RemovedVotes {
+		var entry []byte
+		entry = appendString(entry, 1, string(issueID))
+		for _, playerID := range playerIDs {
+			entry = appendRepeatedString(entry, 2, string(playerID))
+		}
+		b = appendMessage(b, 4, entry)
+	}
+	for _, issueID := range delta.RemovedIssues {
+		b = appendRepeatedString(b, 5, string(issueID))
+	}
+	for _, issueID := range delta.IssuesOrder {
+		b = appendRepeatedString(b, 6, string(issueID))
+	}
+	if delta.ActiveIssue != nil {
+		b = appendRepeatedString(b, 7, string(*delta.ActiveIssue))
+	}
+	if delta.VotesRevealed != nil {
+		b = protowire.AppendTag(b, 8, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(*delta.VotesRevealed))
+	}
+	for _, card := range delta.Deck {
+		b = appendRepeatedString(b, 9, string(card.Value))
+	}
+	b = appendBytes(b, 10, delta.DealerPublicKey)
+	if delta.Dealer != nil {
+		b = appendRepeatedString(b, 11, string(*delta.Dealer))
+	}
+	b = appendCardsMetadata(b, 12, delta.Deck)
+	if delta.Hints != nil {
+		// Explicit presence: empty settings reset the hints to defaults
+		b = appendMessage(b, 13, appendHintSettings(nil, delta.Hints))
+	}
+	if delta.Timebox != nil {
+		b = appendOptionalInt64(b, 14, *delta.Timebox)
+	}
+	if delta.VotingDeadline != nil {
+		b = appendOptionalInt64(b, 15, *delta.VotingDeadline)
+	}
+	if delta.AutoReveal != nil {
+		// Explicit presence: empty settings reset the auto reveal to defaults
+		b = appendMessage(b, 16, appendAutoRevealSettings(nil, delta.AutoReveal))
+	}
+	return b
+}
+
+// appendCardsMetadata encodes only the cards with metadata, the deck values are encoded separately.
+// This way older clients still get the plain deck.
+func appendCardsMetadata(b []byte, num protowire.Number, deck Deck) []byte {
+	for _, card := range deck {
+		if !card.HasMetadata() {
+			continue
+		}
+		var entry []byte
+		entry = appendRepeatedString(entry, 1, string(card.Value))
+		entry = appendString(entry, 2, card.Label)
+		if card.Number != nil {
+			entry = appendDouble(entry, 3, *card.Number)
+		}
+		entry = appendString(entry, 4, string(card.Kind))
+		b = appendMessage(b, num, entry)
+	}
+	return b
+}
+
+func appendHintSettings(b []byte, settings *HintSettings) []byte {
+	b = appendString(b, 1, string(settings.Strategy))
+	if settings.MaxMeanDeviation != 0 {
+		b = appendDouble(b, 2, settings.MaxMeanDeviation)
+	}
+	if settings.MaxDeviation != 0 {
+		b = appendDouble(b, 3, settings.MaxDeviation)
+	}
+	return b
+}
+
+func appendAutoRevealSettings(b []byte, settings *AutoRevealSettings) []byte {
+	b = appendString(b, 1, string(settings.Policy))
+	b = appendInt64(b, 2, int64(settings.Quorum))
+	if settings.Delay != nil {
+		b = appendOptionalInt64(b, 3, *settings.Delay)
+	}
+	return b
+}
+
+func appendPlayer(b []byte, player *Player) []byte {
+	b = appendString(b, 1, string(player.ID))
+	b = appendString(b, 2, player.Name)
+	b = appendBool(b, 3, player.Online)
+	b = appendInt64(b, 4, player.OnlineTimestampMilliseconds)
+	b = appendBytes(b, 5, player.PublicKey)
+	b = appendString(b, 6, string(player.Role))
+	return b
+}
+
+func appendIssue(b []byte, issue *Issue) []byte {
+	b = appendString(b, 1, string(issue.ID))
+	b = appendString(b, 2, issue.TitleOrURL)
+	b = appendVotes(b, 3, issue.Votes)
+	if issue.Result != nil {
+		// Explicit presence: empty result is different from no result
+		b = appendRepeatedString(b, 4, string(*issue.Result))
+	}
+	for _, round := range issue.Rounds {
+		b = appendMessage(b, 5, appendIssueRound(nil, &round))
+	}
+	return b
+}
+
+func appendIssueRound(b []byte, round *IssueRound) []byte {
+	b = appendVotes(b, 1, round.Votes)
+	b = appendInt64(b, 2, round.Timestamp)
+	if round.Result != nil {
+		// Explicit presence, same as the issue result
+		b = appendRepeatedString(b, 3, string(*round.Result))
+	}
+	if round.Hint != nil {
+		b = appendMessage(b, 4, appendHint(nil, round.Hint))
+	}
+	return b
+}
+
+func appendHint(b []byte, hint *Hint) []byte {
+	b = appendBool(b, 1, hint.Acceptable)
+	b = appendString(b, 2, string(hint.Value))
+	b = appendString(b, 3, hint.Description)
+	if hint.Average != nil {
+		b = appendDouble(b, 4, *hint.Average)
+	}
+	for _, playerID := range hint.LowestVoters {
+		b = appendRepeatedString(b, 5, string(playerID))
+	}
+	for _, playerID := range hint.HighestVoters {
+		b = appendRepeatedString(b, 6, string(playerID))
+	}
+	return b
+}
+
+// appendVotes encodes votes as map<string, VoteResult>
+func appendVotes(b []byte, num protowire.Number, votes IssueVotes) []byte {
+	for playerID, vote := range votes {
+		var entry []byte
+		entry = appendString(entry, 1, string(playerID))
+		entry = appendMessage(entry, 2, appendVoteResult(nil, &vote))
+		b = appendMessage(b, num, entry)
+	}
+	return b
+}
+
+func appendVoteResult(b []byte, vote *VoteResult) []byte {
+	b = appendString(b, 1, string(vote.Value))
+	b = appendInt64(b, 2, vote.Timestamp)
+	return b
+}
+
+func consumeMessage(payload []byte, message *Message, fn func(f protoField) error) error {
+	return rangeFields(payload, func(f protoField) error {
+		switch f.num {
+		case headerTypeField:
+			message.Type = MessageType(f.bytes)
+		case headerTimestampField:
+			message.Timestamp = int64(f.varint)
+		case headerSignatureField:
+			message.Signature = cloneBytes(f.bytes)
+		default:
+			if fn != nil {
+				return fn(f)
+			}
+		}
+		return nil
+	})
+}
+
+func consumeState(b []byte, state *State) error {
+	var cards []Card
+	err := rangeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			player := Player{}
+			if err := consumePlayer(f.bytes, &player); err != nil {
+				return err
+			}
+			state.Players = append(state.Players, player)
+		case 2:
+			issue := &Issue{}
+			if err := consumeIssue(f.bytes, issue); err != nil {
+				return err
+			}
+			state.Issues = append(state.Issues, issue)
+		case 3:
+			state.ActiveIssue = IssueID(f.bytes)
+		case 4:
+			state.VotesRevealed = protowire.DecodeBool(f.varint)
+		case 5:
+			state.Deck = append(state.Deck, Card{Value: VoteValue(f.bytes)})
+		case 6:
+			state.DealerPublicKey = cloneBytes(f.bytes)
+		case 7:
+			state.Dealer = PlayerID(f.bytes)
+		case 8:
+			card := Card{}
+			if err := consumeCard(f.bytes, &card); err != nil {
+				return err
+			}
+			cards = append(cards, card)
+		case 9:
+			state.Hints = &HintSettings{}
+			if err := consumeHintSettings(f.bytes, state.Hints); err != nil {
+				return err
+			}
+		case 10:
+			state.Timebox = int64(f.varint)
+		case 11:
+			state.VotingDeadline = int64(f.varint)
+		case 12:
+			state.AutoReveal = &AutoRevealSettings{}
+			if err := consumeAutoRevealSettings(f.bytes, state.AutoReveal); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	mergeCardsMetadata(state.Deck, cards)
+	return nil
+}
+
+func consumeStateDelta(b []byte, delta *StateDelta) error {
+	var cards []Card
+	err := rangeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			player := Player{}
+			if err := consumePlayer(f.bytes, &player); err != nil {
+				return err
+			}
+			delta.Players = append(delta.Players, player)
+		case 2:
+			issue := &Issue{}
+			if err := consumeIssue(f.bytes, issue); err != nil {
+				return err
+			}
+			delta.Issues = append(delta.Issues, issue)
+		case 3:
+			var issueID IssueID
+			votes := IssueVotes{}
+			err := rangeFields(f.bytes, func(entry protoField) error {
+				switch entry.num {
+				case 1:
+					issueID = IssueID(entry.bytes)
+				case 2:
+					return rangeFields(entry.bytes, func(vote protoField) error {
+						if vote.num == 1 {
+							return consumeVote(vote.bytes, votes)
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if delta.Votes == nil {
+				delta.Votes = make(map[IssueID]IssueVotes)
+			}
+			delta.Votes[issueID] = votes
+		case 4:
+			var issueID IssueID
+			var playerIDs []PlayerID
+			err := rangeFields(f.bytes, func(entry protoField) error {
+				switch entry.num {
+				case 1:
+					issueID = IssueID(entry.bytes)
+				case 2:
+					playerIDs = append(playerIDs, PlayerID(entry.bytes))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if delta.RemovedVotes == nil {
+				delta.RemovedVotes = make(map[IssueID][]PlayerID)
+			}
+			delta.RemovedVotes[issueID] = playerIDs
+		case 5:
+			delta.RemovedIssues = append(delta.RemovedIssues, IssueID(f.bytes))
+		case 6:
+			delta.IssuesOrder = append(delta.IssuesOrder, IssueID(f.bytes))
+		case 7:
+			activeIssue := IssueID(f.bytes)
+			delta.ActiveIssue = &activeIssue
+		case 8:
+			votesRevealed := protowire.DecodeBool(f.varint)
+			delta.VotesRevealed = &votesRevealed
+		case 9:
+			delta.Deck = append(delta.Deck, Card{Value: VoteValue(f.bytes)})
+		case 10:
+			delta.DealerPublicKey = cloneBytes(f.bytes)
+		case 11:
+			dealer := PlayerID(f.bytes)
+			delta.Dealer = &dealer
+		case 12:
+			card := Card{}
+			if err := consumeCard(f.bytes, &card); err != nil {
+				return err
+			}
+			cards = append(cards, card)
+		case 13:
+			delta.Hints = &HintSettings{}
+			if err := consumeHintSettings(f.bytes, delta.Hints); err != nil {
+				return err
+			}
+		case 14:
+			timebox := int64(f.varint)
+			delta.Timebox = &timebox
+		case 15:
+			deadline := int64(f.varint)
+			delta.VotingDeadline = &deadline
+		case 16:
+			delta.AutoReveal = &AutoRevealSettings{}
+			if err := consumeAutoRevealSettings(f.bytes, delta.AutoReveal); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	mergeCardsMetadata(delta.Deck, cards)
+	return nil
+}
+
+func consumeCard(b []byte, card *Card) error {
+	return rangeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			card.Value = VoteValue(f.bytes)
+		case 2:
+			card.Label = string(f.bytes)
+		case 3:
+			number := math.Float64frombits(f.varint)
+			card.Number = &number
+		case 4:
+			card.Kind = CardKind(f.bytes)
+		}
+		return nil
+	})
+}
+
+func consumeHintSettings(b []byte, settings *HintSettings) error {
+	return rangeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			settings.Strategy = HintStrategy(f.bytes)
+		case 2:
+			settings.MaxMeanDeviation = math.Float64frombits(f.varint)
+		case 3:
+			settings.MaxDeviation = math.Float64frombits(f.varint)
+		}
+		return nil
+	})
+}
+
+// mergeCardsMetadata replaces the deck cards with the decoded cards with metadata.
+func consumeAutoRevealSettings(b []byte, settings *AutoRevealSettings) error {
+	return rangeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			settings.Policy = AutoRevealPolicy(f.bytes)
+		case 2:
+			settings.Quorum = int(int64(f.varint))
+		case 3:
+			delay := int64(f.varint)
+			settings.Delay = &delay
+		}
+		return nil
+	})
+}
+
+func mergeCardsMetadata(deck Deck, cards []Card) {
+	for _, card := range cards {
+		index := deck.Index(card.Value)
+		if index >= 0 {
+			deck[index] = card
+		}
+	}
+}
+
+func consumePlayer(b []byte, player *Player) error {
+	return rangeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			player.ID = PlayerID(f.bytes)
+		case 2:
+			player.Name = string(f.bytes)
+		case 3:
+			player.Online = protowire.DecodeBool(f.varint)
+		case 4:
+			player.OnlineTimestampMilliseconds = int64(f.varint)
+			// Keep the deprecated field consistent, it's used by ApplyDeprecatedPatchOnReceive
+			player.OnlineTimestamp = time.UnixMilli(player.OnlineTimestampMilliseconds)
+		case 5:
+			player.PublicKey = cloneBytes(f.bytes)
+		case 6:
+			player.Role = PlayerRole(f.bytes)
+		}
+		return nil
+	})
+}
+
+func consumeIssue(b []byte, issue *Issue) error {
+	issue.Votes = IssueVotes{}
+	return rangeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			issue.ID = IssueID(f.bytes)
+		case 2:
+			issue.TitleOrURL = string(f.bytes)
+		case 3:
+			return consumeVote(f.bytes, issue.Votes)
+		case 4:
+			result := VoteValue(f.bytes)
+			issue.Result = &result
+		case 5:
+			round := IssueRound{}
+			if err := consumeIssueRound(f.bytes, &round); err != nil {
+				return err
+			}
+			issue.Rounds = append(issue.Rounds, round)
+		}
+		return nil
+	})
+}
+
+func consumeIssueRound(b []byte, round *IssueRound) error {
+	round.Votes = IssueVotes{}
+	return rangeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			return consumeVote(f.bytes, round.Votes)
+		case 2:
+			round.Timestamp = int64(f.varint)
+		case 3:
+			result := VoteValue(f.bytes)
+			round.Result = &result
+		case 4:
+			round.Hint = &Hint{}
+			return consumeHint(f.bytes, round.Hint)
+		}
+		return nil
+	})
+}
+
+func consumeHint(b []byte, hint *Hint) error {
+	return rangeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			hint.Acceptable = protowire.DecodeBool(f.varint)
+		case 2:
+			hint.Value = VoteValue(f.bytes)
+		case 3:
+			hint.Description = string(f.bytes)
+		case 4:
+			average := math.Float64frombits(f.varint)
+			hint.Average = &average
+		case 5:
+			hint.LowestVoters = append(hint.LowestVoters, PlayerID(f.bytes))
+		case 6:
+			hint.HighestVoters = append(hint.HighestVoters, PlayerID(f.bytes))
+		}
+		return nil
+	})
+}
+
+// consumeVote decodes a single map<string, VoteResult> entry into votes
+func consumeVote(b []byte, votes IssueVotes) error {
+	var playerID PlayerID
+	var vote VoteResult
+	err := rangeFields(b, func(entry protoField) error {
+		switch entry.num {
+		case 1:
+			playerID = PlayerID(entry.bytes)
+		case 2:
+			return consumeVoteResult(entry.bytes, &vote)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	votes[playerID] = vote
+	return nil
+}
+
+func consumeVoteResult(b []byte, vote *VoteResult) error {
+	return rangeFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			vote.Value = VoteValue(f.bytes)
+		case 2:
+			vote.Timestamp = int64(f.varint)
+		}
+		return nil
+	})
+}
+
+type protoField struct {
+	num    protowire.Number
+	typ    protowire.Type
+	varint uint64 // Also holds fixed64 values
+	bytes  []byte
+}
+
+// rangeFields calls fn for each field of the encoded message.
+// Unknown fields are passed to fn as well and are expected to be ignored for forward compatibility.
+func rangeFields(b []byte, fn func(f protoField) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errors.Wrap(protowire.ParseError(n), "failed to parse field tag")
+		}
+		b = b[n:]
+
+		f := protoField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			f.varint, n = protowire.ConsumeFixed64(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return errors.Wrapf(protowire.ParseError(n), "failed to parse field %d", num)
+		}
+		b = b[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// appendRepeatedString appends a string field even if it's empty,
+// used for repeated fields and fields with explicit presence.
+func appendRepeatedString(b []byte, num protowire.Number, v string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendMessage(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendInt64(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+// appendOptionalInt64 encodes the value even if it's zero, for fields with explicit presence.
+func appendOptionalInt64(b []byte, num protowire.Number, v int64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	if !v {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, protowire.EncodeBool(v))
+}
+
+func cloneBytes(b []byte) []byte {
+	return append([]byte(nil), b...)
+}
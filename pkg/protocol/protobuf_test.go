@@ -0,0 +1,324 @@
+package protocol
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var protoScalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"string": descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"bytes":  descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+	"bool":   descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"int64":  descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"double": descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+}
+
+// protoSchemaParser builds the descriptor of messages.proto, so that the manual encoding is checked against it.
+// Only the subset of proto3 used by the schema is supported: messages with scalar, message, repeated,
+// optional and map fields.
+type protoSchemaParser struct {
+	t      *testing.T
+	tokens []string
+	file   *descriptorpb.FileDescriptorProto
+}
+
+func loadProtoSchema(t *testing.T) protoreflect.FileDescriptor {
+	source, err := os.ReadFile("messages.proto")
+	require.NoError(t, err)
+
+	source = regexp.MustCompile(`//.*`).ReplaceAll(source, nil)
+	p := &protoSchemaParser{
+		t:      t,
+		tokens: regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.]*|\d+|[{}<>=;,]`).FindAllString(string(source), -1),
+		file: &descriptorpb.FileDescriptorProto{
+			Name: proto.String("messages.proto"),
+		},
+	}
+
+	for len(p.tokens) > 0 {
+		switch token := p.next(); token {
+		case "syntax":
+			p.expect("=")
+			p.file.Syntax = proto.String(p.next())
+			p.expect(";")
+		case "package":
+			p.file.Package = proto.String(p.next())
+			p.expect(";")
+		case "message":
+			p.message()
+		default:
+			require.Failf(t, "unexpected token in schema", "%s", token)
+		}
+	}
+
+	schema, err := protodesc.NewFile(p.file, nil)
+	require.NoError(t, err)
+	return schema
+}
+
+func (p *protoSchemaParser) next() string {
+	require.NotEmpty(p.t, p.tokens, "unexpected end of schema")
+	token := p.tokens[0]
+	p.tokens = p.tokens[1:]
+	return token
+}
+
+func (p *protoSchemaParser) expect(expected string) {
+	require.Equal(p.t, expected, p.next())
+}
+
+func (p *protoSchemaParser) message() {
+	message := &descriptorpb.DescriptorProto{
+		Name: proto.String(p.next()),
+	}
+	p.expect("{")
+	for p.tokens[0] != "}" {
+		p.field(message)
+	}
+	p.expect("}")
+	p.file.MessageType = append(p.file.MessageType, message)
+}
+
+func (p *protoSchemaParser) field(message *descriptorpb.DescriptorProto) {
+	field := &descriptorpb.FieldDescriptorProto{
+		Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+
+	var entry *descriptorpb.DescriptorProto
+	typeName := p.next()
+	switch typeName {
+	case "repeated":
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		typeName = p.next()
+	case "optional":
+		field.Proto3Optional = proto.Bool(true)
+		typeName = p.next()
+	case "map":
+		p.expect("<")
+		key := p.newField("key", 1, p.next())
+		p.expect(",")
+		value := p.newField("value", 2, p.next())
+		p.expect(">")
+		entry = &descriptorpb.DescriptorProto{
+			Field:   []*descriptorpb.FieldDescriptorProto{key, value},
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	}
+
+	name := p.next()
+	p.expect("=")
+	number, err := strconv.Atoi(p.next())
+	require.NoError(p.t, err)
+	p.expect(";")
+
+	field.Name = proto.String(name)
+	field.Number = proto.Int32(int32(number))
+
+	switch {
+	case entry != nil:
+		entry.Name = proto.String(camelCase(name) + "Entry")
+		message.NestedType = append(message.NestedType, entry)
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		field.TypeName = proto.String(p.typeName(message.GetName() + "." + entry.GetName()))
+	default:
+		p.setType(field, typeName)
+	}
+
+	if field.GetProto3Optional() {
+		// Explicit presence is declared with a synthetic oneof
+		message.OneofDecl = append(message.OneofDecl, &descriptorpb.OneofDescriptorProto{
+			Name: proto.String("_" + name),
+		})
+		field.OneofIndex = proto.Int32(int32(len(message.OneofDecl) - 1))
+	}
+
+	message.Field = append(message.Field, field)
+}
+
+func (p *protoSchemaParser) newField(name string, number int32, typeName string) *descriptorpb.FieldDescriptorProto {
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	p.setType(field, typeName)
+	return field
+}
+
+func (p *protoSchemaParser) setType(field *descriptorpb.FieldDescriptorProto, typeName string) {
+	if scalar, ok := protoScalarTypes[typeName]; ok {
+		field.Type = scalar.Enum()
+		return
+	}
+	field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	field.TypeName = proto.String(p.typeName(typeName))
+}
+
+func (p *protoSchemaParser) typeName(name string) string {
+	return "." + p.file.GetPackage() + "." + name
+}
+
+func camelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// requireNoUnknownFields checks that all fields were decoded with the schema, including nested messages.
+// Fields with unknown numbers or unexpected wire types are kept as unknown by the decoder.
+func requireNoUnknownFields(t *testing.T, message protoreflect.Message) {
+	require.Empty(t, message.GetUnknown(), "unknown fields in %s", message.Descriptor().FullName())
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case field.IsMap():
+			if field.MapValue().Message() != nil {
+				value.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+					requireNoUnknownFields(t, value.Message())
+					return true
+				})
+			}
+		case field.IsList():
+			if field.Message() != nil {
+				for i := 0; i < value.List().Len(); i++ {
+					requireNoUnknownFields(t, value.List().Get(i).Message())
+				}
+			}
+		case field.Message() != nil:
+			requireNoUnknownFields(t, value.Message())
+		}
+		return true
+	})
+}
+
+func TestProtobufSchema(t *testing.T) {
+	schema := loadProtoSchema(t)
+
+	state := fakeProtobufState()
+	issue := state.Issues[0]
+	player := state.Players[0]
+	activeIssue := issue.ID
+	votesRevealed := false // Explicit false differs from a missing value
+	dealer := PlayerID(gofakeit.LetterN(5))
+	timebox := int64(0)
+	votingDeadline := gofakeit.Date().UnixMilli()
+
+	header := func(messageType MessageType) Message {
+		return Message{
+			Type:      messageType,
+			Timestamp: gofakeit.Date().UnixMilli(),
+		}
+	}
+
+	messages := map[string]any{
+		"GameStateMessage": GameStateMessage{
+			Message:  header(MessageTypeState),
+			State:    state,
+			Sequence: gofakeit.Int64(),
+		},
+		"StateDeltaMessage": StateDeltaMessage{
+			Message:      header(MessageTypeStateDelta),
+			Sequence:     gofakeit.Int64(),
+			BaseSequence: gofakeit.Int64(),
+			Delta: StateDelta{
+				Players:         state.Players,
+				Issues:          state.Issues,
+				Votes:           map[IssueID]IssueVotes{issue.ID: issue.Votes},
+				RemovedVotes:    map[IssueID][]PlayerID{issue.ID: {player.ID}},
+				RemovedIssues:   []IssueID{IssueID(gofakeit.LetterN(5))},
+				IssuesOrder:     []IssueID{state.Issues[1].ID, issue.ID},
+				ActiveIssue:     &activeIssue,
+				VotesRevealed:   &votesRevealed,
+				Deck:            state.Deck,
+				DealerPublicKey: state.DealerPublicKey,
+				Dealer:          &dealer,
+				Hints:           &HintSettings{}, // Empty settings reset to defaults, so they must be encoded
+				Timebox:         &timebox,
+				VotingDeadline:  &votingDeadline,
+				AutoReveal:      state.AutoReveal,
+			},
+		},
+		"StateRequestMessage": StateRequestMessage{
+			Message:  header(MessageTypeStateRequest),
+			PlayerID: player.ID,
+			Sequence: gofakeit.Int64(),
+		},
+		"PlayerOnlineMessage": PlayerOnlineMessage{
+			Message: header(MessageTypePlayerOnline),
+			Player:  player,
+		},
+		"PlayerOfflineMessage": PlayerOfflineMessage{
+			Message: header(MessageTypePlayerOffline),
+			Player:  player,
+		},
+		"PlayerVoteMessage": PlayerVoteMessage{
+			Message:    header(MessageTypePlayerVote),
+			PlayerID:   player.ID,
+			Issue:      issue.ID,
+			VoteResult: *NewVoteResult(VoteValue(gofakeit.LetterN(1))),
+		},
+		"DealerTransferMessage": DealerTransferMessage{
+			Message:  header(MessageTypeDealerTransfer),
+			Dealer:   dealer,
+			State:    state,
+			Sequence: gofakeit.Int64(),
+		},
+	}
+
+	// Every message of the schema is checked
+	for i := 0; i < schema.Messages().Len(); i++ {
+		name := string(schema.Messages().Get(i).Name())
+		if strings.HasSuffix(name, "Message") {
+			require.Contains(t, messages, name)
+		}
+	}
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	for name, message := range messages {
+		t.Run(name, func(t *testing.T) {
+			descriptor := schema.Messages().ByName(protoreflect.Name(name))
+			require.NotNil(t, descriptor)
+
+			payload, err := Marshal(message, EncodingProtobuf)
+			require.NoError(t, err)
+			payload, err = SignMessage(payload, key)
+			require.NoError(t, err)
+
+			decoded := dynamicpb.NewMessage(descriptor)
+			err = proto.Unmarshal(payload, decoded)
+			require.NoError(t, err)
+			requireNoUnknownFields(t, decoded)
+
+			// Encoding of a generic protobuf implementation is decoded to the same message
+			encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(decoded)
+			require.NoError(t, err)
+
+			expected := reflect.New(reflect.TypeOf(message))
+			err = Unmarshal(payload, expected.Interface())
+			require.NoError(t, err)
+
+			received := reflect.New(reflect.TypeOf(message))
+			err = Unmarshal(encoded, received.Interface())
+			require.NoError(t, err)
+
+			require.Equal(t, expected.Interface(), received.Interface())
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -75,3 +76,303 @@ func TestOnlineTimestampMigrationForward(t *testing.T) {
 	require.Equal(t, player.Name, playerReceived.Name)
 	require.Equal(t, now.UnixMilli(), playerReceived.OnlineTimestamp.UnixMilli())
 }
+
+func TestMessageSignature(t *testing.T) {
+	for _, encoding := range Encodings {
+		t.Run(string(encoding), func(t *testing.T) {
+			testMessageSignature(t, encoding)
+		})
+	}
+}
+
+func testMessageSignature(t *testing.T, encoding Encoding) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	message := PlayerVoteMessage{
+		Message: Message{
+			Type:      MessageTypePlayerVote,
+			Timestamp: time.Now().UnixMilli(),
+		},
+		PlayerID:   PlayerID(gofakeit.LetterN(5)),
+		Issue:      IssueID(gofakeit.LetterN(5)),
+		VoteResult: *NewVoteResult(VoteValue(gofakeit.LetterN(1))),
+	}
+
+	payload, err := Marshal(message, encoding)
+	require.NoError(t, err)
+	require.Equal(t, encoding, DetectEncoding(payload))
+
+	_, err = VerifyMessage(payload)
+	require.ErrorIs(t, err, ErrMessageNotSigned)
+
+	signedPayload, err := SignMessage(payload, key)
+	require.NoError(t, err)
+
+	signer, err := VerifyMessage(signedPayload)
+	require.NoError(t, err)
+	require.True(t, signer.Equal(&key.PublicKey))
+
+	// Signed message is still a valid message
+	vote, err := UnmarshalPlayerVote(signedPayload)
+	require.NoError(t, err)
+	require.Equal(t, message.PlayerID, vote.PlayerID)
+	require.NotEmpty(t, vote.Signature)
+
+	// Any change invalidates the signature
+	vote.Issue = IssueID(gofakeit.LetterN(6))
+	tamperedPayload, err := Marshal(vote, encoding)
+	require.NoError(t, err)
+
+	signer, err = VerifyMessage(tamperedPayload)
+	if err == nil {
+		require.False(t, signer.Equal(&key.PublicKey))
+	}
+}
+
+// fakeProtobufState returns a state with all the fields that are encoded with protobuf.
+func fakeProtobufState() State {
+	result := VoteValue(gofakeit.LetterN(1))
+	average := gofakeit.Float64()
+
+	var state State
+	gofakeit.Struct(&state)
+	state.Timestamp = 0
+	state.Issues = IssuesList{
+		{
+			ID:         IssueID(gofakeit.LetterN(5)),
+			TitleOrURL: gofakeit.URL(),
+			Votes: IssueVotes{
+				PlayerID(gofakeit.LetterN(5)): *NewVoteResult(VoteValue(gofakeit.LetterN(1))),
+				PlayerID(gofakeit.LetterN(5)): *NewVoteResult(""),
+			},
+			Result: &result,
+			Rounds: []IssueRound{
+				{
+					Votes: IssueVotes{
+						PlayerID(gofakeit.LetterN(5)): *NewVoteResult(VoteValue(gofakeit.LetterN(1))),
+					},
+					Result:    &result,
+					Timestamp: gofakeit.Date().UnixMilli(),
+					Hint: &Hint{
+						Value:         result,
+						Description:   gofakeit.Sentence(3),
+						Average:       &average,
+						LowestVoters:  []PlayerID{PlayerID(gofakeit.LetterN(5))},
+						HighestVoters: []PlayerID{PlayerID(gofakeit.LetterN(5))},
+					},
+				},
+				{
+					Votes: IssueVotes{
+						PlayerID(gofakeit.LetterN(5)): *NewVoteResult(VoteValue(gofakeit.LetterN(1))),
+					},
+					Timestamp: gofakeit.Date().UnixMilli(),
+				},
+			},
+		},
+		{
+			ID:         IssueID(gofakeit.LetterN(5)),
+			TitleOrURL: gofakeit.URL(),
+			Votes:      IssueVotes{},
+		},
+	}
+	for i := range state.Players {
+		state.Players[i].OnlineTimestampMilliseconds = gofakeit.Date().UnixMilli()
+		state.Players[i].ApplyDeprecatedPatchOnSend()
+	}
+	number := gofakeit.Float64()
+	state.Deck = Deck{
+		{Value: VoteValue(gofakeit.LetterN(2)), Label: gofakeit.Word(), Number: &number},
+		{Value: VoteValue(gofakeit.LetterN(3))},
+		{Value: UncertaintyCard, Kind: BreakCardKind},
+	}
+	state.Hints = &HintSettings{Strategy: ModeHintStrategy, MaxDeviation: gofakeit.Float64()}
+	delay := int64(0) // Zero delay differs from the default one, so it must be encoded
+	state.AutoReveal = &AutoRevealSettings{Policy: AutoRevealQuorum, Quorum: 75, Delay: &delay}
+
+	return state
+}
+
+func TestProtobufEncoding(t *testing.T) {
+	state := fakeProtobufState()
+
+	message := GameStateMessage{
+		Message: Message{
+			Type:      MessageTypeState,
+			Timestamp: time.Now().UnixMilli(),
+		},
+		State: state,
+	}
+
+	// Protobuf encoding should carry exactly the same data as JSON
+	jsonPayload, err := Marshal(message, EncodingJSON)
+	require.NoError(t, err)
+	require.Equal(t, EncodingJSON, DetectEncoding(jsonPayload))
+	require.Equal(t, EncodingJSON, DetectEncoding(append([]byte(" \n\t"), jsonPayload...)))
+
+	protoPayload, err := Marshal(message, EncodingProtobuf)
+	require.NoError(t, err)
+	require.Equal(t, EncodingProtobuf, DetectEncoding(protoPayload))
+	require.Less(t, len(protoPayload), len(jsonPayload))
+
+	var jsonReceived GameStateMessage
+	err = Unmarshal(jsonPayload, &jsonReceived)
+	require.NoError(t, err)
+
+	var protoReceived GameStateMessage
+	err = Unmarshal(protoPayload, &protoReceived)
+	require.NoError(t, err)
+
+	// Deprecated field is only compared by value, time location differs
+	for i := range jsonReceived.State.Players {
+		require.True(t, jsonReceived.State.Players[i].OnlineTimestamp.Equal(protoReceived.State.Players[i].OnlineTimestamp))
+		protoReceived.State.Players[i].OnlineTimestamp = jsonReceived.State.Players[i].OnlineTimestamp
+	}
+
+	require.Equal(t, jsonReceived, protoReceived)
+
+	header, err := UnmarshalMessage(protoPayload)
+	require.NoError(t, err)
+	require.Equal(t, message.Message, *header)
+}
+
+func TestDeckJSON(t *testing.T) {
+	// Plain decks are encoded as before
+	deck := DeckFromValues("1", "2", "?")
+	payload, err := json.Marshal(deck)
+	require.NoError(t, err)
+	require.JSONEq(t, `["1", "2", "?"]`, string(payload))
+
+	var received Deck
+	err = json.Unmarshal(payload, &received)
+	require.NoError(t, err)
+	require.Equal(t, deck, received)
+
+	// Cards with metadata are encoded as objects
+	number := 0.5
+	deck = Deck{{Value: "S", Label: "Small", Number: &number}, {Value: "☕"}}
+	payload, err = json.Marshal(deck)
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"value": "S", "label": "Small", "number": 0.5}, "☕"]`, string(payload))
+
+	received = nil
+	err = json.Unmarshal(payload, &received)
+	require.NoError(t, err)
+	require.True(t, deck.Equal(received))
+
+	value, ok := received[0].Numeric()
+	require.True(t, ok)
+	require.Equal(t, number, value)
+	require.Equal(t, BreakCardKind, received[1].CardKind())
+	_, ok = received[1].Numeric()
+	require.False(t, ok)
+}
+
+func TestStateDelta(t *testing.T) {
+	fakeIssue := func() *Issue {
+		return &Issue{
+			ID:         IssueID(gofakeit.UUID()),
+			TitleOrURL: gofakeit.URL(),
+			Votes:      IssueVotes{},
+		}
+	}
+	fakePlayer := func() Player {
+		return Player{
+			ID:   PlayerID(gofakeit.UUID()),
+			Name: gofakeit.Username(),
+		}
+	}
+
+	previous := &State{
+		Players: PlayersList{fakePlayer(), fakePlayer()},
+		Issues:  IssuesList{fakeIssue(), fakeIssue(), fakeIssue(), fakeIssue()},
+		Deck:    DeckFromValues("1", "2", "3"),
+		Timebox: time.Minute.Milliseconds(),
+	}
+	previous.Issues[0].Votes[previous.Players[0].ID] = *NewVoteResult("1")
+	previous.Issues[0].Votes[previous.Players[1].ID] = *NewVoteResult("2")
+
+	current := previous.Clone()
+	current.Players[1].Name = gofakeit.Username()
+	current.Players = append(current.Players, fakePlayer())
+	current.Issues[0].Votes[current.Players[0].ID] = *NewVoteResult("3")
+	delete(current.Issues[0].Votes, current.Players[1].ID)
+	result := VoteValue("3")
+	current.Issues[1].Result = &result
+	// Reorder, remove and add issues
+	current.Issues = IssuesList{current.Issues[0], current.Issues[2], current.Issues[1], fakeIssue()}
+	current.ActiveIssue = current.Issues[0].ID
+	current.VotesRevealed = true
+	current.Timebox = 0 // Zero values must be sent too
+	current.VotingDeadline = time.Now().UnixMilli()
+	current.AutoReveal = &AutoRevealSettings{Policy: AutoRevealOff}
+
+	delta := NewStateDelta(previous, current)
+	require.False(t, delta.Empty())
+	require.Len(t, delta.Players, 2)
+	require.Len(t, delta.Issues, 2) // Finished and new issue
+	require.Len(t, delta.Votes, 1)
+	require.Len(t, delta.RemovedVotes, 1)
+	require.Equal(t, []IssueID{previous.Issues[3].ID}, delta.RemovedIssues)
+	require.NotEmpty(t, delta.IssuesOrder)
+	require.Empty(t, delta.Deck)
+	require.NotNil(t, delta.Timebox)
+	require.NotNil(t, delta.VotingDeadline)
+	require.NotNil(t, delta.AutoReveal)
+
+	// Previous state is not modified
+	require.Len(t, previous.Issues, 4)
+	require.Len(t, previous.Issues[0].Votes, 2)
+
+	applied := previous.Clone()
+	applied.ApplyDelta(delta)
+	require.True(t, applied.Equal(current))
+
+	require.True(t, NewStateDelta(current, current.Clone()).Empty())
+
+	// Protobuf encoding should carry exactly the same delta as JSON
+	message := StateDeltaMessage{
+		Message: Message{
+			Type:      MessageTypeStateDelta,
+			Timestamp: time.Now().UnixMilli(),
+		},
+		Sequence:     gofakeit.Int64(),
+		BaseSequence: gofakeit.Int64(),
+		Delta:        *delta,
+	}
+
+	for _, encoding := range Encodings {
+		payload, err := Marshal(message, encoding)
+		require.NoError(t, err)
+		require.Equal(t, encoding, DetectEncoding(payload))
+
+		var received StateDeltaMessage
+		err = Unmarshal(payload, &received)
+		require.NoError(t, err)
+		require.Equal(t, message.Sequence, received.Sequence)
+		require.Equal(t, message.BaseSequence, received.BaseSequence)
+
+		applied = previous.Clone()
+		applied.ApplyDelta(&received.Delta)
+		require.True(t, applied.Equal(current), encoding)
+	}
+}
+
+func TestAllPlayersVoted(t *testing.T) {
+	issue := &Issue{ID: "1", Votes: IssueVotes{}}
+	state := &State{
+		Players: PlayersList{
+			{ID: "voter"},
+			{ID: "observer", Role: ObserverRole},
+		},
+		Issues: IssuesList{issue},
+	}
+	require.False(t, state.AllPlayersVoted()) // No active issue
+	require.Equal(t, PlayersList{state.Players[0]}, state.Players.Voters())
+
+	state.ActiveIssue = issue.ID
+	require.False(t, state.AllPlayersVoted())
+
+	issue.Votes["voter"] = *NewVoteResult("1")
+	require.True(t, state.AllPlayersVoted())
+}
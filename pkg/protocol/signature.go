@@ -0,0 +1,158 @@
+package protocol
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const signatureField = "signature"
+
+var (
+	ErrMessageNotSigned = errors.New("message is not signed")
+	ErrInvalidSignature = errors.New("invalid message signature")
+)
+
+// SignMessage signs given encoded message and returns the payload with the signature.
+// The signature is calculated over the canonical form of the message,
+// see canonicalMessage and canonicalProtobufMessage.
+func SignMessage(payload []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	if DetectEncoding(payload) == EncodingProtobuf {
+		return signProtobufMessage(payload, key)
+	}
+
+	fields, canonical, err := canonicalMessage(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := crypto.Sign(crypto.Keccak256(canonical), key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign message")
+	}
+
+	fields[signatureField], err = json.Marshal(signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal signature")
+	}
+
+	return json.Marshal(fields)
+}
+
+// VerifyMessage recovers the public key of the message signer.
+// ErrMessageNotSigned is returned for messages without signature,
+// which are still sent by older clients.
+func VerifyMessage(payload []byte) (*ecdsa.PublicKey, error) {
+	if DetectEncoding(payload) == EncodingProtobuf {
+		return verifyProtobufMessage(payload)
+	}
+
+	fields, canonical, err := canonicalMessage(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	rawSignature, ok := fields[signatureField]
+	if !ok {
+		return nil, ErrMessageNotSigned
+	}
+
+	var signature []byte
+	err = json.Unmarshal(rawSignature, &signature)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidSignature, err.Error())
+	}
+
+	return recoverSigner(canonical, signature)
+}
+
+func recoverSigner(canonical []byte, signature []byte) (*ecdsa.PublicKey, error) {
+	publicKey, err := crypto.SigToPub(crypto.Keccak256(canonical), signature)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidSignature, err.Error())
+	}
+	return publicKey, nil
+}
+
+// canonicalMessage returns the message fields (without signature) and their canonical encoding.
+// Canonical form is a compact JSON object with sorted keys, so that it doesn't depend
+// on the fields order and formatting chosen by the sender.
+func canonicalMessage(payload []byte) (map[string]json.RawMessage, []byte, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(payload, &fields)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal message")
+	}
+
+	signature, signed := fields[signatureField]
+	delete(fields, signatureField)
+
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal canonical message")
+	}
+
+	if signed {
+		fields[signatureField] = signature
+	}
+
+	return fields, canonical, nil
+}
+
+func signProtobufMessage(payload []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	canonical, _, err := canonicalProtobufMessage(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := crypto.Sign(crypto.Keccak256(canonical), key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign message")
+	}
+
+	return appendBytes(canonical, headerSignatureField, signature), nil
+}
+
+func verifyProtobufMessage(payload []byte) (*ecdsa.PublicKey, error) {
+	canonical, signature, err := canonicalProtobufMessage(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if signature == nil {
+		return nil, ErrMessageNotSigned
+	}
+
+	return recoverSigner(canonical, signature)
+}
+
+// canonicalProtobufMessage returns the message without the signature field, and the signature itself.
+// Unlike JSON, the protobuf payload is signed as is, because re-encoding could change the fields order.
+func canonicalProtobufMessage(payload []byte) ([]byte, []byte, error) {
+	canonical := make([]byte, 0, len(payload))
+	var signature []byte
+
+	for b := payload; len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, nil, errors.Wrap(protowire.ParseError(n), "failed to unmarshal message")
+		}
+		m := protowire.ConsumeFieldValue(num, typ, b[n:])
+		if m < 0 {
+			return nil, nil, errors.Wrap(protowire.ParseError(m), "failed to unmarshal message")
+		}
+
+		if num == headerSignatureField && typ == protowire.BytesType {
+			signature, _ = protowire.ConsumeBytes(b[n:])
+		} else {
+			canonical = append(canonical, b[:n+m]...)
+		}
+
+		b = b[n+m:]
+	}
+
+	return canonical, signature, nil
+}
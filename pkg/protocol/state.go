@@ -1,8 +1,6 @@
 package protocol
 
 import (
-	"golang.org/x/exp/maps"
-
 	"github.com/six78/2-story-points-cli/internal/config"
 )
 
@@ -13,6 +11,20 @@ type State struct {
 	VotesRevealed bool        `json:"votesRevealed"`
 	Timestamp     int64       `json:"-"`    // TODO: Fix conflict with Message.Timestamp. Change type to time.Time.
 	Deck          Deck        `json:"deck"` // NOTE: This field is experimental and not supported by web client
+	// DealerPublicKey is used by players to encrypt messages that only dealer should be able to read (e.g. votes).
+	// Uncompressed secp256k1 public key. Empty for dealers that don't support private messages.
+	DealerPublicKey []byte `json:"dealerPublicKey,omitempty"`
+	// Dealer is the player that currently owns the room. Empty for dealers that don't support dealer transfer.
+	Dealer PlayerID `json:"dealer,omitempty"`
+	// Hints define how the hint is calculated when votes are revealed. Nil means defaults.
+	Hints *HintSettings `json:"hints,omitempty"`
+	// Timebox is the default voting duration in milliseconds. Zero means voting is not timeboxed.
+	Timebox int64 `json:"timebox,omitempty"`
+	// VotingDeadline is the Unix time in milliseconds when votes of the active issue are revealed automatically,
+	// even if not all players voted. Zero when there's no deadline.
+	VotingDeadline int64 `json:"votingDeadline,omitempty"`
+	// AutoReveal defines when votes are revealed automatically. Nil means defaults of the dealer.
+	AutoReveal *AutoRevealSettings `json:"autoReveal,omitempty"`
 }
 
 type VoteState string
@@ -57,7 +69,16 @@ func (s *State) ActiveIssueHintDeckIndex() int {
 	return s.Deck.Index(issue.Hint.Value)
 }
 
+// AllPlayersVoted tells if all voters voted for the active issue. Observers are not waited for.
 func (s *State) AllPlayersVoted() bool {
 	issue := s.GetActiveIssue()
-	return issue != nil && len(maps.Keys(issue.Votes)) == len(s.Players)
+	if issue == nil {
+		return false
+	}
+	for _, player := range s.Players.Voters() {
+		if _, voted := issue.Votes[player.ID]; !voted {
+			return false
+		}
+	}
+	return true
 }
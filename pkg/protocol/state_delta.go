@@ -0,0 +1,344 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// StateDelta contains only the parts of the State that changed since the previous state.
+// Votes are sent separately from issues, so that a single vote doesn't resend the whole issue.
+type StateDelta struct {
+	Players         PlayersList            `json:"players,omitempty"`      // New or changed players
+	Issues          IssuesList             `json:"issues,omitempty"`       // New issues or issues with changed fields (including votes)
+	Votes           map[IssueID]IssueVotes `json:"votes,omitempty"`        // New or changed votes of existing issues
+	RemovedVotes    map[IssueID][]PlayerID `json:"removedVotes,omitempty"` // Retracted votes of existing issues
+	RemovedIssues   []IssueID              `json:"removedIssues,omitempty"`
+	IssuesOrder     []IssueID              `json:"issuesOrder,omitempty"` // Only set when issues were reordered
+	ActiveIssue     *IssueID               `json:"activeIssue,omitempty"`
+	VotesRevealed   *bool                  `json:"votesRevealed,omitempty"`
+	Deck            Deck                   `json:"deck,omitempty"`
+	DealerPublicKey []byte                 `json:"dealerPublicKey,omitempty"`
+	Dealer          *PlayerID              `json:"dealer,omitempty"`
+	Hints           *HintSettings          `json:"hints,omitempty"` // Empty settings reset to defaults
+	Timebox         *int64                 `json:"timebox,omitempty"`
+	VotingDeadline  *int64                 `json:"votingDeadline,omitempty"`
+	AutoReveal      *AutoRevealSettings    `json:"autoReveal,omitempty"` // Empty settings reset to defaults
+}
+
+// NewStateDelta returns the changes required to get the current state from the previous one.
+// NOTE: The delta doesn't support removing players, such state changes should be sent as a full state.
+// Use Equal to check that applying the delta gives the expected state.
+func NewStateDelta(previous *State, current *State) *StateDelta {
+	delta := &StateDelta{}
+
+	for _, player := range current.Players {
+		previousPlayer, found := previous.Players.Get(player.ID)
+		if !found || !jsonEqual(previousPlayer, player) {
+			delta.Players = append(delta.Players, player)
+		}
+	}
+
+	currentIssues := make(map[IssueID]bool, len(current.Issues))
+	for _, issue := range current.Issues {
+		currentIssues[issue.ID] = true
+		previousIssue := previous.Issues.Get(issue.ID)
+		if previousIssue == nil || !issueFieldsEqual(previousIssue, issue) {
+			delta.Issues = append(delta.Issues, issue)
+			continue
+		}
+		for playerID, vote := range issue.Votes {
+			previousVote, found := previousIssue.Votes[playerID]
+			if found && previousVote == vote {
+				continue
+			}
+			if delta.Votes == nil {
+				delta.Votes = make(map[IssueID]IssueVotes)
+			}
+			if delta.Votes[issue.ID] == nil {
+				delta.Votes[issue.ID] = make(IssueVotes)
+			}
+			delta.Votes[issue.ID][playerID] = vote
+		}
+		for playerID := range previousIssue.Votes {
+			if _, found := issue.Votes[playerID]; found {
+				continue
+			}
+			if delta.RemovedVotes == nil {
+				delta.RemovedVotes = make(map[IssueID][]PlayerID)
+			}
+			delta.RemovedVotes[issue.ID] = append(delta.RemovedVotes[issue.ID], playerID)
+		}
+	}
+
+	remainingOrder := make([]IssueID, 0, len(previous.Issues))
+	for _, issue := range previous.Issues {
+		if currentIssues[issue.ID] {
+			remainingOrder = append(remainingOrder, issue.ID)
+		} else {
+			delta.RemovedIssues = append(delta.RemovedIssues, issue.ID)
+		}
+	}
+
+	// New issues are appended to the end, order only needs to be sent when existing issues were moved
+	currentOrder := current.Issues.IDs()
+	if !slices.Equal(remainingOrder, currentOrder[:min(len(remainingOrder), len(currentOrder))]) {
+		delta.IssuesOrder = currentOrder
+	}
+
+	if previous.ActiveIssue != current.ActiveIssue {
+		activeIssue := current.ActiveIssue
+		delta.ActiveIssue = &activeIssue
+	}
+
+	if previous.VotesRevealed != current.VotesRevealed {
+		votesRevealed := current.VotesRevealed
+		delta.VotesRevealed = &votesRevealed
+	}
+
+	if !previous.Deck.Equal(current.Deck) {
+		delta.Deck = current.Deck
+	}
+
+	if !bytes.Equal(previous.DealerPublicKey, current.DealerPublicKey) {
+		delta.DealerPublicKey = current.DealerPublicKey
+	}
+
+	if previous.Dealer != current.Dealer {
+		dealer := current.Dealer
+		delta.Dealer = &dealer
+	}
+
+	if hintSettings(previous.Hints) != hintSettings(current.Hints) {
+		hints := hintSettings(current.Hints)
+		delta.Hints = &hints
+	}
+
+	if previous.Timebox != current.Timebox {
+		timebox := current.Timebox
+		delta.Timebox = &timebox
+	}
+
+	if previous.VotingDeadline != current.VotingDeadline {
+		deadline := current.VotingDeadline
+		delta.VotingDeadline = &deadline
+	}
+
+	if !autoRevealSettings(previous.AutoReveal).Equal(autoRevealSettings(current.AutoReveal)) {
+		autoReveal := autoRevealSettings(current.AutoReveal)
+		delta.AutoReveal = autoReveal.Clone()
+	}
+
+	return delta
+}
+
+func (d *StateDelta) Empty() bool {
+	return len(d.Players) == 0 &&
+		len(d.Issues) == 0 &&
+		len(d.Votes) == 0 &&
+		len(d.RemovedVotes) == 0 &&
+		len(d.RemovedIssues) == 0 &&
+		len(d.IssuesOrder) == 0 &&
+		d.ActiveIssue == nil &&
+		d.VotesRevealed == nil &&
+		len(d.Deck) == 0 &&
+		len(d.DealerPublicKey) == 0 &&
+		d.Dealer == nil &&
+		d.Hints == nil &&
+		d.Timebox == nil &&
+		d.VotingDeadline == nil &&
+		d.AutoReveal == nil
+}
+
+// ApplyDelta updates the state with given delta.
+func (s *State) ApplyDelta(delta *StateDelta) {
+	for _, player := range delta.Players {
+		index := slices.IndexFunc(s.Players, func(p Player) bool {
+			return p.ID == player.ID
+		})
+		if index < 0 {
+			s.Players = append(s.Players, player)
+		} else {
+			s.Players[index] = player
+		}
+	}
+
+	s.Issues = slices.DeleteFunc(s.Issues, func(issue *Issue) bool {
+		return slices.Contains(delta.RemovedIssues, issue.ID)
+	})
+
+	for _, issue := range delta.Issues {
+		issue := issue.Clone()
+		index := slices.IndexFunc(s.Issues, func(i *Issue) bool {
+			return i.ID == issue.ID
+		})
+		if index < 0 {
+			s.Issues = append(s.Issues, issue)
+		} else {
+			s.Issues[index] = issue
+		}
+	}
+
+	for issueID, votes := range delta.Votes {
+		issue := s.Issues.Get(issueID)
+		if issue == nil {
+			continue
+		}
+		if issue.Votes == nil {
+			issue.Votes = make(IssueVotes, len(votes))
+		}
+		maps.Copy(issue.Votes, votes)
+	}
+
+	for issueID, playerIDs := range delta.RemovedVotes {
+		issue := s.Issues.Get(issueID)
+		if issue == nil {
+			continue
+		}
+		for _, playerID := range playerIDs {
+			delete(issue.Votes, playerID)
+		}
+	}
+
+	if len(delta.IssuesOrder) > 0 {
+		issues := make(IssuesList, 0, len(s.Issues))
+		for _, issueID := range delta.IssuesOrder {
+			if issue := s.Issues.Get(issueID); issue != nil {
+				issues = append(issues, issue)
+			}
+		}
+		s.Issues = issues
+	}
+
+	if delta.ActiveIssue != nil {
+		s.ActiveIssue = *delta.ActiveIssue
+	}
+
+	if delta.VotesRevealed != nil {
+		s.VotesRevealed = *delta.VotesRevealed
+	}
+
+	if len(delta.Deck) > 0 {
+		s.Deck = delta.Deck
+	}
+
+	if len(delta.DealerPublicKey) > 0 {
+		s.DealerPublicKey = delta.DealerPublicKey
+	}
+
+	if delta.Dealer != nil {
+		s.Dealer = *delta.Dealer
+	}
+
+	if delta.Hints != nil {
+		s.Hints = nil
+		if !delta.Hints.Empty() {
+			hints := *delta.Hints
+			s.Hints = &hints
+		}
+	}
+
+	if delta.Timebox != nil {
+		s.Timebox = *delta.Timebox
+	}
+
+	if delta.VotingDeadline != nil {
+		s.VotingDeadline = *delta.VotingDeadline
+	}
+
+	if delta.AutoReveal != nil {
+		s.AutoReveal = nil
+		if !delta.AutoReveal.Empty() {
+			s.AutoReveal = delta.AutoReveal.Clone()
+		}
+	}
+}
+
+// Clone returns a deep copy of the state.
+func (s *State) Clone() *State {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	clone.Players = slices.Clone(s.Players)
+	clone.Deck = slices.Clone(s.Deck)
+	clone.DealerPublicKey = slices.Clone(s.DealerPublicKey)
+	if s.Hints != nil {
+		hints := *s.Hints
+		clone.Hints = &hints
+	}
+	clone.AutoReveal = s.AutoReveal.Clone()
+	clone.Issues = make(IssuesList, 0, len(s.Issues))
+	for _, issue := range s.Issues {
+		clone.Issues = append(clone.Issues, issue.Clone())
+	}
+	return &clone
+}
+
+// Equal compares states as they are seen by the players, i.e. only the fields that are sent in the protocol.
+func (s *State) Equal(other *State) bool {
+	return jsonEqual(s, other)
+}
+
+func (i *Issue) Clone() *Issue {
+	clone := *i
+	clone.Votes = maps.Clone(i.Votes)
+	if i.Rounds != nil {
+		clone.Rounds = make([]IssueRound, 0, len(i.Rounds))
+		for _, round := range i.Rounds {
+			round.Votes = maps.Clone(round.Votes)
+			if round.Result != nil {
+				result := *round.Result
+				round.Result = &result
+			}
+			round.Hint = round.Hint.Clone()
+			clone.Rounds = append(clone.Rounds, round)
+		}
+	}
+	if i.Result != nil {
+		result := *i.Result
+		clone.Result = &result
+	}
+	return &clone
+}
+
+func hintSettings(settings *HintSettings) HintSettings {
+	if settings == nil {
+		return HintSettings{}
+	}
+	return *settings
+}
+
+func autoRevealSettings(settings *AutoRevealSettings) AutoRevealSettings {
+	if settings == nil {
+		return AutoRevealSettings{}
+	}
+	return *settings
+}
+
+func issueFieldsEqual(a *Issue, b *Issue) bool {
+	if a.ID != b.ID || a.TitleOrURL != b.TitleOrURL {
+		return false
+	}
+	if len(a.Rounds) != len(b.Rounds) || !jsonEqual(a.Rounds, b.Rounds) {
+		return false
+	}
+	if a.Result == nil || b.Result == nil {
+		return a.Result == nil && b.Result == nil
+	}
+	return *a.Result == *b.Result
+}
+
+func jsonEqual(a any, b any) bool {
+	aJson, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJson, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aJson, bJson)
+}
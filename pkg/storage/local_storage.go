@@ -2,20 +2,28 @@ package storage
 
 import (
 	"encoding/json"
+	"io/fs"
+	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/six78/2-story-points-cli/internal/config"
 	"github.com/six78/2-story-points-cli/pkg/protocol"
 	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
 
 	"github.com/shibukawa/configdir"
 )
 
 const (
 	playerStorageFileName = "player.json"
-	roomsDirectory        = "rooms"
+	roomsDirectory        = "rooms"   // Rooms owned by the player as a dealer
+	archiveDirectory      = "archive" // Read-only copies of rooms joined as a player
+	roomFileExtension     = ".json"
 )
 
 var (
@@ -27,16 +35,39 @@ type LocalStorage struct {
 
 	folder *configdir.Config
 	mutex  *sync.RWMutex
+
+	// Names of the loaded and renamed rooms by file path, so that the room file is not read on every save
+	roomNames map[string]string
 }
 
 type playerStorage struct {
-	ID   protocol.PlayerID `json:"id"`
-	Name string            `json:"name"`
+	ID          protocol.PlayerID `json:"id"`
+	Name        string            `json:"name"`
+	IdentityKey []byte            `json:"identityKey,omitempty"`
 }
 
 type roomStorage struct {
 	// TODO: PrivateKey string
-	State *protocol.State `json:"state"`
+	Name   string          `json:"name,omitempty"`
+	State  *protocol.State `json:"state"`
+	Outbox []PendingVote   `json:"outbox,omitempty"` // Only kept for rooms joined as a player
+}
+
+// PendingVote is a vote of the player, which is not yet confirmed by the dealer.
+type PendingVote struct {
+	Issue   protocol.IssueID    `json:"issue"`
+	Vote    protocol.VoteResult `json:"vote"`
+	Payload []byte              `json:"payload"` // Signed vote message, it's published again as is
+}
+
+// RoomInfo describes a room saved in the storage.
+type RoomInfo struct {
+	RoomID      protocol.RoomID
+	Name        string
+	LastUsed    time.Time
+	IssuesCount int
+	Deck        protocol.Deck
+	Archived    bool // Room was joined as a player, it can only be opened read-only
 }
 
 func NewLocalStorage(localPath string) *LocalStorage {
@@ -44,8 +75,9 @@ func NewLocalStorage(localPath string) *LocalStorage {
 	configDirs.LocalPath = localPath
 
 	return &LocalStorage{
-		folder: queryFolder(&configDirs),
-		mutex:  &sync.RWMutex{},
+		folder:    queryFolder(&configDirs),
+		mutex:     &sync.RWMutex{},
+		roomNames: make(map[string]string),
 	}
 }
 
@@ -115,6 +147,7 @@ func (s *LocalStorage) ResetPlayer() error {
 	defer s.mutex.Unlock()
 	s.player.ID = ""
 	s.player.Name = ""
+	s.player.IdentityKey = nil
 	return s.savePlayerStorage()
 }
 
@@ -144,9 +177,173 @@ func (s *LocalStorage) SetPlayerName(name string) error {
 	return s.savePlayerStorage()
 }
 
+func (s *LocalStorage) IdentityKey() []byte {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.player.IdentityKey
+}
+
+func (s *LocalStorage) SetIdentityKey(key []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.player.IdentityKey = key
+	return s.savePlayerStorage()
+}
+
 func (s *LocalStorage) LoadRoomState(roomID protocol.RoomID) (*protocol.State, error) {
-	filePath := roomFilePath(roomID)
+	room, err := s.loadRoom(roomFilePath(roomsDirectory, roomID))
+	if err != nil {
+		return nil, err
+	}
+	return room.State, nil
+}
+
+func (s *LocalStorage) SaveRoomState(roomID protocol.RoomID, state *protocol.State) error {
+	return s.saveRoom(roomFilePath(roomsDirectory, roomID), state, nil)
+}
+
+// LoadArchivedRoomState loads the last state of a room joined as a player, with the votes pending at that time.
+func (s *LocalStorage) LoadArchivedRoomState(roomID protocol.RoomID) (*protocol.State, []PendingVote, error) {
+	room, err := s.loadRoom(roomFilePath(archiveDirectory, roomID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return room.State, room.Outbox, nil
+}
+
+// ArchiveRoomState saves the state of a room joined as a player, with the votes not yet confirmed by the dealer.
+// Archived rooms are kept separately from the dealer rooms, so that they're never loaded as owned by the player.
+func (s *LocalStorage) ArchiveRoomState(roomID protocol.RoomID, state *protocol.State, outbox []PendingVote) error {
+	return s.saveRoom(roomFilePath(archiveDirectory, roomID), state, outbox)
+}
+
+// ListRooms returns the rooms owned by the player, most recently used first.
+func (s *LocalStorage) ListRooms() ([]RoomInfo, error) {
+	return s.listRooms(roomsDirectory)
+}
+
+// ListArchivedRooms returns the rooms joined as a player, most recently used first.
+func (s *LocalStorage) ListArchivedRooms() ([]RoomInfo, error) {
+	return s.listRooms(archiveDirectory)
+}
+
+func (s *LocalStorage) listRooms(directory string) ([]RoomInfo, error) {
+	entries, err := os.ReadDir(filepath.Join(s.folder.Path, directory))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []RoomInfo{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read rooms directory")
+	}
+
+	rooms := make([]RoomInfo, 0, len(entries))
+
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || path.Ext(fileName) != roomFileExtension {
+			continue
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			config.Logger.Warn("failed to get room file info", zap.String("file", fileName), zap.Error(err))
+			continue
+		}
+
+		room, err := s.readRoomStorage(path.Join(directory, fileName))
+		if err != nil {
+			config.Logger.Warn("failed to read room storage", zap.String("file", fileName), zap.Error(err))
+			continue
+		}
 
+		info := RoomInfo{
+			RoomID:   protocol.NewRoomID(strings.TrimSuffix(fileName, roomFileExtension)),
+			Name:     room.Name,
+			LastUsed: fileInfo.ModTime(),
+			Archived: directory == archiveDirectory,
+		}
+		if room.State != nil {
+			info.IssuesCount = len(room.State.Issues)
+			info.Deck = room.State.Deck
+		}
+
+		rooms = append(rooms, info)
+	}
+
+	slices.SortFunc(rooms, func(a, b RoomInfo) int {
+		return b.LastUsed.Compare(a.LastUsed)
+	})
+
+	return rooms, nil
+}
+
+// RenameRoom sets a human-readable name of the room.
+// The name is only stored locally and is never sent to other players.
+func (s *LocalStorage) RenameRoom(roomID protocol.RoomID, name string) error {
+	filePath := roomFilePath(roomsDirectory, roomID)
+
+	room, err := s.readRoomStorage(filePath)
+	if err != nil {
+		return err
+	}
+
+	room.Name = name
+	err = s.writeRoomStorage(filePath, room)
+	if err != nil {
+		return err
+	}
+
+	s.setRoomName(filePath, name)
+	return nil
+}
+
+func (s *LocalStorage) DeleteRoom(roomID protocol.RoomID) error {
+	filePath := roomFilePath(roomsDirectory, roomID)
+	err := os.Remove(filepath.Join(s.folder.Path, filePath))
+	if err != nil {
+		return errors.Wrap(err, "failed to delete room storage file")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.roomNames, filePath)
+	return nil
+}
+
+func (s *LocalStorage) loadRoom(filePath string) (*roomStorage, error) {
+	room, err := s.readRoomStorage(filePath)
+	if err != nil {
+		return nil, err
+	}
+	s.setRoomName(filePath, room.Name)
+	return room, nil
+}
+
+// saveRoom saves the room, keeping its name known from the last load or rename.
+func (s *LocalStorage) saveRoom(filePath string, state *protocol.State, outbox []PendingVote) error {
+	s.mutex.RLock()
+	name := s.roomNames[filePath]
+	s.mutex.RUnlock()
+
+	return s.writeRoomStorage(filePath, &roomStorage{
+		Name:   name,
+		State:  state,
+		Outbox: outbox,
+	})
+}
+
+func (s *LocalStorage) setRoomName(filePath string, name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if name == "" {
+		delete(s.roomNames, filePath)
+		return
+	}
+	s.roomNames[filePath] = name
+}
+
+func (s *LocalStorage) readRoomStorage(filePath string) (*roomStorage, error) {
 	data, err := s.folder.ReadFile(filePath)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read room storage file")
@@ -158,21 +355,15 @@ func (s *LocalStorage) LoadRoomState(roomID protocol.RoomID) (*protocol.State, e
 		return nil, errors.Wrap(err, "failed to unmarshal storage file")
 	}
 
-	return room.State, nil
+	return &room, nil
 }
 
-func (s *LocalStorage) SaveRoomState(roomID protocol.RoomID, state *protocol.State) error {
-	room := roomStorage{
-		State: state,
-	}
-
+func (s *LocalStorage) writeRoomStorage(filePath string, room *roomStorage) error {
 	roomJson, err := json.Marshal(room)
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal room data")
 	}
 
-	filePath := roomFilePath(roomID)
-
 	err = s.folder.WriteFile(filePath, roomJson)
 	if err != nil {
 		return errors.Wrap(err, "failed to write room storage")
@@ -181,8 +372,8 @@ func (s *LocalStorage) SaveRoomState(roomID protocol.RoomID, state *protocol.Sta
 	return nil
 }
 
-func roomFilePath(roomID protocol.RoomID) string {
-	return path.Join(roomsDirectory, roomID.String()+".json")
+func roomFilePath(directory string, roomID protocol.RoomID) string {
+	return path.Join(directory, roomID.String()+roomFileExtension)
 }
 
 func queryFolder(configDirs *configdir.ConfigDir) *configdir.Config {
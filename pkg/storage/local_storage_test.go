@@ -1,11 +1,15 @@
 package storage
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/shibukawa/configdir"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/exp/slices"
 
 	"github.com/six78/2-story-points-cli/internal/config"
 	"github.com/six78/2-story-points-cli/internal/testcommon"
@@ -78,6 +82,21 @@ func (s *Suite) TestPlayerStorage() {
 	s.Require().Equal(name, s.storage.PlayerName())
 }
 
+func (s *Suite) TestIdentityKeyStorage() {
+	s.Require().Empty(s.storage.IdentityKey())
+
+	key := []byte(gofakeit.LetterN(32))
+	err := s.storage.SetIdentityKey(key)
+	s.Require().NoError(err)
+	s.Require().Equal(key, s.storage.IdentityKey())
+
+	// Ensure the key is persisted
+	newStorage := NewLocalStorage(s.tempPath)
+	err = newStorage.Initialize()
+	s.Require().NoError(err)
+	s.Require().Equal(key, newStorage.IdentityKey())
+}
+
 func (s *Suite) TestRoomStorage() {
 	roomID := protocol.NewRoomID(gofakeit.LetterN(5))
 	state, err := s.storage.LoadRoomState(roomID)
@@ -111,6 +130,127 @@ func (s *Suite) TestRoomStorage() {
 	s.Require().Equal(state, loadedState)
 }
 
+func (s *Suite) TestRoomArchive() {
+	roomID := protocol.NewRoomID(gofakeit.LetterN(5))
+	state, _, err := s.storage.LoadArchivedRoomState(roomID)
+	s.Require().Error(err)
+	s.Require().Nil(state)
+
+	state = &protocol.State{
+		ActiveIssue: protocol.IssueID(gofakeit.UUID()),
+		Dealer:      protocol.PlayerID(gofakeit.UUID()),
+	}
+	outbox := []PendingVote{{
+		Issue:   state.ActiveIssue,
+		Vote:    protocol.VoteResult{Value: "3", Timestamp: time.Now().UnixMilli()},
+		Payload: []byte(gofakeit.Sentence(3)),
+	}}
+
+	err = s.storage.ArchiveRoomState(roomID, state, outbox)
+	s.Require().NoError(err)
+
+	loadedState, loadedOutbox, err := s.storage.LoadArchivedRoomState(roomID)
+	s.Require().NoError(err)
+	s.Require().Equal(state.ActiveIssue, loadedState.ActiveIssue)
+	s.Require().Equal(state.Dealer, loadedState.Dealer)
+	s.Require().Equal(outbox, loadedOutbox)
+
+	// Archived rooms are never loaded as dealer rooms
+	loadedState, err = s.storage.LoadRoomState(roomID)
+	s.Require().Error(err)
+	s.Require().Nil(loadedState)
+}
+
+func (s *Suite) TestListRooms() {
+	rooms, err := s.storage.ListRooms()
+	s.Require().NoError(err)
+	s.Require().Empty(rooms)
+
+	oldRoomID := protocol.NewRoomID(gofakeit.LetterN(5))
+	oldState := &protocol.State{
+		Issues: protocol.IssuesList{{ID: protocol.IssueID(gofakeit.UUID())}},
+		Deck:   protocol.DeckFromValues("1", "2", "3"),
+	}
+	err = s.storage.SaveRoomState(oldRoomID, oldState)
+	s.Require().NoError(err)
+
+	newRoomID := protocol.NewRoomID(gofakeit.LetterN(5))
+	err = s.storage.SaveRoomState(newRoomID, &protocol.State{})
+	s.Require().NoError(err)
+
+	// Archived rooms are listed separately
+	archivedRoomID := protocol.NewRoomID(gofakeit.LetterN(5))
+	err = s.storage.ArchiveRoomState(archivedRoomID, oldState, nil)
+	s.Require().NoError(err)
+
+	archived, err := s.storage.ListArchivedRooms()
+	s.Require().NoError(err)
+	s.Require().Len(archived, 1)
+	s.Require().Equal(archivedRoomID, archived[0].RoomID)
+	s.Require().Equal(len(oldState.Issues), archived[0].IssuesCount)
+	s.Require().True(archived[0].Archived)
+
+	// Make sure the rooms are sorted by last usage
+	lastUsed := time.Now().Add(-time.Hour)
+	oldRoomPath := filepath.Join(s.tempPath, roomFilePath(roomsDirectory, oldRoomID))
+	err = os.Chtimes(oldRoomPath, lastUsed, lastUsed)
+	s.Require().NoError(err)
+
+	rooms, err = s.storage.ListRooms()
+	s.Require().NoError(err)
+	s.Require().Len(rooms, 2)
+	s.Require().Equal(newRoomID, rooms[0].RoomID)
+	s.Require().Equal(oldRoomID, rooms[1].RoomID)
+	s.Require().Equal(len(oldState.Issues), rooms[1].IssuesCount)
+	s.Require().Equal(oldState.Deck, rooms[1].Deck)
+	s.Require().False(rooms[1].Archived)
+	s.Require().WithinDuration(lastUsed, rooms[1].LastUsed, time.Second)
+
+	// Name is kept when the state is saved
+	name := gofakeit.LetterN(8)
+	err = s.storage.RenameRoom(oldRoomID, name)
+	s.Require().NoError(err)
+	err = s.storage.SaveRoomState(oldRoomID, oldState)
+	s.Require().NoError(err)
+
+	rooms, err = s.storage.ListRooms()
+	s.Require().NoError(err)
+	s.Require().Len(rooms, 2)
+	index := slices.IndexFunc(rooms, func(room RoomInfo) bool {
+		return room.RoomID == oldRoomID
+	})
+	s.Require().GreaterOrEqual(index, 0)
+	s.Require().Equal(name, rooms[index].Name)
+
+	// Name is kept after restart, once the room is loaded
+	restarted := NewLocalStorage(s.tempPath)
+	err = restarted.Initialize()
+	s.Require().NoError(err)
+	_, err = restarted.LoadRoomState(oldRoomID)
+	s.Require().NoError(err)
+	err = restarted.SaveRoomState(oldRoomID, oldState)
+	s.Require().NoError(err)
+
+	rooms, err = s.storage.ListRooms()
+	s.Require().NoError(err)
+	index = slices.IndexFunc(rooms, func(room RoomInfo) bool {
+		return room.RoomID == oldRoomID
+	})
+	s.Require().GreaterOrEqual(index, 0)
+	s.Require().Equal(name, rooms[index].Name)
+
+	err = s.storage.DeleteRoom(oldRoomID)
+	s.Require().NoError(err)
+
+	rooms, err = s.storage.ListRooms()
+	s.Require().NoError(err)
+	s.Require().Len(rooms, 1)
+	s.Require().Equal(newRoomID, rooms[0].RoomID)
+
+	err = s.storage.RenameRoom(oldRoomID, name)
+	s.Require().Error(err)
+}
+
 func (s *Suite) TestResetPlayer() {
 	id := protocol.PlayerID(gofakeit.LetterN(5))
 	name := gofakeit.LetterN(6)
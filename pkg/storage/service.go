@@ -12,6 +12,14 @@ type Service interface {
 	PlayerName() string
 	SetPlayerID(id protocol.PlayerID) error
 	SetPlayerName(name string) error
+	IdentityKey() []byte
+	SetIdentityKey(key []byte) error
 	LoadRoomState(roomID protocol.RoomID) (*protocol.State, error)
 	SaveRoomState(roomID protocol.RoomID, state *protocol.State) error
+	LoadArchivedRoomState(roomID protocol.RoomID) (*protocol.State, []PendingVote, error)
+	ArchiveRoomState(roomID protocol.RoomID, state *protocol.State, outbox []PendingVote) error
+	ListRooms() ([]RoomInfo, error)
+	ListArchivedRooms() ([]RoomInfo, error)
+	RenameRoom(roomID protocol.RoomID, name string) error
+	DeleteRoom(roomID protocol.RoomID) error
 }